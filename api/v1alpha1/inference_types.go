@@ -33,6 +33,23 @@ type InferenceSpec struct {
 	// Replicas specify the expected model serving replicas.
 	Replicas *int32 `json:"replicas,omitempty"`
 
+	// MinReplicas is the lower bound of replicas that scaling decisions may set. Defaults to 1.
+	// +kubebuilder:validation:Minimum=0
+	MinReplicas *int32 `json:"minReplicas,omitempty"`
+
+	// MaxReplicas is the upper bound of replicas that scaling decisions may set. Unbounded if not set.
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas *int32 `json:"maxReplicas,omitempty"`
+
+	// MinAvailable is the number of serving pods which must stay ready while scheduling decisions move or scale down
+	// the serving pods. Defaults to the minimum available of the controller.
+	// +kubebuilder:validation:Minimum=0
+	MinAvailable *int32 `json:"minAvailable,omitempty"`
+
+	// SchedulingAlgorithm selects the registered scheduling algorithm of the Inference by name, which overrides the
+	// algorithm of the SchedulingPolicy selecting it.
+	SchedulingAlgorithm string `json:"schedulingAlgorithm,omitempty"`
+
 	// PredictorStatuses exposes current observed status for each predictor.
 	Servings []ServingSpec `json:"servings"`
 }
@@ -51,10 +68,35 @@ type ServingSpec struct {
 
 	//BatchSize specify the expected batch size
 	BatchSize int32 `json:"batchSize,omitempty"`
+
+	// Metrics configures scraping the metrics of the serving runtime. The metrics are not scraped if not set.
+	Metrics *ServingMetricsSpec `json:"metrics,omitempty"`
 	// Template describes a template of predictor pod with its properties.
 	//Template corev1.PodTemplateSpec `json:"template"`
 }
 
+// ServingMetricsFormat is the format of the metrics of a serving runtime.
+// +kubebuilder:validation:Enum=TFServing;Triton
+type ServingMetricsFormat string
+
+const (
+	TFServingMetricsFormat ServingMetricsFormat = "TFServing"
+	TritonMetricsFormat    ServingMetricsFormat = "Triton"
+)
+
+// ServingMetricsSpec configures the Prometheus endpoint of the serving containers.
+type ServingMetricsSpec struct {
+	// Format is the metrics format of the serving runtime.
+	Format ServingMetricsFormat `json:"format"`
+	// Port is the port of the metrics endpoint. Defaults to the serving port for TFServing, and 8002 for Triton.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+	// Path is the path of the metrics endpoint. Defaults to /monitoring/prometheus/metrics for TFServing, and
+	// /metrics for Triton.
+	Path string `json:"path,omitempty"`
+}
+
 // InferenceStatus defines the observed state of Inference
 type InferenceStatus struct {
 
@@ -65,6 +107,21 @@ type InferenceStatus struct {
 	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 
 	ServingStatuses []ServingStatus `json:"servingStatuses,omitempty"`
+
+	// ActiveDecesion is the scheduling decision being applied to this inference.
+	ActiveDecesion *ActiveDecesionStatus `json:"activeDecesion,omitempty"`
+}
+
+// ActiveDecesionStatus summarizes the scheduling decision being applied to an inference.
+type ActiveDecesionStatus struct {
+	// Name is the name of the SchedulingDecesion.
+	Name string `json:"name"`
+	// Type is the scheduling type of the decision.
+	Type string `json:"type,omitempty"`
+	// TargetNode is the node targeted by the decision.
+	TargetNode string `json:"targetNode,omitempty"`
+	// The time the decision has been applied.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
 }
 
 type ServingStatus struct {
@@ -87,10 +144,31 @@ type ServingStatus struct {
 	Type ServingStatusType `json:"type"`
 	// A human readable message indicating details about the transition.
 	Message string `json:"message,omitempty"`
+	// Metrics are the serving metrics scraped from the serving containers.
+	Metrics *ServingMetrics `json:"metrics,omitempty"`
+}
+
+// ServingMetrics are the serving metrics of a serving over the last scrape interval. The decimals are formatted as
+// strings, and empty if they are not observed.
+type ServingMetrics struct {
+	// LatencyP50Milliseconds and LatencyP99Milliseconds are the median and the 99th percentile of the request latency.
+	LatencyP50Milliseconds string `json:"latencyP50Milliseconds,omitempty"`
+	LatencyP99Milliseconds string `json:"latencyP99Milliseconds,omitempty"`
+	// RequestsPerSecond is the request rate of all the serving containers.
+	RequestsPerSecond string `json:"requestsPerSecond,omitempty"`
+	// BatchUtilization is the mean size of the processed batches as a ratio of the batch size.
+	BatchUtilization string `json:"batchUtilization,omitempty"`
+	// ScrapedPods is the number of the serving pods scraped.
+	ScrapedPods int32 `json:"scrapedPods"`
+	// The last time the metrics were scraped.
+	LastScrapeTime metav1.Time `json:"lastScrapeTime,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Replicas",type=integer,JSONPath=`.spec.replicas`
+//+kubebuilder:printcolumn:name="Decesion",type=string,JSONPath=`.status.activeDecesion.name`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // Inference is the Schema for the inferences API
 type Inference struct {
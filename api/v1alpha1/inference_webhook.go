@@ -0,0 +1,68 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// SetupWebhookWithManager registers the validating webhook of Inference with the manager.
+func (r *Inference) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-melody-io-melody-io-v1alpha1-inference,mutating=false,failurePolicy=fail,sideEffects=None,groups=melody.io.melody.io,resources=inferences,verbs=create;update,versions=v1alpha1,name=vinference.melody.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &Inference{}
+
+// ValidateCreate implements webhook.Validator.
+func (r *Inference) ValidateCreate() error {
+	return r.validateInference()
+}
+
+// ValidateUpdate implements webhook.Validator.
+func (r *Inference) ValidateUpdate(old runtime.Object) error {
+	return r.validateInference()
+}
+
+// ValidateDelete implements webhook.Validator.
+func (r *Inference) ValidateDelete() error {
+	return nil
+}
+
+// validateInference rejects the min replicas above the max replicas, which the CRD schema can not express.
+func (r *Inference) validateInference() error {
+	var errs field.ErrorList
+	spec := field.NewPath("spec")
+	minReplicas := int32(1)
+	if r.Spec.MinReplicas != nil {
+		minReplicas = *r.Spec.MinReplicas
+	}
+	if r.Spec.MaxReplicas != nil && minReplicas > *r.Spec.MaxReplicas {
+		errs = append(errs, field.Invalid(spec.Child("minReplicas"), minReplicas, "must be less than or equal to maxReplicas"))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("Inference").GroupKind(), r.Name, errs)
+}
@@ -0,0 +1,35 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	"k8s.io/utils/pointer"
+)
+
+func TestValidateInference(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		minReplicas *int32
+		maxReplicas *int32
+		valid       bool
+	}{
+		{name: "unbounded", valid: true},
+		{name: "min below max", minReplicas: pointer.Int32(2), maxReplicas: pointer.Int32(4), valid: true},
+		{name: "min equal to max", minReplicas: pointer.Int32(3), maxReplicas: pointer.Int32(3), valid: true},
+		{name: "min above max", minReplicas: pointer.Int32(5), maxReplicas: pointer.Int32(4), valid: false},
+		{name: "default min below max", maxReplicas: pointer.Int32(1), valid: true},
+		{name: "zero min", minReplicas: pointer.Int32(0), maxReplicas: pointer.Int32(1), valid: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			inference := &Inference{Spec: InferenceSpec{MinReplicas: tc.minReplicas, MaxReplicas: tc.maxReplicas}}
+			for verb, err := range map[string]error{
+				"create": inference.ValidateCreate(),
+				"update": inference.ValidateUpdate(&Inference{}),
+			} {
+				if (err == nil) != tc.valid {
+					t.Errorf("expected %s to be valid %v, got %v", verb, tc.valid, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha1
+
+import (
+	"encoding/json"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"melody/api/v1alpha2"
+)
+
+// AnnotationConversionData stashes the fields of a SchedulingDecesion which v1alpha1 can not represent, so that they
+// survive a round trip through v1alpha1.
+const AnnotationConversionData = "melody.io/conversion-data"
+
+// hubFields are the fields of the Hub version which v1alpha1 can not represent. They are stashed in the
+// AnnotationConversionData annotation by ConvertFrom and restored by ConvertTo.
+type hubFields struct {
+	Weights      *v1alpha2.ObjectiveWeights      `json:"weights,omitempty"`
+	DryRun       bool                            `json:"dryRun,omitempty"`
+	Plan         *v1alpha2.SchedulingPlan        `json:"plan,omitempty"`
+	SteeredPods  int32                           `json:"steeredPods,omitempty"`
+	Feedback     *v1alpha2.SchedulingFeedback    `json:"feedback,omitempty"`
+	Explanation  *v1alpha2.SchedulingExplanation `json:"explanation,omitempty"`
+	ApprovedTime *metav1.Time                    `json:"approvedTime,omitempty"`
+}
+
+// ConvertTo converts this SchedulingDecesion to the Hub version (v1alpha2).
+func (src *SchedulingDecesion) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha2.SchedulingDecesion)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	// Spec
+	if src.Spec.Algorithm != nil {
+		algorithm := v1alpha2.SchedulingAlgorithm(*src.Spec.Algorithm)
+		dst.Spec.Algorithm = &algorithm
+	}
+	pod := &src.Spec.Objective.TargetPod
+	node := &src.Spec.Objective.TargetNode
+	dst.Spec.Objective = v1alpha2.SchedulingObjective{
+		Type:           v1alpha2.SchedulingType(src.Spec.Objective.Type),
+		TargetPod:      v1alpha2.ObjectReference{Name: pod.Name, Namespace: pod.Namespace, UID: pod.UID},
+		TargetNode:     v1alpha2.ObjectReference{Name: node.Name, UID: node.UID},
+		ScalingReplica: src.Spec.Objective.ScalingReplica,
+	}
+	dst.Spec.ResultTime = src.Spec.ResultTime
+	dst.Spec.DeadlineSeconds = src.Spec.DeadlineSeconds
+
+	// The owning Inference was only known from the labels of the embedded pod.
+	if name := pod.Labels[v1alpha2.LabelInferenceName]; name != "" {
+		dst.Spec.InferenceRef = &corev1.LocalObjectReference{Name: name}
+	}
+
+	// Status
+	dst.Status = v1alpha2.SchedulingDecesionStatus{
+		LastUpdateTime:   src.Status.LastUpdateTime,
+		Status:           src.Status.Status,
+		Used:             src.Status.Used,
+		Phase:            v1alpha2.SchedulingDecesionPhase(src.Status.Phase),
+		Reason:           src.Status.Reason,
+		Message:          src.Status.Message,
+		PreviousReplicas: src.Status.PreviousReplicas,
+		NewReplicas:      src.Status.NewReplicas,
+		PreviousRevision: src.Status.PreviousRevision,
+		StartTime:        src.Status.StartTime,
+		CompletionTime:   src.Status.CompletionTime,
+	}
+
+	// Restore the fields stashed by ConvertFrom
+	data, ok := dst.Annotations[AnnotationConversionData]
+	if !ok {
+		return nil
+	}
+	delete(dst.Annotations, AnnotationConversionData)
+	if len(dst.Annotations) == 0 {
+		dst.Annotations = nil
+	}
+	stashed := &hubFields{}
+	if err := json.Unmarshal([]byte(data), stashed); err != nil {
+		return err
+	}
+	dst.Spec.Weights = stashed.Weights
+	dst.Spec.DryRun = stashed.DryRun
+	dst.Status.Plan = stashed.Plan
+	dst.Status.SteeredPods = stashed.SteeredPods
+	dst.Status.Feedback = stashed.Feedback
+	dst.Status.Explanation = stashed.Explanation
+	dst.Status.ApprovedTime = stashed.ApprovedTime
+	return nil
+}
+
+// ConvertFrom converts from the Hub version (v1alpha2) to this version.
+func (dst *SchedulingDecesion) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha2.SchedulingDecesion)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	// Spec
+	if src.Spec.Algorithm != nil {
+		algorithm := SchedulingAlgorithm(*src.Spec.Algorithm)
+		dst.Spec.Algorithm = &algorithm
+	}
+	pod := &src.Spec.Objective.TargetPod
+	node := &src.Spec.Objective.TargetNode
+	dst.Spec.Objective = SchedulingObjective{
+		Type:           SchedulingType(src.Spec.Objective.Type),
+		TargetPod:      corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace, UID: pod.UID}},
+		TargetNode:     corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: node.Name, UID: node.UID}},
+		ScalingReplica: src.Spec.Objective.ScalingReplica,
+	}
+	if src.Spec.InferenceRef != nil {
+		dst.Spec.Objective.TargetPod.Labels = map[string]string{v1alpha2.LabelInferenceName: src.Spec.InferenceRef.Name}
+	}
+	dst.Spec.ResultTime = src.Spec.ResultTime
+	dst.Spec.DeadlineSeconds = src.Spec.DeadlineSeconds
+
+	// Status
+	dst.Status = SchedulingDecesionStatus{
+		LastUpdateTime:   src.Status.LastUpdateTime,
+		Status:           src.Status.Status,
+		Used:             src.Status.Used,
+		Phase:            SchedulingDecesionPhase(src.Status.Phase),
+		Reason:           src.Status.Reason,
+		Message:          src.Status.Message,
+		PreviousReplicas: src.Status.PreviousReplicas,
+		NewReplicas:      src.Status.NewReplicas,
+		PreviousRevision: src.Status.PreviousRevision,
+		StartTime:        src.Status.StartTime,
+		CompletionTime:   src.Status.CompletionTime,
+	}
+
+	// Stash the fields v1alpha1 can not represent, so that ConvertTo restores them
+	stashed := hubFields{
+		Weights:      src.Spec.Weights,
+		DryRun:       src.Spec.DryRun,
+		Plan:         src.Status.Plan,
+		SteeredPods:  src.Status.SteeredPods,
+		Feedback:     src.Status.Feedback,
+		Explanation:  src.Status.Explanation,
+		ApprovedTime: src.Status.ApprovedTime,
+	}
+	if reflect.DeepEqual(stashed, hubFields{}) {
+		return nil
+	}
+	data, err := json.Marshal(stashed)
+	if err != nil {
+		return err
+	}
+	if dst.Annotations == nil {
+		dst.Annotations = make(map[string]string)
+	}
+	dst.Annotations[AnnotationConversionData] = string(data)
+	return nil
+}
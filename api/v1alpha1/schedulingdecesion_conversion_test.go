@@ -0,0 +1,130 @@
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"melody/api/v1alpha2"
+)
+
+func TestSchedulingDecesionHubRoundTrip(t *testing.T) {
+	// Times are serialized in seconds in the stash, and compared semantically as they are parsed in local time
+	now := metav1.NewTime(time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC))
+	previous, replicas, deadline := int32(2), int32(3), int32(120)
+	algorithm := v1alpha2.GreedyScheduling
+	hub := &v1alpha2.SchedulingDecesion{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "move",
+			Namespace:   "default",
+			Labels:      map[string]string{v1alpha2.LabelInferenceName: "resnet"},
+			Annotations: map[string]string{v1alpha2.AnnotationApproved: "true"},
+		},
+		Spec: v1alpha2.SchedulingDecesionSpec{
+			InferenceRef: &corev1.LocalObjectReference{Name: "resnet"},
+			Algorithm:    &algorithm,
+			Objective: v1alpha2.SchedulingObjective{
+				Type:           v1alpha2.TransitionScaling,
+				TargetPod:      v1alpha2.ObjectReference{Name: "resnet-0", Namespace: "default", UID: "pod-uid"},
+				TargetNode:     v1alpha2.ObjectReference{Name: "edge-1", UID: "node-uid"},
+				ScalingReplica: replicas,
+			},
+			ResultTime:      now,
+			Weights:         &v1alpha2.ObjectiveWeights{CPUBalance: 2, MemoryBalance: 1, Latency: 3, MigrationCost: 1},
+			DryRun:          true,
+			DeadlineSeconds: &deadline,
+		},
+		Status: v1alpha2.SchedulingDecesionStatus{
+			LastUpdateTime:   now,
+			Status:           corev1.ConditionUnknown,
+			Used:             true,
+			Phase:            v1alpha2.DecesionRunning,
+			Reason:           "TransitionScalingApplied",
+			PreviousReplicas: &previous,
+			NewReplicas:      &replicas,
+			PreviousRevision: "4",
+			Plan: &v1alpha2.SchedulingPlan{
+				Deployment:      "resnet",
+				DeploymentPatch: `{"spec":{"replicas":3}}`,
+				PreferredNode:   "edge-1",
+				SteerPods:       2,
+				ReplicaDelta:    1,
+				NodeRequests:    corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+			},
+			SteeredPods:  1,
+			ApprovedTime: &now,
+			StartTime:    &now,
+			Feedback: &v1alpha2.SchedulingFeedback{
+				Before: &v1alpha2.SchedulingState{ObservedTime: now, Nodes: []v1alpha2.NodeState{{Name: "edge-1", CPU: "0.5000"}}},
+				Reward: "0.1000",
+			},
+			Explanation: &v1alpha2.SchedulingExplanation{
+				Candidates: []v1alpha2.NodeScore{{Node: "edge-1", Score: "0.9000"}},
+				Features:   []v1alpha2.FeatureImportance{{Name: "edge-1/cpu", Value: "0.5000", Importance: "0.2000"}},
+			},
+		},
+	}
+
+	spoke := &SchedulingDecesion{}
+	if err := spoke.ConvertFrom(hub.DeepCopy()); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if _, ok := spoke.Annotations[AnnotationConversionData]; !ok {
+		t.Errorf("ConvertFrom() did not stash the v1alpha2 fields in %s", AnnotationConversionData)
+	}
+	got := &v1alpha2.SchedulingDecesion{}
+	if err := spoke.ConvertTo(got); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if !equality.Semantic.DeepEqual(got, hub) {
+		t.Errorf("round trip = %+v, want %+v", got, hub)
+	}
+}
+
+func TestSchedulingDecesionSpokeRoundTrip(t *testing.T) {
+	now := metav1.NewTime(time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC))
+	replicas := int32(1)
+	spoke := &SchedulingDecesion{
+		ObjectMeta: metav1.ObjectMeta{Name: "move", Namespace: "default"},
+		Spec: SchedulingDecesionSpec{
+			Objective: SchedulingObjective{
+				Type: Transition,
+				TargetPod: corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+					Name: "resnet-0", Namespace: "default", UID: "pod-uid", Labels: map[string]string{v1alpha2.LabelInferenceName: "resnet"},
+				}},
+				TargetNode:     corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "edge-1", UID: "node-uid"}},
+				ScalingReplica: replicas,
+			},
+			ResultTime: now,
+		},
+		Status: SchedulingDecesionStatus{
+			LastUpdateTime: now,
+			Status:         corev1.ConditionTrue,
+			Phase:          DecesionSucceeded,
+			Reason:         "TransitionSucceeded",
+			CompletionTime: &now,
+		},
+	}
+
+	hub := &v1alpha2.SchedulingDecesion{}
+	if err := spoke.DeepCopy().ConvertTo(hub); err != nil {
+		t.Fatalf("ConvertTo() error = %v", err)
+	}
+	if hub.Spec.InferenceRef == nil || hub.Spec.InferenceRef.Name != "resnet" {
+		t.Errorf("ConvertTo() InferenceRef = %v, want resnet", hub.Spec.InferenceRef)
+	}
+	got := &SchedulingDecesion{}
+	if err := got.ConvertFrom(hub); err != nil {
+		t.Fatalf("ConvertFrom() error = %v", err)
+	}
+	if _, ok := got.Annotations[AnnotationConversionData]; ok {
+		t.Errorf("ConvertFrom() stashed %s without v1alpha2 fields", AnnotationConversionData)
+	}
+	if !equality.Semantic.DeepEqual(got, spoke) {
+		t.Errorf("round trip = %+v, want %+v", got, spoke)
+	}
+}
@@ -36,6 +36,11 @@ type SchedulingDecesionSpec struct {
 	Objective SchedulingObjective `json:"schedulingResult,omitempty"`
 
 	ResultTime metav1.Time `json:"resultTime"`
+
+	// DeadlineSeconds is the deadline for the decision to be completed after it is applied, defaults to 300.
+	// A TransitionScaling decision which misses the deadline is rolled back.
+	// +kubebuilder:validation:Minimum=1
+	DeadlineSeconds *int32 `json:"deadlineSeconds,omitempty"`
 }
 
 type SchedulingAlgorithm string
@@ -46,10 +51,11 @@ const (
 )
 
 type SchedulingObjective struct {
-	Type           SchedulingType `json:"type"`
-	TargetPod      corev1.Pod     `json:"targetPod"`
-	TargetNode     corev1.Node    `json:"targetNode"`
-	ScalingReplica int32          `json:"scalingReplica"`
+	Type       SchedulingType `json:"type"`
+	TargetPod  corev1.Pod     `json:"targetPod"`
+	TargetNode corev1.Node    `json:"targetNode"`
+	// ScalingReplica is the expected replicas of the Inference, bounded by its min and max replicas.
+	ScalingReplica int32 `json:"scalingReplica"`
 }
 
 // SchedulingDecesionStatus defines the observed state of SchedulingDecesion
@@ -64,12 +70,37 @@ type SchedulingDecesionStatus struct {
 	//Is this sd is used to scheduling.
 	Used bool `json:"used"`
 
+	// Phase is the execution phase of this SchedulingDecesion.
+	Phase SchedulingDecesionPhase `json:"phase,omitempty"`
+
+	// Reason is a brief CamelCase reason for the current phase.
+	Reason string `json:"reason,omitempty"`
+
+	// A human readable message indicating details about the execution.
+	Message string `json:"message,omitempty"`
+
+	// PreviousReplicas is the replicas of the Inference before the decision is applied.
+	PreviousReplicas *int32 `json:"previousReplicas,omitempty"`
+
+	// NewReplicas is the replicas of the Inference set by the decision.
+	NewReplicas *int32 `json:"newReplicas,omitempty"`
+
+	// PreviousRevision is the revision of the serving deployment before the decision is applied.
+	PreviousRevision string `json:"previousRevision,omitempty"`
+
+	//The time SchedulingDecesion has been applied to the cluster.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
 	//The time SchedulingDecesion has been completed.
 	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
+//+kubebuilder:deprecatedversion:warning="melody.io.melody.io/v1alpha1 SchedulingDecesion is deprecated, use melody.io.melody.io/v1alpha2"
+//+kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.schedulingResult.type`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // SchedulingDecesion is the Schema for the schedulingdecesions API
 type SchedulingDecesion struct {
@@ -97,6 +128,15 @@ const (
 	TransitionScaling SchedulingType = "TransitionScaling"
 )
 
+type SchedulingDecesionPhase string
+
+const (
+	DecesionPending   SchedulingDecesionPhase = "Pending"
+	DecesionRunning   SchedulingDecesionPhase = "Running"
+	DecesionSucceeded SchedulingDecesionPhase = "Succeeded"
+	DecesionFailed    SchedulingDecesionPhase = "Failed"
+)
+
 func init() {
 	SchemeBuilder.Register(&SchedulingDecesion{}, &SchedulingDecesionList{})
 }
@@ -22,9 +22,28 @@ limitations under the License.
 package v1alpha1
 
 import (
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ActiveDecesionStatus) DeepCopyInto(out *ActiveDecesionStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ActiveDecesionStatus.
+func (in *ActiveDecesionStatus) DeepCopy() *ActiveDecesionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ActiveDecesionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Inference) DeepCopyInto(out *Inference) {
 	*out = *in
@@ -92,6 +111,21 @@ func (in *InferenceSpec) DeepCopyInto(out *InferenceSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.MinReplicas != nil {
+		in, out := &in.MinReplicas, &out.MinReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxReplicas != nil {
+		in, out := &in.MaxReplicas, &out.MaxReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(int32)
+		**out = **in
+	}
 	if in.Servings != nil {
 		in, out := &in.Servings, &out.Servings
 		*out = make([]ServingSpec, len(*in))
@@ -129,6 +163,11 @@ func (in *InferenceStatus) DeepCopyInto(out *InferenceStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ActiveDecesion != nil {
+		in, out := &in.ActiveDecesion, &out.ActiveDecesion
+		*out = new(ActiveDecesionStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InferenceStatus.
@@ -208,14 +247,13 @@ func (in *SchedulingDecesionSpec) DeepCopyInto(out *SchedulingDecesionSpec) {
 		*out = new(SchedulingAlgorithm)
 		**out = **in
 	}
-	if in.Result != nil {
-		in, out := &in.Result, &out.Result
-		*out = make([]SchedulingResult, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	in.Objective.DeepCopyInto(&out.Objective)
 	in.ResultTime.DeepCopyInto(&out.ResultTime)
+	if in.DeadlineSeconds != nil {
+		in, out := &in.DeadlineSeconds, &out.DeadlineSeconds
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingDecesionSpec.
@@ -232,6 +270,20 @@ func (in *SchedulingDecesionSpec) DeepCopy() *SchedulingDecesionSpec {
 func (in *SchedulingDecesionStatus) DeepCopyInto(out *SchedulingDecesionStatus) {
 	*out = *in
 	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	if in.PreviousReplicas != nil {
+		in, out := &in.PreviousReplicas, &out.PreviousReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NewReplicas != nil {
+		in, out := &in.NewReplicas, &out.NewReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
 	if in.CompletionTime != nil {
 		in, out := &in.CompletionTime, &out.CompletionTime
 		*out = (*in).DeepCopy()
@@ -249,17 +301,54 @@ func (in *SchedulingDecesionStatus) DeepCopy() *SchedulingDecesionStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *SchedulingResult) DeepCopyInto(out *SchedulingResult) {
+func (in *SchedulingObjective) DeepCopyInto(out *SchedulingObjective) {
 	*out = *in
+	in.TargetPod.DeepCopyInto(&out.TargetPod)
 	in.TargetNode.DeepCopyInto(&out.TargetNode)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingResult.
-func (in *SchedulingResult) DeepCopy() *SchedulingResult {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingObjective.
+func (in *SchedulingObjective) DeepCopy() *SchedulingObjective {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulingObjective)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServingMetrics) DeepCopyInto(out *ServingMetrics) {
+	*out = *in
+	in.LastScrapeTime.DeepCopyInto(&out.LastScrapeTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServingMetrics.
+func (in *ServingMetrics) DeepCopy() *ServingMetrics {
 	if in == nil {
 		return nil
 	}
-	out := new(SchedulingResult)
+	out := new(ServingMetrics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServingMetricsSpec) DeepCopyInto(out *ServingMetricsSpec) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServingMetricsSpec.
+func (in *ServingMetricsSpec) DeepCopy() *ServingMetricsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServingMetricsSpec)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -272,6 +361,11 @@ func (in *ServingSpec) DeepCopyInto(out *ServingSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(ServingMetricsSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServingSpec.
@@ -289,6 +383,11 @@ func (in *ServingStatus) DeepCopyInto(out *ServingStatus) {
 	*out = *in
 	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
 	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.Metrics != nil {
+		in, out := &in.Metrics, &out.Metrics
+		*out = new(ServingMetrics)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServingStatus.
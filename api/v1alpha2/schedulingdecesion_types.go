@@ -0,0 +1,280 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// SchedulingDecesionSpec defines the desired state of SchedulingDecesion
+type SchedulingDecesionSpec struct {
+	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
+	// Important: Run "make" to regenerate code after modifying this file
+
+	// InferenceRef references the Inference in the same namespace the decision is applied to.
+	// If not set, the Inference is resolved from the labels of the target pod.
+	InferenceRef *corev1.LocalObjectReference `json:"inferenceRef,omitempty"`
+
+	// Algorithm specifies the scheduling algorithm (i.e. DQN) for serving tasks.
+	Algorithm *SchedulingAlgorithm `json:"algorithm,omitempty"`
+
+	//SchedulingResult specifies
+	Objective SchedulingObjective `json:"schedulingResult,omitempty"`
+
+	ResultTime metav1.Time `json:"resultTime"`
+
+	// Weights weigh the objectives the reward of the decision is computed for, copied from the SchedulingPolicy which
+	// created the decision. Balance and latency are weighed equally if not set.
+	Weights *ObjectiveWeights `json:"weights,omitempty"`
+
+	// DryRun evaluates what the decision would change without applying it.
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// DeadlineSeconds is the deadline for the decision to be completed after it is applied, defaults to 300.
+	// A TransitionScaling decision which misses the deadline is rolled back.
+	// +kubebuilder:validation:Minimum=1
+	DeadlineSeconds *int32 `json:"deadlineSeconds,omitempty"`
+}
+
+// SchedulingAlgorithm is the name of a scheduling algorithm registered in the manager. The built-in algorithms are
+// always registered, the others are registered at startup, or called on the algorithm server.
+type SchedulingAlgorithm string
+
+const (
+	DQNScheduling     SchedulingAlgorithm = "DQN"
+	DefaultScheduling SchedulingAlgorithm = "default"
+	// PPOScheduling and A2CScheduling are the policy gradient algorithms of the algorithm server.
+	PPOScheduling SchedulingAlgorithm = "PPO"
+	A2CScheduling SchedulingAlgorithm = "A2C"
+	// LeastLoadedScheduling moves the serving pods from the most loaded nodes onto the least loaded nodes, which is
+	// the default algorithm.
+	LeastLoadedScheduling SchedulingAlgorithm = "least-loaded"
+	// BinPackingScheduling packs the serving pods onto fewer nodes.
+	BinPackingScheduling SchedulingAlgorithm = "bin-packing"
+	// SpreadScheduling spreads the serving pods of an Inference across the nodes.
+	SpreadScheduling SchedulingAlgorithm = "spread"
+	// GreedyScheduling moves the serving pod which improves the weighted balance of the nodes the most.
+	GreedyScheduling SchedulingAlgorithm = "greedy"
+)
+
+type SchedulingObjective struct {
+	Type SchedulingType `json:"type"`
+	// TargetPod references the serving pod to be scheduled.
+	TargetPod ObjectReference `json:"targetPod,omitempty"`
+	// TargetNode references the node the serving pod is scheduled onto.
+	TargetNode ObjectReference `json:"targetNode,omitempty"`
+	// ScalingReplica is the expected replicas of the Inference, bounded by its min and max replicas.
+	ScalingReplica int32 `json:"scalingReplica"`
+}
+
+// ObjectReference references a pod or a node targeted by the decision.
+type ObjectReference struct {
+	// Name of the referent.
+	Name string `json:"name"`
+	// Namespace of the referent, defaults to the namespace of the decision, which a pod must be in. Ignored for nodes.
+	Namespace string `json:"namespace,omitempty"`
+	// UID of the referent. If set, the decision fails when the referent has been replaced.
+	UID types.UID `json:"uid,omitempty"`
+}
+
+// SchedulingPlan describes what a decision changes in the cluster.
+type SchedulingPlan struct {
+	// Deployment is the name of the serving deployment to be patched.
+	Deployment string `json:"deployment,omitempty"`
+	// DeploymentPatch is the JSON merge patch applied to the serving deployment.
+	DeploymentPatch string `json:"deploymentPatch,omitempty"`
+	// PreferredNode is the node the new serving pods of the decision are required onto with node affinity.
+	PreferredNode string `json:"preferredNode,omitempty"`
+	// SteerPods is the number of new serving pods steered onto the preferred node, that is the pod replacing the target
+	// pod and the pods added by the decision. The other serving pods are not changed.
+	SteerPods int32 `json:"steerPods,omitempty"`
+	// ReplicaDelta is the change of the serving replicas.
+	ReplicaDelta int32 `json:"replicaDelta,omitempty"`
+	// NodeRequests is the resource requests reserved on the preferred node until the decision is completed.
+	NodeRequests corev1.ResourceList `json:"nodeRequests,omitempty"`
+}
+
+// NodeState is the requested utilization of a node.
+type NodeState struct {
+	// Name is the name of the node.
+	Name string `json:"name"`
+	// CPU is the ratio of the cpu requests to the allocatable cpu of the node, formatted as a decimal.
+	CPU string `json:"cpu,omitempty"`
+	// Memory is the ratio of the memory requests to the allocatable memory of the node, formatted as a decimal.
+	Memory string `json:"memory,omitempty"`
+}
+
+// SchedulingState is the state of the cluster and the Inference observed around a decision.
+type SchedulingState struct {
+	// ObservedTime is the time the state is observed.
+	ObservedTime metav1.Time `json:"observedTime"`
+	// Nodes are the requested utilizations of the schedulable nodes.
+	Nodes []NodeState `json:"nodes,omitempty"`
+	// CPUImbalance is the standard deviation of the cpu utilizations of the nodes, formatted as a decimal.
+	CPUImbalance string `json:"cpuImbalance,omitempty"`
+	// MemoryImbalance is the standard deviation of the memory utilizations of the nodes, formatted as a decimal.
+	MemoryImbalance string `json:"memoryImbalance,omitempty"`
+	// Latency is the serving latency of the Inference, unset if it is not observed.
+	Latency *metav1.Duration `json:"latency,omitempty"`
+}
+
+// SchedulingFeedback is the outcome of an applied decision, which is fed back to the scheduling algorithm.
+type SchedulingFeedback struct {
+	// Before is the state observed right before the decision is applied.
+	Before *SchedulingState `json:"before,omitempty"`
+	// After is the state observed once the cluster settles after the decision is completed.
+	After *SchedulingState `json:"after,omitempty"`
+	// Reward is the reward of the decision computed from the states, formatted as a decimal.
+	Reward string `json:"reward,omitempty"`
+}
+
+// SchedulingExplanation explains why the algorithm decided the decision.
+type SchedulingExplanation struct {
+	// Candidates are the candidate nodes with their scores from the best, i.e. the Q-values of DQN. The current node
+	// of the target pod is scored for keeping the pod.
+	Candidates []NodeScore `json:"candidates,omitempty"`
+	// Features are the input features which mattered the most for the decision, from the most important.
+	Features []FeatureImportance `json:"features,omitempty"`
+}
+
+// NodeScore is the score of a candidate node, formatted as a decimal.
+type NodeScore struct {
+	Node  string `json:"node"`
+	Score string `json:"score"`
+}
+
+// FeatureImportance is an input feature of the algorithm, i.e. edge-1/cpu, with its value and how much it mattered
+// for the decision, formatted as decimals.
+type FeatureImportance struct {
+	Name       string `json:"name"`
+	Value      string `json:"value"`
+	Importance string `json:"importance"`
+}
+
+// SchedulingDecesionStatus defines the observed state of SchedulingDecesion
+type SchedulingDecesionStatus struct {
+
+	// The last time this condition was updated.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+
+	// Status of the condition, one of True, False, Unknown.
+	Status corev1.ConditionStatus `json:"status"`
+
+	//Is this sd is used to scheduling.
+	Used bool `json:"used"`
+
+	// Phase is the execution phase of this SchedulingDecesion.
+	Phase SchedulingDecesionPhase `json:"phase,omitempty"`
+
+	// Reason is a brief CamelCase reason for the current phase.
+	Reason string `json:"reason,omitempty"`
+
+	// A human readable message indicating details about the execution.
+	Message string `json:"message,omitempty"`
+
+	// PreviousReplicas is the replicas of the Inference before the decision is applied.
+	PreviousReplicas *int32 `json:"previousReplicas,omitempty"`
+
+	// NewReplicas is the replicas of the Inference set by the decision.
+	NewReplicas *int32 `json:"newReplicas,omitempty"`
+
+	// PreviousRevision is the revision of the serving deployment before the decision is applied.
+	PreviousRevision string `json:"previousRevision,omitempty"`
+
+	// Plan is what the decision changes in the cluster, computed before it is applied.
+	Plan *SchedulingPlan `json:"plan,omitempty"`
+
+	// SteeredPods is the number of new serving pods steered onto the preferred node so far.
+	SteeredPods int32 `json:"steeredPods,omitempty"`
+
+	// ApprovedTime is when the approval of a decision which requires manual approval is observed. The TTL of an
+	// approved decision counts from it.
+	ApprovedTime *metav1.Time `json:"approvedTime,omitempty"`
+
+	//The time SchedulingDecesion has been applied to the cluster.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	//The time SchedulingDecesion has been completed.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Feedback is the outcome of the decision, recorded once the cluster settles after the decision is applied.
+	Feedback *SchedulingFeedback `json:"feedback,omitempty"`
+
+	// Explanation explains why the algorithm decided the decision, recorded by the SchedulingPolicy which created it.
+	Explanation *SchedulingExplanation `json:"explanation,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:storageversion
+//+kubebuilder:printcolumn:name="Inference",type=string,JSONPath=`.spec.inferenceRef.name`
+//+kubebuilder:printcolumn:name="Type",type=string,JSONPath=`.spec.schedulingResult.type`
+//+kubebuilder:printcolumn:name="Pod",type=string,JSONPath=`.spec.schedulingResult.targetPod.name`
+//+kubebuilder:printcolumn:name="Node",type=string,JSONPath=`.spec.schedulingResult.targetNode.name`
+//+kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`
+//+kubebuilder:printcolumn:name="Reward",type=string,JSONPath=`.status.feedback.reward`,priority=1
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// SchedulingDecesion is the Schema for the schedulingdecesions API
+type SchedulingDecesion struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SchedulingDecesionSpec   `json:"spec,omitempty"`
+	Status SchedulingDecesionStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SchedulingDecesionList contains a list of SchedulingDecesion
+type SchedulingDecesionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SchedulingDecesion `json:"items"`
+}
+
+type SchedulingType string
+
+const (
+	Transition        SchedulingType = "Transition"
+	Scaling           SchedulingType = "Scaling"
+	TransitionScaling SchedulingType = "TransitionScaling"
+)
+
+type SchedulingDecesionPhase string
+
+const (
+	DecesionPending   SchedulingDecesionPhase = "Pending"
+	DecesionRunning   SchedulingDecesionPhase = "Running"
+	DecesionSucceeded SchedulingDecesionPhase = "Succeeded"
+	DecesionFailed    SchedulingDecesionPhase = "Failed"
+	// DecesionEvaluated means the decision is evaluated in dry-run mode, but not applied.
+	DecesionEvaluated SchedulingDecesionPhase = "Evaluated"
+	// DecesionRejected means the decision is never applied, i.e. it is expired or superseded.
+	DecesionRejected SchedulingDecesionPhase = "Rejected"
+	// DecesionPendingApproval means the decision is held until it is approved by an operator.
+	DecesionPendingApproval SchedulingDecesionPhase = "PendingApproval"
+)
+
+func init() {
+	SchemeBuilder.Register(&SchedulingDecesion{}, &SchedulingDecesionList{})
+}
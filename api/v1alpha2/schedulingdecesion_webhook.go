@@ -0,0 +1,64 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package v1alpha2
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// SetupWebhookWithManager registers the conversion and validating webhooks of SchedulingDecesion with the manager.
+func (r *SchedulingDecesion) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-melody-io-melody-io-v1alpha2-schedulingdecesion,mutating=false,failurePolicy=fail,sideEffects=None,groups=melody.io.melody.io,resources=schedulingdecesions,verbs=create;update,versions=v1alpha2,name=vschedulingdecesion.melody.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &SchedulingDecesion{}
+
+// ValidateCreate implements webhook.Validator.
+func (r *SchedulingDecesion) ValidateCreate() error {
+	return r.validateSchedulingDecesion()
+}
+
+// ValidateUpdate implements webhook.Validator.
+func (r *SchedulingDecesion) ValidateUpdate(old runtime.Object) error {
+	return r.validateSchedulingDecesion()
+}
+
+// ValidateDelete implements webhook.Validator.
+func (r *SchedulingDecesion) ValidateDelete() error {
+	return nil
+}
+
+// validateSchedulingDecesion rejects a target pod in another namespace, the target pod and its Inference are resolved in
+// the namespace of the decision.
+func (r *SchedulingDecesion) validateSchedulingDecesion() error {
+	var errs field.ErrorList
+	targetPod := field.NewPath("spec", "objective", "targetPod")
+	if ns := r.Spec.Objective.TargetPod.Namespace; ns != "" && ns != r.Namespace {
+		errs = append(errs, field.Invalid(targetPod.Child("namespace"), ns, "must be the namespace of the decision"))
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("SchedulingDecesion").GroupKind(), r.Name, errs)
+}
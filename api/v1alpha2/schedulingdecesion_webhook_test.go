@@ -0,0 +1,37 @@
+package v1alpha2
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidateSchedulingDecesion(t *testing.T) {
+	for _, tc := range []struct {
+		name         string
+		podNamespace string
+		valid        bool
+	}{
+		{name: "default namespace", valid: true},
+		{name: "same namespace", podNamespace: "default", valid: true},
+		{name: "other namespace", podNamespace: "kube-system", valid: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			decesion := &SchedulingDecesion{
+				ObjectMeta: metav1.ObjectMeta{Name: "move", Namespace: "default"},
+				Spec: SchedulingDecesionSpec{Objective: SchedulingObjective{
+					Type:      Transition,
+					TargetPod: ObjectReference{Name: "resnet-0", Namespace: tc.podNamespace},
+				}},
+			}
+			for verb, err := range map[string]error{
+				"create": decesion.ValidateCreate(),
+				"update": decesion.ValidateUpdate(&SchedulingDecesion{}),
+			} {
+				if (err == nil) != tc.valid {
+					t.Errorf("expected %s to be valid %v, got %v", verb, tc.valid, err)
+				}
+			}
+		})
+	}
+}
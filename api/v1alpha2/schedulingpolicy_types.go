@@ -0,0 +1,131 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SchedulingPolicySpec defines the desired state of SchedulingPolicy
+type SchedulingPolicySpec struct {
+	// Selector selects the Inferences in the namespace of the policy to be scheduled.
+	Selector metav1.LabelSelector `json:"selector"`
+
+	// IntervalSeconds is the interval between two scheduling rounds, defaults to 60.
+	// +kubebuilder:validation:Minimum=10
+	// +kubebuilder:default=60
+	IntervalSeconds int32 `json:"intervalSeconds,omitempty"`
+
+	// Algorithm is the scheduling algorithm called in each round, defaults to default.
+	// +kubebuilder:default=default
+	Algorithm SchedulingAlgorithm `json:"algorithm,omitempty"`
+
+	// AllowedTypes are the scheduling types the decisions may have. All types are allowed if empty.
+	AllowedTypes []SchedulingType `json:"allowedTypes,omitempty"`
+
+	// Weights weigh the objectives of the scheduling against each other. They are passed to the algorithm, and
+	// copied to the decisions to compute their rewards. Balance and latency are weighed equally if not set.
+	Weights *ObjectiveWeights `json:"weights,omitempty"`
+
+	// Suspend stops creating decisions, the decisions created already are still executed.
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// ObjectiveWeights weighs the objectives of the scheduling against each other, i.e. latency first for video analytics,
+// or balance first for time series. The weights are relative, an objective with weight 0 is ignored.
+type ObjectiveWeights struct {
+	// CPUBalance weighs balancing the cpu utilization of the nodes, defaults to 1.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	CPUBalance int32 `json:"cpuBalance"`
+
+	// MemoryBalance weighs balancing the memory utilization of the nodes, defaults to 1.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	MemoryBalance int32 `json:"memoryBalance"`
+
+	// Latency weighs the serving latency of the Inferences, defaults to 1.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=1
+	Latency int32 `json:"latency"`
+
+	// MigrationCost weighs the cost of moving a serving pod onto another node, defaults to 0.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=0
+	MigrationCost int32 `json:"migrationCost"`
+}
+
+// SchedulingPolicyStatus defines the observed state of SchedulingPolicy
+type SchedulingPolicyStatus struct {
+	// LastScheduleTime is the last time the algorithm is called.
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastDecesions are the decisions created in the last round.
+	LastDecesions []corev1.LocalObjectReference `json:"lastDecesions,omitempty"`
+
+	// SelectedInferences is the number of Inferences selected in the last round.
+	SelectedInferences int32 `json:"selectedInferences,omitempty"`
+
+	// Reason is a brief CamelCase reason for the result of the last round.
+	Reason string `json:"reason,omitempty"`
+
+	// A human readable message indicating details about the last round.
+	Message string `json:"message,omitempty"`
+
+	// Conditions are the conditions of the policy, i.e. FallbackActive.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+const (
+	// PolicyFallbackActive is the condition type which is true while the decisions are produced by the fallback
+	// algorithm instead of the algorithm of the policy.
+	PolicyFallbackActive = "FallbackActive"
+)
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Algorithm",type=string,JSONPath=`.spec.algorithm`
+//+kubebuilder:printcolumn:name="Interval",type=integer,JSONPath=`.spec.intervalSeconds`
+//+kubebuilder:printcolumn:name="Inferences",type=integer,JSONPath=`.status.selectedInferences`
+//+kubebuilder:printcolumn:name="Last Schedule",type=date,JSONPath=`.status.lastScheduleTime`
+//+kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.reason`
+//+kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// SchedulingPolicy is the Schema for the schedulingpolicies API
+type SchedulingPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SchedulingPolicySpec   `json:"spec,omitempty"`
+	Status SchedulingPolicyStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// SchedulingPolicyList contains a list of SchedulingPolicy
+type SchedulingPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SchedulingPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SchedulingPolicy{}, &SchedulingPolicyList{})
+}
@@ -0,0 +1,483 @@
+//go:build !ignore_autogenerated
+// +build !ignore_autogenerated
+
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FeatureImportance) DeepCopyInto(out *FeatureImportance) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FeatureImportance.
+func (in *FeatureImportance) DeepCopy() *FeatureImportance {
+	if in == nil {
+		return nil
+	}
+	out := new(FeatureImportance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeScore) DeepCopyInto(out *NodeScore) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeScore.
+func (in *NodeScore) DeepCopy() *NodeScore {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeScore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeState) DeepCopyInto(out *NodeState) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeState.
+func (in *NodeState) DeepCopy() *NodeState {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectReference) DeepCopyInto(out *ObjectReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectReference.
+func (in *ObjectReference) DeepCopy() *ObjectReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ObjectiveWeights) DeepCopyInto(out *ObjectiveWeights) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ObjectiveWeights.
+func (in *ObjectiveWeights) DeepCopy() *ObjectiveWeights {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectiveWeights)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulingDecesion) DeepCopyInto(out *SchedulingDecesion) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingDecesion.
+func (in *SchedulingDecesion) DeepCopy() *SchedulingDecesion {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulingDecesion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SchedulingDecesion) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulingDecesionList) DeepCopyInto(out *SchedulingDecesionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SchedulingDecesion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingDecesionList.
+func (in *SchedulingDecesionList) DeepCopy() *SchedulingDecesionList {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulingDecesionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SchedulingDecesionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulingDecesionSpec) DeepCopyInto(out *SchedulingDecesionSpec) {
+	*out = *in
+	if in.InferenceRef != nil {
+		in, out := &in.InferenceRef, &out.InferenceRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.Algorithm != nil {
+		in, out := &in.Algorithm, &out.Algorithm
+		*out = new(SchedulingAlgorithm)
+		**out = **in
+	}
+	out.Objective = in.Objective
+	in.ResultTime.DeepCopyInto(&out.ResultTime)
+	if in.Weights != nil {
+		in, out := &in.Weights, &out.Weights
+		*out = new(ObjectiveWeights)
+		**out = **in
+	}
+	if in.DeadlineSeconds != nil {
+		in, out := &in.DeadlineSeconds, &out.DeadlineSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingDecesionSpec.
+func (in *SchedulingDecesionSpec) DeepCopy() *SchedulingDecesionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulingDecesionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulingDecesionStatus) DeepCopyInto(out *SchedulingDecesionStatus) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	if in.PreviousReplicas != nil {
+		in, out := &in.PreviousReplicas, &out.PreviousReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NewReplicas != nil {
+		in, out := &in.NewReplicas, &out.NewReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Plan != nil {
+		in, out := &in.Plan, &out.Plan
+		*out = new(SchedulingPlan)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ApprovedTime != nil {
+		in, out := &in.ApprovedTime, &out.ApprovedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Feedback != nil {
+		in, out := &in.Feedback, &out.Feedback
+		*out = new(SchedulingFeedback)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Explanation != nil {
+		in, out := &in.Explanation, &out.Explanation
+		*out = new(SchedulingExplanation)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingDecesionStatus.
+func (in *SchedulingDecesionStatus) DeepCopy() *SchedulingDecesionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulingDecesionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulingExplanation) DeepCopyInto(out *SchedulingExplanation) {
+	*out = *in
+	if in.Candidates != nil {
+		in, out := &in.Candidates, &out.Candidates
+		*out = make([]NodeScore, len(*in))
+		copy(*out, *in)
+	}
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make([]FeatureImportance, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingExplanation.
+func (in *SchedulingExplanation) DeepCopy() *SchedulingExplanation {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulingExplanation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulingFeedback) DeepCopyInto(out *SchedulingFeedback) {
+	*out = *in
+	if in.Before != nil {
+		in, out := &in.Before, &out.Before
+		*out = new(SchedulingState)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.After != nil {
+		in, out := &in.After, &out.After
+		*out = new(SchedulingState)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingFeedback.
+func (in *SchedulingFeedback) DeepCopy() *SchedulingFeedback {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulingFeedback)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulingObjective) DeepCopyInto(out *SchedulingObjective) {
+	*out = *in
+	out.TargetPod = in.TargetPod
+	out.TargetNode = in.TargetNode
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingObjective.
+func (in *SchedulingObjective) DeepCopy() *SchedulingObjective {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulingObjective)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulingPlan) DeepCopyInto(out *SchedulingPlan) {
+	*out = *in
+	if in.NodeRequests != nil {
+		in, out := &in.NodeRequests, &out.NodeRequests
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingPlan.
+func (in *SchedulingPlan) DeepCopy() *SchedulingPlan {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulingPlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulingPolicy) DeepCopyInto(out *SchedulingPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingPolicy.
+func (in *SchedulingPolicy) DeepCopy() *SchedulingPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulingPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SchedulingPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulingPolicyList) DeepCopyInto(out *SchedulingPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SchedulingPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingPolicyList.
+func (in *SchedulingPolicyList) DeepCopy() *SchedulingPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulingPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SchedulingPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulingPolicySpec) DeepCopyInto(out *SchedulingPolicySpec) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+	if in.AllowedTypes != nil {
+		in, out := &in.AllowedTypes, &out.AllowedTypes
+		*out = make([]SchedulingType, len(*in))
+		copy(*out, *in)
+	}
+	if in.Weights != nil {
+		in, out := &in.Weights, &out.Weights
+		*out = new(ObjectiveWeights)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingPolicySpec.
+func (in *SchedulingPolicySpec) DeepCopy() *SchedulingPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulingPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulingPolicyStatus) DeepCopyInto(out *SchedulingPolicyStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastDecesions != nil {
+		in, out := &in.LastDecesions, &out.LastDecesions
+		*out = make([]v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingPolicyStatus.
+func (in *SchedulingPolicyStatus) DeepCopy() *SchedulingPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulingPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SchedulingState) DeepCopyInto(out *SchedulingState) {
+	*out = *in
+	in.ObservedTime.DeepCopyInto(&out.ObservedTime)
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]NodeState, len(*in))
+		copy(*out, *in)
+	}
+	if in.Latency != nil {
+		in, out := &in.Latency, &out.Latency
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SchedulingState.
+func (in *SchedulingState) DeepCopy() *SchedulingState {
+	if in == nil {
+		return nil
+	}
+	out := new(SchedulingState)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,96 @@
+// Command simulator evaluates a scheduling algorithm against a simulated edge cluster, or serves the simulator to an
+// external RL agent.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/go-logr/logr"
+
+	melodyiov1alpha2 "melody/api/v1alpha2"
+	"melody/pkg/algorithm"
+	"melody/pkg/dqn"
+	"melody/pkg/scheduler"
+	"melody/pkg/simulator"
+)
+
+func main() {
+	var configPath, algorithmName, registryPath, dqnModelPath, serveAddr string
+	flag.StringVar(&configPath, "config", "", "The JSON config of the simulated cluster and the trace of the request load.")
+	flag.StringVar(&algorithmName, "algorithm", string(melodyiov1alpha2.DefaultScheduling),
+		"The algorithm to evaluate. The algorithms other than the built-in ones are called on the algorithm server.")
+	flag.StringVar(&registryPath, "algorithm-registry", "", "The YAML file registering the scheduling algorithms by name.")
+	flag.StringVar(&dqnModelPath, "dqn-model-path", "", "The DQN weight file evaluated in process for the DQN algorithm.")
+	flag.StringVar(&serveAddr, "serve", "", "Serve the simulator to an external agent on the address instead of evaluating.")
+	algorithmConfig := algorithm.NewConfig()
+	algorithmConfig.BindFlags(flag.CommandLine)
+	flag.Parse()
+
+	if err := run(configPath, algorithmName, registryPath, dqnModelPath, serveAddr, algorithmConfig); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(configPath, algorithmName, registryPath, dqnModelPath, serveAddr string, algorithmConfig *algorithm.Config) error {
+	if configPath == "" {
+		return fmt.Errorf("--config is required")
+	}
+	config, err := simulator.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+	sim, err := simulator.New(*config)
+	if err != nil {
+		return err
+	}
+	if serveAddr != "" {
+		fmt.Fprintf(os.Stderr, "serving simulator on %s\n", serveAddr)
+		return http.ListenAndServe(serveAddr, simulator.NewHandler(sim))
+	}
+
+	sched, err := schedulerFor(melodyiov1alpha2.SchedulingAlgorithm(algorithmName), registryPath, dqnModelPath, algorithmConfig)
+	if err != nil {
+		return err
+	}
+	result, err := simulator.Evaluate(context.Background(), sim, sched)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+func schedulerFor(algorithmName melodyiov1alpha2.SchedulingAlgorithm, registryPath, dqnModelPath string, algorithmConfig *algorithm.Config) (scheduler.Scheduler, error) {
+	client, err := algorithm.NewClient(algorithmConfig)
+	if err != nil {
+		return nil, err
+	}
+	registry := scheduler.Builtin()
+	if registryPath != "" {
+		config, err := scheduler.LoadRegistryConfig(registryPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := registry.RegisterAll(config, algorithmConfig, client); err != nil {
+			return nil, err
+		}
+	}
+	if sched, ok := registry[algorithmName]; ok {
+		return sched, nil
+	}
+	if algorithmName == melodyiov1alpha2.DQNScheduling && dqnModelPath != "" {
+		loader := dqn.NewLoader(dqnModelPath, 0, logr.Discard())
+		if _, err := loader.Load(); err != nil {
+			return nil, err
+		}
+		return scheduler.NewDQN(loader), nil
+	}
+	return scheduler.NewRemote(client, algorithmName), nil
+}
@@ -1,18 +1,31 @@
 package consts
 
-import "os"
+import (
+	"os"
+	"time"
+
+	melodyiov1alpha2 "melody/api/v1alpha2"
+)
 
 const (
-	// LabelExperimentName is the label of experiment name.
-	LabelInferenceName = "inference"
+	// LabelInferenceName is the label of the Inference, which is defined by the API.
+	LabelInferenceName = melodyiov1alpha2.LabelInferenceName
 	// LabelTrialName is the label of trial name.
 	LabelSchedulingDecesionName = "schedulingdecesion"
 	// LabelDeploymentName is the label of deployment name.
 	LabelDeploymentName = "deployment"
 	LabelDomainName     = "domain"
+	// LabelSchedulingPolicyName is the label of the SchedulingPolicy which creates a decision.
+	LabelSchedulingPolicyName = "schedulingpolicy"
 	// DefaultServicePort is the default port of sampling_client service.
 	InferenceServicePort   = 8500
 	InferenceContainerPort = 8300
+	// TritonMetricsPort is the default port of the metrics of Triton, the metrics of TF-Serving are served on the
+	// serving port.
+	TritonMetricsPort = 8002
+	// TFServingMetricsPath and TritonMetricsPath are the default paths of the metrics of TF-Serving and Triton.
+	TFServingMetricsPath = "/monitoring/prometheus/metrics"
+	TritonMetricsPath    = "/metrics"
 	// DefaultServicePortName is the default port name of sampling_client service.
 	InferenceServicePortName = "inference-service"
 	// DefaultMetricValue is the default trial result value, set for failed trials
@@ -28,6 +41,21 @@ const (
 	// DefaultMorphlingDBManagerServiceName is the default db-manager k8s service name
 	DefaultMorphlingDBManagerServiceName = "morphling-db-manager"
 
+	// AnnotationSchedulingDecesion records the SchedulingDecesion which steered a serving pod onto its target node.
+	AnnotationSchedulingDecesion = "melody.io/scheduling-decesion"
+	// AnnotationFallbackFrom records the algorithm of the policy on a decision produced by the fallback algorithm.
+	AnnotationFallbackFrom = "melody.io/fallback-from"
+	// NodeNameField is the node field used to pin pods onto a target node.
+	NodeNameField = "metadata.name"
+	// PodNodeNameField is the field index of the node a pod is bound to.
+	PodNodeNameField = "spec.nodeName"
+	// DefaultSchedulingRequeueInterval is the interval to check the progress of a running decision.
+	DefaultSchedulingRequeueInterval = 5 * time.Second
+	// DefaultSchedulingTimeout is the deadline for a decision to be completed after it is applied.
+	DefaultSchedulingTimeout = 5 * time.Minute
+	// DeploymentRevisionAnnotation is the revision annotation of a deployment and its replica sets.
+	DeploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+
 	//DefaultMorphlingDBManagerServicePort = 6799
 	//DefaultMorphlingNamespace            = "morphling-system"
 )
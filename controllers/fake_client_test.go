@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	clienttesting "k8s.io/client-go/testing"
+	melodyiov1alpha1 "melody/api/v1alpha1"
+	melodyiov1alpha2 "melody/api/v1alpha2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// newFakeClient returns a fake client of the objects, for the unit tests which do not need envtest.
+func newFakeClient(t *testing.T, objects ...client.Object) client.Client {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	for _, add := range []func(*runtime.Scheme) error{
+		clientgoscheme.AddToScheme, melodyiov1alpha1.AddToScheme, melodyiov1alpha2.AddToScheme,
+	} {
+		if err := add(scheme); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+}
+
+// newFakeEvictor returns the pods client whose evictions delete the pods from the fake client, or are refused with
+// 429 Too Many Requests as by a disruption budget.
+func newFakeEvictor(c client.Client, refused bool) corev1client.PodsGetter {
+	clientset := kubefake.NewSimpleClientset()
+	clientset.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		create := action.(clienttesting.CreateAction)
+		if create.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		if refused {
+			return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		}
+		eviction := create.GetObject().(client.Object)
+		pod := &corev1.Pod{}
+		pod.Name, pod.Namespace = eviction.GetName(), eviction.GetNamespace()
+		return true, nil, c.Delete(context.TODO(), pod)
+	})
+	return clientset.CoreV1()
+}
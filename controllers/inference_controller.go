@@ -24,7 +24,9 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
@@ -33,6 +35,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	melodyiov1alpha1 "melody/api/v1alpha1"
+	melodyiov1alpha2 "melody/api/v1alpha2"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -83,8 +86,11 @@ type InferenceReconciler struct {
 //type updateStatusFunc func(instance *melodyiov1alpha1.Inference) error
 
 func addWatch(c controller.Controller) error {
-	// Watch for changes to inference
-	err := c.Watch(&source.Kind{Type: &melodyiov1alpha1.Inference{}}, &handler.EnqueueRequestForObject{})
+	// Watch for changes to inference, except the serving metrics which are scraped periodically
+	err := c.Watch(&source.Kind{Type: &melodyiov1alpha1.Inference{}}, &handler.EnqueueRequestForObject{},
+		predicate.Funcs{UpdateFunc: func(e event.UpdateEvent) bool {
+			return !onlyServingMetricsChanged(e.ObjectOld, e.ObjectNew)
+		}})
 	if err != nil {
 		log.Error(err, "Inference watch error")
 		return err
@@ -109,14 +115,35 @@ func addWatch(c controller.Controller) error {
 		log.Error(err, "Inference Service watch error")
 		return err
 	}
+
+	// Watch for changes to scheduling decesions targeting the inference
+	err = c.Watch(&source.Kind{Type: &melodyiov1alpha2.SchedulingDecesion{}}, handler.EnqueueRequestsFromMapFunc(decesionToInference))
+	if err != nil {
+		log.Error(err, "Inference SchedulingDecesion watch error")
+		return err
+	}
 	return nil
 }
 
+// decesionToInference maps a scheduling decesion to the inference it is applied to.
+func decesionToInference(obj client.Object) []reconcile.Request {
+	sd, ok := obj.(*melodyiov1alpha2.SchedulingDecesion)
+	if !ok {
+		return nil
+	}
+	name := util.InferenceNameForDecesion(sd)
+	if name == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name, Namespace: sd.GetNamespace()}}}
+}
+
 // +kubebuilder:rbac:groups=melody.io.melody.io,resources=inferences,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=melody.io.melody.io,resources=inferences/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=melody.io.melody.io,resources=inferences/finalizers,verbs=update
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=services,verbs=get;list;create;update;patch;delete
+// +kubebuilder:rbac:groups=melody.io.melody.io,resources=schedulingdecesions,verbs=get;list;watch
 
 func (r *InferenceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.WithValues("Inference", req.NamespacedName)
@@ -153,6 +180,10 @@ func (r *InferenceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 			logger.Error(err, "Reconcile inference error")
 			return reconcile.Result{}, err
 		}
+		if err = r.updateActiveDecesion(instance); err != nil {
+			logger.Error(err, "Update active scheduling decesion error")
+			return reconcile.Result{}, err
+		}
 	}
 
 	// 4) Compare status before-and-after reconciling and update changes to cluster.
@@ -248,6 +279,33 @@ func (r *InferenceReconciler) updateInferenceStatus(instance *melodyiov1alpha1.I
 	return nil
 }
 
+// updateActiveDecesion shows the running scheduling decision applied to the inference in its status.
+func (r *InferenceReconciler) updateActiveDecesion(instance *melodyiov1alpha1.Inference) error {
+	decesions := &melodyiov1alpha2.SchedulingDecesionList{}
+	if err := r.List(context.TODO(), decesions, client.InNamespace(instance.GetNamespace())); err != nil {
+		return err
+	}
+	var targeting []melodyiov1alpha2.SchedulingDecesion
+	for i := range decesions.Items {
+		if util.InferenceNameForDecesion(&decesions.Items[i]) == instance.GetName() {
+			targeting = append(targeting, decesions.Items[i])
+		}
+	}
+
+	active := util.GetActiveDecesion(targeting)
+	if active == nil {
+		instance.Status.ActiveDecesion = nil
+		return nil
+	}
+	instance.Status.ActiveDecesion = &melodyiov1alpha1.ActiveDecesionStatus{
+		Name:       active.GetName(),
+		Type:       string(active.Spec.Objective.Type),
+		TargetNode: active.Spec.Objective.TargetNode.Name,
+		StartTime:  active.Status.StartTime,
+	}
+	return nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *InferenceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	c, err := controller.New(ControllerName, mgr, controller.Options{Reconciler: r})
@@ -172,6 +172,15 @@ func (r *InferenceReconciler) reconcileServiceDeployment(instance *melodyiov1alp
 				return nil, nil
 			}
 		}
+		// Sync the serving replicas, which may be changed by scheduling decisions
+		if instance.Spec.Replicas != nil && (deploy.Spec.Replicas == nil || *deploy.Spec.Replicas != *instance.Spec.Replicas) {
+			logger.Info("Scaling ML inference deployment", "name", deploy.GetName(), "replicas", *instance.Spec.Replicas)
+			deploy.Spec.Replicas = instance.Spec.Replicas
+			if err = r.Update(context.TODO(), deploy); err != nil {
+				logger.Error(err, "Scale inference deployment error", "name", deploy.GetName())
+				return nil, err
+			}
+		}
 	}
 	return deploy, nil
 }
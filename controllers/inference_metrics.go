@@ -0,0 +1,242 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	melodyiov1alpha1 "melody/api/v1alpha1"
+	consts "melody/controllers/const"
+	util "melody/controllers/utils"
+	"melody/pkg/monitor"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// ServingMetricsScraper scrapes the metrics of the serving containers into the serving statuses of the Inferences
+// every Interval. It runs as a Runnable of the manager, so that the scrapes do not block the reconcilers and the
+// metrics cover the whole interval.
+type ServingMetricsScraper struct {
+	client.Client
+	Log      logr.Logger
+	Scraper  *monitor.ServingScraper
+	Interval time.Duration
+}
+
+// NewServingMetricsScraper returns a scraper of the serving metrics with the timeout of each scrape.
+func NewServingMetricsScraper(mgr manager.Manager, interval, timeout time.Duration) *ServingMetricsScraper {
+	return &ServingMetricsScraper{
+		Client:   mgr.GetClient(),
+		Log:      logf.Log.WithName("serving-metrics"),
+		Scraper:  monitor.NewServingScraper(timeout),
+		Interval: interval,
+	}
+}
+
+// Start scrapes the serving metrics until the context is done.
+func (s *ServingMetricsScraper) Start(ctx context.Context) error {
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		inferences := &melodyiov1alpha1.InferenceList{}
+		if err := s.List(ctx, inferences); err != nil {
+			s.Log.Error(err, "List inferences error")
+			continue
+		}
+		for i := range inferences.Items {
+			if hasServingMetrics(&inferences.Items[i]) {
+				s.scrapeInference(ctx, &inferences.Items[i])
+			}
+		}
+	}
+}
+
+// scrapeInference scrapes the metrics of the servings of the Inference which configure them into their serving
+// statuses. The scraping is best effort, the metrics of a serving which fails to be scraped are kept until the next
+// scrape.
+func (s *ServingMetricsScraper) scrapeInference(ctx context.Context, instance *melodyiov1alpha1.Inference) {
+	logger := s.Log.WithValues("Inference", client.ObjectKeyFromObject(instance))
+	pods := &corev1.PodList{}
+	if err := s.List(ctx, pods, client.InNamespace(instance.Namespace), client.MatchingLabels(util.ServicePodLabels(instance))); err != nil {
+		logger.Error(err, "List serving pods error")
+		return
+	}
+	scraped := make(map[string]*melodyiov1alpha1.ServingMetrics)
+	for pi := range instance.Spec.Servings {
+		serving := &instance.Spec.Servings[pi]
+		if serving.Metrics == nil {
+			continue
+		}
+		var targets []monitor.ServingTarget
+		port, path := servingMetricsEndpoint(serving.Metrics)
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if pod.DeletionTimestamp != nil || pod.Status.PodIP == "" || !util.IsPodReady(pod) {
+				continue
+			}
+			targets = append(targets, monitor.ServingTarget{
+				Key: string(pod.UID) + "/" + serving.Name,
+				URL: fmt.Sprintf("http://%s%s", net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(port))), path),
+			})
+		}
+		if len(targets) == 0 {
+			continue
+		}
+		metrics, err := s.Scraper.Scrape(ctx, monitor.ServingFormat(serving.Metrics.Format), serving.BatchSize, targets)
+		if err != nil {
+			logger.Error(err, "Scrape serving metrics error", "serving", serving.Name)
+			continue
+		}
+		scraped[serving.Name] = newServingMetrics(metrics)
+	}
+	if len(scraped) == 0 {
+		return
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := &melodyiov1alpha1.Inference{}
+		if err := s.Get(ctx, client.ObjectKeyFromObject(instance), latest); err != nil {
+			return err
+		}
+		updated := false
+		for i := range latest.Status.ServingStatuses {
+			if metrics, ok := scraped[latest.Status.ServingStatuses[i].Name]; ok {
+				latest.Status.ServingStatuses[i].Metrics = metrics
+				updated = true
+			}
+		}
+		if !updated {
+			return nil
+		}
+		return s.Status().Update(ctx, latest)
+	})
+	if err != nil && !errors.IsNotFound(err) {
+		logger.Error(err, "Update serving metrics error")
+	}
+}
+
+// newServingMetrics returns the status of the scraped metrics, whose metrics not observed are empty.
+func newServingMetrics(scraped *monitor.ServingMetrics) *melodyiov1alpha1.ServingMetrics {
+	metrics := &melodyiov1alpha1.ServingMetrics{ScrapedPods: int32(scraped.Scraped), LastScrapeTime: metav1.Now()}
+	if scraped.Observed > 0 {
+		metrics.RequestsPerSecond = util.FormatDecimal(scraped.RequestsPerSecond)
+		if scraped.LatencyP50Milliseconds > 0 {
+			metrics.LatencyP50Milliseconds = util.FormatDecimal(scraped.LatencyP50Milliseconds)
+			metrics.LatencyP99Milliseconds = util.FormatDecimal(scraped.LatencyP99Milliseconds)
+		}
+		if scraped.BatchUtilization > 0 {
+			metrics.BatchUtilization = util.FormatDecimal(scraped.BatchUtilization)
+		}
+	}
+	return metrics
+}
+
+// onlyServingMetricsChanged returns true if the update of the Inference only changes the scraped serving metrics,
+// which the Inference reconciler does not read, so the scrapes do not reconcile the Inferences.
+func onlyServingMetricsChanged(oldObj, newObj client.Object) bool {
+	oldInference, ok := oldObj.(*melodyiov1alpha1.Inference)
+	if !ok {
+		return false
+	}
+	newInference, ok := newObj.(*melodyiov1alpha1.Inference)
+	if !ok {
+		return false
+	}
+	return reflect.DeepEqual(withoutServingMetrics(oldInference), withoutServingMetrics(newInference))
+}
+
+// withoutServingMetrics returns a copy of the Inference without the serving metrics and the metadata every update
+// changes.
+func withoutServingMetrics(instance *melodyiov1alpha1.Inference) *melodyiov1alpha1.Inference {
+	stripped := instance.DeepCopy()
+	stripped.ResourceVersion = ""
+	stripped.ManagedFields = nil
+	for i := range stripped.Status.ServingStatuses {
+		stripped.Status.ServingStatuses[i].Metrics = nil
+	}
+	return stripped
+}
+
+// hasServingMetrics returns true if a serving of the Inference configures its metrics.
+func hasServingMetrics(instance *melodyiov1alpha1.Inference) bool {
+	for i := range instance.Spec.Servings {
+		if instance.Spec.Servings[i].Metrics != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// servingMetricsEndpoint returns the port and the path of the metrics of the serving, the defaults of the format if
+// not set.
+func servingMetricsEndpoint(spec *melodyiov1alpha1.ServingMetricsSpec) (int32, string) {
+	port, path := int32(consts.InferenceContainerPort), consts.TFServingMetricsPath
+	if spec.Format == melodyiov1alpha1.TritonMetricsFormat {
+		port, path = consts.TritonMetricsPort, consts.TritonMetricsPath
+	}
+	if spec.Port != nil {
+		port = *spec.Port
+	}
+	if spec.Path != "" {
+		path = spec.Path
+	}
+	return port, path
+}
+
+// servingMetricsMaxAge is the age after which the scraped serving metrics are stale, i.e. when the scraping stops.
+const servingMetricsMaxAge = 10 * time.Minute
+
+// inferenceServingMetrics aggregates the serving metrics of the servings of the Inference which are not stale: the
+// latencies of the slowest serving, the total request rate and the mean batch utilization. It returns false if no
+// serving has metrics.
+func inferenceServingMetrics(inference *melodyiov1alpha1.Inference) (monitor.ServingMetrics, bool) {
+	aggregated := monitor.ServingMetrics{}
+	var batched int
+	for i := range inference.Status.ServingStatuses {
+		metrics := inference.Status.ServingStatuses[i].Metrics
+		if metrics == nil || metrics.RequestsPerSecond == "" || time.Since(metrics.LastScrapeTime.Time) > servingMetricsMaxAge {
+			continue
+		}
+		aggregated.Observed++
+		aggregated.RequestsPerSecond += util.ParseDecimal(metrics.RequestsPerSecond)
+		if p50 := util.ParseDecimal(metrics.LatencyP50Milliseconds); p50 > aggregated.LatencyP50Milliseconds {
+			aggregated.LatencyP50Milliseconds = p50
+		}
+		if p99 := util.ParseDecimal(metrics.LatencyP99Milliseconds); p99 > aggregated.LatencyP99Milliseconds {
+			aggregated.LatencyP99Milliseconds = p99
+		}
+		if metrics.BatchUtilization != "" {
+			aggregated.BatchUtilization += util.ParseDecimal(metrics.BatchUtilization)
+			batched++
+		}
+	}
+	if batched > 0 {
+		aggregated.BatchUtilization /= float64(batched)
+	}
+	return aggregated, aggregated.Observed > 0
+}
+
+// ServingLatency observes the median serving latency of an Inference from the serving metrics in its status.
+type ServingLatency struct{}
+
+func (ServingLatency) ObserveLatency(ctx context.Context, inference *melodyiov1alpha1.Inference) (time.Duration, bool, error) {
+	metrics, ok := inferenceServingMetrics(inference)
+	if !ok || metrics.LatencyP50Milliseconds <= 0 {
+		return 0, false, nil
+	}
+	return time.Duration(metrics.LatencyP50Milliseconds * float64(time.Millisecond)), true, nil
+}
@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	melodyiov1alpha1 "melody/api/v1alpha1"
+	util "melody/controllers/utils"
+	"melody/pkg/monitor"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestScrapeInference(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests += 40
+		fmt.Fprintf(w, "nv_inference_request_success{model=\"resnet\"} %d\n"+
+			"nv_inference_count{model=\"resnet\"} %d\nnv_inference_exec_count{model=\"resnet\"} %d\n",
+			requests, requests, requests/4)
+	}))
+	defer server.Close()
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	metricsPort, _ := strconv.Atoi(port)
+
+	inference := &melodyiov1alpha1.Inference{
+		ObjectMeta: metav1.ObjectMeta{Name: "resnet", Namespace: "default"},
+		Spec: melodyiov1alpha1.InferenceSpec{Servings: []melodyiov1alpha1.ServingSpec{{
+			Name:      "resnet",
+			BatchSize: 8,
+			Metrics: &melodyiov1alpha1.ServingMetricsSpec{
+				Format: melodyiov1alpha1.TritonMetricsFormat,
+				Port:   func(p int32) *int32 { return &p }(int32(metricsPort)),
+				Path:   "/metrics",
+			},
+		}}},
+		Status: melodyiov1alpha1.InferenceStatus{ServingStatuses: []melodyiov1alpha1.ServingStatus{{Name: "resnet"}}},
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "resnet-0", Namespace: "default", UID: "uid-0", Labels: util.ServicePodLabels(inference)},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			PodIP:      host,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	s := &ServingMetricsScraper{
+		Client:   newFakeClient(t, inference, pod),
+		Log:      logr.Discard(),
+		Scraper:  monitor.NewServingScraper(time.Second),
+		Interval: time.Minute,
+	}
+
+	// The first scrape observes no interval, the second one observes the requests since the first one
+	for i := 0; i < 2; i++ {
+		s.scrapeInference(context.Background(), inference)
+	}
+	scraped := &melodyiov1alpha1.Inference{}
+	if err := s.Get(context.Background(), client.ObjectKeyFromObject(inference), scraped); err != nil {
+		t.Fatal(err)
+	}
+	metrics := scraped.Status.ServingStatuses[0].Metrics
+	if metrics == nil || metrics.ScrapedPods != 1 || metrics.RequestsPerSecond == "" || metrics.BatchUtilization != "0.5000" {
+		t.Fatalf("expected the metrics of 1 pod with batch utilization 0.5, got %+v", metrics)
+	}
+	if _, ok := inferenceServingMetrics(scraped); !ok {
+		t.Error("expected the scraped metrics to be observed")
+	}
+}
+
+func TestServingLatency(t *testing.T) {
+	now := metav1.Now()
+	stale := metav1.NewTime(now.Add(-2 * servingMetricsMaxAge))
+	tests := []struct {
+		name     string
+		statuses []melodyiov1alpha1.ServingStatus
+		want     time.Duration
+		ok       bool
+	}{
+		{name: "not scraped", statuses: []melodyiov1alpha1.ServingStatus{{Name: "resnet"}}},
+		{
+			name: "slowest serving",
+			statuses: []melodyiov1alpha1.ServingStatus{
+				{Name: "resnet", Metrics: &melodyiov1alpha1.ServingMetrics{LatencyP50Milliseconds: "12.5000", RequestsPerSecond: "10.0000", LastScrapeTime: now}},
+				{Name: "bert", Metrics: &melodyiov1alpha1.ServingMetrics{LatencyP50Milliseconds: "30.0000", RequestsPerSecond: "2.0000", LastScrapeTime: now}},
+			},
+			want: 30 * time.Millisecond,
+			ok:   true,
+		},
+		{
+			name: "stale",
+			statuses: []melodyiov1alpha1.ServingStatus{
+				{Name: "resnet", Metrics: &melodyiov1alpha1.ServingMetrics{LatencyP50Milliseconds: "12.5000", RequestsPerSecond: "10.0000", LastScrapeTime: stale}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inference := &melodyiov1alpha1.Inference{Status: melodyiov1alpha1.InferenceStatus{ServingStatuses: tt.statuses}}
+			got, ok, err := ServingLatency{}.ObserveLatency(context.Background(), inference)
+			if err != nil || ok != tt.ok || got != tt.want {
+				t.Errorf("ObserveLatency() = %v, %v, %v, want %v, %v", got, ok, err, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestOnlyServingMetricsChanged(t *testing.T) {
+	old := &melodyiov1alpha1.Inference{
+		ObjectMeta: metav1.ObjectMeta{Name: "resnet", Namespace: "default", ResourceVersion: "1"},
+		Status: melodyiov1alpha1.InferenceStatus{ServingStatuses: []melodyiov1alpha1.ServingStatus{{
+			Name: "resnet", ReadyReplicas: 1, Metrics: &melodyiov1alpha1.ServingMetrics{ScrapedPods: 1},
+		}}},
+	}
+	for _, tc := range []struct {
+		name   string
+		update func(*melodyiov1alpha1.Inference)
+		want   bool
+	}{
+		{name: "metrics", update: func(in *melodyiov1alpha1.Inference) {
+			in.Status.ServingStatuses[0].Metrics = &melodyiov1alpha1.ServingMetrics{ScrapedPods: 2, LastScrapeTime: metav1.Now()}
+		}, want: true},
+		{name: "ready replicas", update: func(in *melodyiov1alpha1.Inference) {
+			in.Status.ServingStatuses[0].Metrics = nil
+			in.Status.ServingStatuses[0].ReadyReplicas = 2
+		}},
+		{name: "labels", update: func(in *melodyiov1alpha1.Inference) {
+			in.Labels = map[string]string{"tier": "edge"}
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			updated := old.DeepCopy()
+			updated.ResourceVersion = "2"
+			tc.update(updated)
+			if got := onlyServingMetricsChanged(old, updated); got != tc.want {
+				t.Errorf("onlyServingMetricsChanged = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"flag"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// SchedulingOptions configures how the scheduling decisions are executed.
+type SchedulingOptions struct {
+	// DecesionTTL rejects the decisions whose result time is older than the TTL. Zero disables the check.
+	DecesionTTL time.Duration
+	// DecesionHistoryLimit is the number of completed decisions retained for each Inference. Zero retains all.
+	DecesionHistoryLimit int
+	// DryRun only evaluates all decisions without applying them.
+	DryRun bool
+	// ApprovalNamespaces is a comma separated list of namespaces, whose decisions require manual approval.
+	ApprovalNamespaces string
+	// ApprovalSelector is a label selector of Inferences, whose decisions require manual approval.
+	ApprovalSelector string
+	// ApprovalTimeout rejects the decisions which are not approved in time. Zero waits forever.
+	ApprovalTimeout time.Duration
+	// MinAvailable is the number of serving pods which must stay ready for the Inferences without minAvailable.
+	MinAvailable int
+	// MaxDecesionsPerMinute is the number of decisions applied in the cluster per minute. Zero is unlimited.
+	MaxDecesionsPerMinute int
+	// DecesionCooldown is the minimum interval between the decisions applied to the same Inference.
+	DecesionCooldown time.Duration
+	// FeedbackSettleWindow is how long the cluster settles after a decision is completed before its outcome is
+	// observed. Zero disables the feedback.
+	FeedbackSettleWindow time.Duration
+}
+
+// NewSchedulingOptions returns the default scheduling options.
+func NewSchedulingOptions() *SchedulingOptions {
+	return &SchedulingOptions{
+		DecesionTTL:          5 * time.Minute,
+		DecesionHistoryLimit: 10,
+		ApprovalTimeout:      time.Hour,
+		MinAvailable:         1,
+		DecesionCooldown:     time.Minute,
+		FeedbackSettleWindow: 2 * time.Minute,
+	}
+}
+
+// BindFlags binds the scheduling options to the command line flags.
+func (o *SchedulingOptions) BindFlags(fs *flag.FlagSet) {
+	fs.DurationVar(&o.DecesionTTL, "decesion-ttl", o.DecesionTTL,
+		"Reject the scheduling decisions whose result time is older than the TTL, 0 disables the check.")
+	fs.IntVar(&o.DecesionHistoryLimit, "decesion-history-limit", o.DecesionHistoryLimit,
+		"The number of completed scheduling decisions retained for each inference, 0 retains all.")
+	fs.BoolVar(&o.DryRun, "dry-run", o.DryRun,
+		"Only evaluate the scheduling decisions and record what they would change, without applying them.")
+	fs.StringVar(&o.ApprovalNamespaces, "approval-namespaces", o.ApprovalNamespaces,
+		"Comma separated namespaces, whose scheduling decisions require manual approval.")
+	fs.StringVar(&o.ApprovalSelector, "approval-selector", o.ApprovalSelector,
+		"Label selector of inferences, whose scheduling decisions require manual approval.")
+	fs.DurationVar(&o.ApprovalTimeout, "approval-timeout", o.ApprovalTimeout,
+		"Reject the scheduling decisions which are not approved in time, 0 waits forever.")
+	fs.IntVar(&o.MinAvailable, "min-available", o.MinAvailable,
+		"The number of serving pods which must stay ready while executing decisions, for inferences without minAvailable.")
+	fs.IntVar(&o.MaxDecesionsPerMinute, "max-decesions-per-minute", o.MaxDecesionsPerMinute,
+		"The number of scheduling decisions applied in the cluster per minute, 0 is unlimited.")
+	fs.DurationVar(&o.DecesionCooldown, "decesion-cooldown", o.DecesionCooldown,
+		"The minimum interval between the scheduling decisions applied to the same inference, 0 disables the cooldown.")
+	fs.DurationVar(&o.FeedbackSettleWindow, "feedback-settle-window", o.FeedbackSettleWindow,
+		"How long the cluster settles after a scheduling decision is completed before its reward is observed, 0 disables the feedback.")
+}
+
+// RequiresApproval returns true if the decisions for the Inference in the namespace with the labels require manual
+// approval before they are applied.
+func (o *SchedulingOptions) RequiresApproval(namespace string, inferenceLabels map[string]string) (bool, error) {
+	for _, ns := range strings.Split(o.ApprovalNamespaces, ",") {
+		if strings.TrimSpace(ns) == namespace {
+			return true, nil
+		}
+	}
+	if o.ApprovalSelector == "" {
+		return false, nil
+	}
+	selector, err := labels.Parse(o.ApprovalSelector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(inferenceLabels)), nil
+}
+
+// PolicyOptions configures how the scheduling policies call the algorithms.
+type PolicyOptions struct {
+	// FallbackAlgorithm is called when an algorithm fails or its circuit is open.
+	FallbackAlgorithm string
+	// FailureThreshold is the number of failures in a row of an algorithm to open its circuit.
+	FailureThreshold int
+	// OpenDuration is how long the circuit of an algorithm stays open before the algorithm is tried again.
+	OpenDuration time.Duration
+	// AlgorithmTimeout is the timeout of a call to an algorithm, including its retries.
+	AlgorithmTimeout time.Duration
+}
+
+// NewPolicyOptions returns the default policy options.
+func NewPolicyOptions() *PolicyOptions {
+	return &PolicyOptions{
+		FallbackAlgorithm: "default",
+		FailureThreshold:  3,
+		OpenDuration:      5 * time.Minute,
+		AlgorithmTimeout:  time.Minute,
+	}
+}
+
+// BindFlags binds the policy options to the command line flags.
+func (o *PolicyOptions) BindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.FallbackAlgorithm, "fallback-algorithm", o.FallbackAlgorithm,
+		"The built-in algorithm called when the scheduling algorithm fails or its circuit is open.")
+	fs.IntVar(&o.FailureThreshold, "algorithm-failure-threshold", o.FailureThreshold,
+		"The number of failures in a row of a scheduling algorithm to open its circuit.")
+	fs.DurationVar(&o.OpenDuration, "algorithm-open-duration", o.OpenDuration,
+		"How long the circuit of a scheduling algorithm stays open before the algorithm is tried again.")
+	fs.DurationVar(&o.AlgorithmTimeout, "algorithm-call-timeout", o.AlgorithmTimeout,
+		"The timeout of a call to a scheduling algorithm including its retries, 0 disables the timeout.")
+}
@@ -0,0 +1,36 @@
+package controllers
+
+import "testing"
+
+func TestRequiresApproval(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		namespaces string
+		selector   string
+		namespace  string
+		labels     map[string]string
+		expected   bool
+		err        bool
+	}{
+		{name: "not configured", namespace: "default", expected: false},
+		{name: "namespace listed", namespaces: "prod,staging", namespace: "staging", expected: true},
+		{name: "namespace listed with spaces", namespaces: "prod, staging ", namespace: "staging", expected: true},
+		{name: "namespace not listed", namespaces: "prod,staging", namespace: "default", expected: false},
+		{name: "selector matches", selector: "tier=critical", namespace: "default", labels: map[string]string{"tier": "critical"}, expected: true},
+		{name: "selector does not match", selector: "tier=critical", namespace: "default", labels: map[string]string{"tier": "batch"}, expected: false},
+		{name: "selector without labels", selector: "tier=critical", namespace: "default", expected: false},
+		{name: "selector or namespace", namespaces: "prod", selector: "tier=critical", namespace: "prod", labels: map[string]string{"tier": "batch"}, expected: true},
+		{name: "invalid selector", selector: "tier in (", namespace: "default", err: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := &SchedulingOptions{ApprovalNamespaces: tc.namespaces, ApprovalSelector: tc.selector}
+			required, err := opts.RequiresApproval(tc.namespace, tc.labels)
+			if (err != nil) != tc.err {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+			if required != tc.expected {
+				t.Errorf("expected approval required %v, got %v", tc.expected, required)
+			}
+		})
+	}
+}
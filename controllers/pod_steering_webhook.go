@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/retry"
+	melodyiov1alpha2 "melody/api/v1alpha2"
+	consts "melody/controllers/const"
+	util "melody/controllers/utils"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// PodSteeringPath is the path the PodSteering webhook is served at.
+const PodSteeringPath = "/mutate-v1-pod"
+
+//+kubebuilder:webhook:path=/mutate-v1-pod,mutating=true,failurePolicy=ignore,sideEffects=NoneOnDryRun,groups="",resources=pods,verbs=create,versions=v1,name=mpod.melody.io,admissionReviewVersions=v1
+
+// PodSteering steers the new serving pods of a running decision onto its preferred node. Each new serving pod claims
+// a slot of the plan of the decision, so only as many pods as the decision replaces and adds are required onto the
+// node, and the other serving pods are not changed. A pod which finds no slot is admitted unchanged. A dry-run request
+// is steered without claiming a slot.
+type PodSteering struct {
+	client.Client
+	// Reader reads the decisions from the API server, as the slots claimed by the pods created just before are not
+	// observed by the cache yet.
+	Reader  client.Reader
+	Log     logr.Logger
+	decoder *admission.Decoder
+}
+
+// NewPodSteering returns the PodSteering webhook of the manager.
+func NewPodSteering(mgr ctrl.Manager) *webhook.Admission {
+	return &webhook.Admission{Handler: &PodSteering{
+		Client: mgr.GetClient(),
+		Reader: mgr.GetAPIReader(),
+		Log:    ctrl.Log.WithName("webhooks").WithName("PodSteering"),
+	}}
+}
+
+// InjectDecoder injects the decoder of the admission requests.
+func (s *PodSteering) InjectDecoder(d *admission.Decoder) error {
+	s.decoder = d
+	return nil
+}
+
+// Handle steers the pod onto the preferred node of the running decision of its Inference, if the decision has a slot
+// left.
+func (s *PodSteering) Handle(ctx context.Context, req admission.Request) admission.Response {
+	pod := &corev1.Pod{}
+	if err := s.decoder.Decode(req, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	name := util.InferenceNameForPod(pod)
+	if name == "" {
+		return admission.Allowed("Pod is not a serving pod")
+	}
+
+	dryRun := req.DryRun != nil && *req.DryRun
+	var steering *melodyiov1alpha2.SchedulingDecesion
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		steering = nil
+		decesions := &melodyiov1alpha2.SchedulingDecesionList{}
+		if err := s.Reader.List(ctx, decesions, client.MatchingLabels{consts.LabelInferenceName: name}); err != nil {
+			return err
+		}
+		sd := getSteeringDecesion(decesions.Items, req.Namespace)
+		if sd == nil || dryRun {
+			steering = sd
+			return nil
+		}
+		sd.Status.SteeredPods++
+		if err := s.Status().Update(ctx, sd); err != nil {
+			return err
+		}
+		steering = sd
+		return nil
+	})
+	if err != nil {
+		s.Log.Error(err, "Claim steering slot error", "inference", name, "namespace", req.Namespace)
+		return admission.Allowed("Pod is not steered")
+	}
+	if steering == nil {
+		return admission.Allowed("No running decision steers the pod")
+	}
+
+	nodeName := steering.Status.Plan.PreferredNode
+	s.Log.Info("Steering serving pod", "inference", name, "namespace", req.Namespace, "node", nodeName, "decision", steering.GetName(), "dryRun", dryRun)
+	util.SetRequiredNode(pod, nodeName, steering.GetName())
+	marshaled, err := json.Marshal(pod)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// getSteeringDecesion returns the running decision applied earliest which has a slot left to steer a new pod in the
+// namespace, or nil if there is none.
+func getSteeringDecesion(decesions []melodyiov1alpha2.SchedulingDecesion, namespace string) *melodyiov1alpha2.SchedulingDecesion {
+	var steering []melodyiov1alpha2.SchedulingDecesion
+	for i := range decesions {
+		sd := &decesions[i]
+		plan := sd.Status.Plan
+		if plan == nil || plan.PreferredNode == "" || sd.Status.SteeredPods >= plan.SteerPods || util.GetTargetPodNamespace(sd) != namespace {
+			continue
+		}
+		steering = append(steering, *sd)
+	}
+	return util.GetActiveDecesion(steering)
+}
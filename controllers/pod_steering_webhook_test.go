@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-logr/logr"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	melodyiov1alpha2 "melody/api/v1alpha2"
+	consts "melody/controllers/const"
+	util "melody/controllers/utils"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestPodSteering(t *testing.T) {
+	sd := &melodyiov1alpha2.SchedulingDecesion{
+		ObjectMeta: metav1.ObjectMeta{Name: "move", Namespace: "default", Labels: map[string]string{consts.LabelInferenceName: "resnet"}},
+		Spec: melodyiov1alpha2.SchedulingDecesionSpec{Objective: melodyiov1alpha2.SchedulingObjective{
+			Type: melodyiov1alpha2.Transition,
+		}},
+	}
+	util.MarkSchedulingDecesionRunning(sd, "TransitionApplied", "")
+	sd.Status.Plan = &melodyiov1alpha2.SchedulingPlan{PreferredNode: "edge-1", SteerPods: 1}
+
+	c := newFakeClient(t, sd)
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	decoder, err := admission.NewDecoder(scheme)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &PodSteering{Client: c, Reader: c, Log: logr.Discard()}
+	if err := s.InjectDecoder(decoder); err != nil {
+		t.Fatal(err)
+	}
+
+	handle := func(labels map[string]string, dryRun bool) admission.Response {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{GenerateName: "resnet-", Labels: labels}}
+		raw, err := json.Marshal(pod)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s.Handle(context.TODO(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Namespace: "default",
+			Operation: admissionv1.Create,
+			DryRun:    &dryRun,
+			Object:    runtime.RawExtension{Raw: raw},
+		}})
+	}
+
+	if resp := handle(map[string]string{"app": "other"}, false); !resp.Allowed || len(resp.Patches) != 0 {
+		t.Errorf("other pod: allowed = %v, patches = %v, want allowed and unchanged", resp.Allowed, resp.Patches)
+	}
+	serving := map[string]string{consts.LabelInferenceName: "resnet"}
+	if resp := handle(serving, true); !resp.Allowed || len(resp.Patches) == 0 {
+		t.Errorf("dry-run pod: allowed = %v, patches = %v, want allowed and steered", resp.Allowed, resp.Patches)
+	}
+	if resp := handle(serving, false); !resp.Allowed || len(resp.Patches) == 0 {
+		t.Errorf("replacement pod: allowed = %v, patches = %v, want allowed and steered", resp.Allowed, resp.Patches)
+	}
+	if resp := handle(serving, false); !resp.Allowed || len(resp.Patches) != 0 {
+		t.Errorf("pod after the slots: allowed = %v, patches = %v, want allowed and unchanged", resp.Allowed, resp.Patches)
+	}
+
+	got := &melodyiov1alpha2.SchedulingDecesion{}
+	if err := c.Get(context.TODO(), client.ObjectKeyFromObject(sd), got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.SteeredPods != 1 {
+		t.Errorf("steered pods = %d, want 1", got.Status.SteeredPods)
+	}
+}
+
+func TestGetSteeringDecesion(t *testing.T) {
+	newDecesion := func(name, namespace string, steer, steered int32, running bool) melodyiov1alpha2.SchedulingDecesion {
+		sd := melodyiov1alpha2.SchedulingDecesion{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		sd.Status.Plan = &melodyiov1alpha2.SchedulingPlan{PreferredNode: "edge-1", SteerPods: steer}
+		sd.Status.SteeredPods = steered
+		if running {
+			util.MarkSchedulingDecesionRunning(&sd, "TransitionApplied", "")
+		}
+		return sd
+	}
+	tests := []struct {
+		name      string
+		decesions []melodyiov1alpha2.SchedulingDecesion
+		want      string
+	}{
+		{name: "no decision"},
+		{name: "slot left", decesions: []melodyiov1alpha2.SchedulingDecesion{newDecesion("a", "default", 2, 1, true)}, want: "a"},
+		{name: "slots claimed", decesions: []melodyiov1alpha2.SchedulingDecesion{newDecesion("a", "default", 1, 1, true)}},
+		{name: "not running", decesions: []melodyiov1alpha2.SchedulingDecesion{newDecesion("a", "default", 1, 0, false)}},
+		{name: "other namespace", decesions: []melodyiov1alpha2.SchedulingDecesion{newDecesion("a", "other", 1, 0, true)}},
+		{
+			name: "claimed skipped",
+			decesions: []melodyiov1alpha2.SchedulingDecesion{
+				newDecesion("a", "default", 1, 1, true), newDecesion("b", "default", 1, 0, true),
+			},
+			want: "b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := getSteeringDecesion(tt.decesions, "default")
+			if (got == nil && tt.want != "") || (got != nil && got.Name != tt.want) {
+				t.Errorf("getSteeringDecesion() = %v, want %q", got, tt.want)
+			}
+		})
+	}
+}
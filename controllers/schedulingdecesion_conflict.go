@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	melodyiov1alpha1 "melody/api/v1alpha1"
+	melodyiov1alpha2 "melody/api/v1alpha2"
+	consts "melody/controllers/const"
+	util "melody/controllers/utils"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// decesionReservations holds the decisions applied by this controller until they are completed. The decisions are
+// applied before the cache observes their Running status, so the reservations are merged with the running decisions
+// listed from the cache to lock their targets and node resources.
+type decesionReservations struct {
+	mu        sync.Mutex
+	decesions map[types.NamespacedName]*melodyiov1alpha2.SchedulingDecesion
+}
+
+func newDecesionReservations() *decesionReservations {
+	return &decesionReservations{decesions: make(map[types.NamespacedName]*melodyiov1alpha2.SchedulingDecesion)}
+}
+
+// reserve holds the target and node resources of the applied decision.
+func (d *decesionReservations) reserve(sd *melodyiov1alpha2.SchedulingDecesion) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.decesions[types.NamespacedName{Name: sd.Name, Namespace: sd.Namespace}] = sd.DeepCopy()
+}
+
+// release releases the reservation of the completed or deleted decision.
+func (d *decesionReservations) release(key types.NamespacedName) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.decesions, key)
+}
+
+// list returns the reserved decisions.
+func (d *decesionReservations) list() []*melodyiov1alpha2.SchedulingDecesion {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	decesions := make([]*melodyiov1alpha2.SchedulingDecesion, 0, len(d.decesions))
+	for _, sd := range d.decesions {
+		decesions = append(decesions, sd)
+	}
+	return decesions
+}
+
+// holdForConflict delays the decision in Pending while another decision targeting the same pod or Inference is
+// running, or while the pods moved onto the target node do not fit into the node together with the pending moves of
+// the running decisions. It returns true if the decision is held.
+func (r *SchedulingDecesionReconciler) holdForConflict(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion,
+	inference *melodyiov1alpha1.Inference, deploy *appsv1.Deployment, plan *decesionPlan) (bool, ctrl.Result, error) {
+	running, err := r.listRunningDecesions(ctx, instance)
+	if err != nil {
+		return false, ctrl.Result{}, err
+	}
+	for _, other := range running {
+		if other.Namespace == instance.Namespace && util.IsSameSchedulingTarget(instance, other) {
+			return true, r.delayDecesion(instance, "TargetLocked",
+				fmt.Sprintf("Target is locked by the running decision %s", other.GetName()), consts.DefaultSchedulingRequeueInterval), nil
+		}
+	}
+
+	nodeName := instance.Status.Plan.PreferredNode
+	if nodeName == "" {
+		return false, ctrl.Result{}, nil
+	}
+	requests := getIncomingRequests(instance, deploy)
+	instance.Status.Plan.NodeRequests = requests
+	if len(requests) == 0 {
+		return false, ctrl.Result{}, nil
+	}
+
+	node := &corev1.Node{}
+	if err := r.Get(ctx, types.NamespacedName{Name: nodeName}, node); err != nil {
+		return false, ctrl.Result{}, err
+	}
+	used, err := r.getNodeRequests(ctx, nodeName)
+	if err != nil {
+		return false, ctrl.Result{}, err
+	}
+	for _, other := range running {
+		if other.Status.Plan != nil && other.Status.Plan.PreferredNode == nodeName {
+			util.AddResourceList(used, other.Status.Plan.NodeRequests)
+		}
+	}
+	util.AddResourceList(used, requests)
+	for name := range requests {
+		allocatable, ok := node.Status.Allocatable[name]
+		if !ok {
+			continue
+		}
+		if total := used[name]; total.Cmp(allocatable) > 0 {
+			return true, r.delayDecesion(instance, "NodeOvercommitted",
+				fmt.Sprintf("Node %s can not fit %s requests of %s with the pending moves, %s of %s allocatable are requested",
+					nodeName, name, inference.GetName(), total.String(), allocatable.String()), consts.DefaultSchedulingRequeueInterval), nil
+		}
+	}
+	return false, ctrl.Result{}, nil
+}
+
+// listRunningDecesions returns the running decisions in the cluster other than the decision, including the decisions
+// reserved by this controller which are not observed as running by the cache yet.
+func (r *SchedulingDecesionReconciler) listRunningDecesions(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion) ([]*melodyiov1alpha2.SchedulingDecesion, error) {
+	decesions := &melodyiov1alpha2.SchedulingDecesionList{}
+	if err := r.List(ctx, decesions); err != nil {
+		return nil, err
+	}
+	listed := make(map[types.UID]*melodyiov1alpha2.SchedulingDecesion, len(decesions.Items))
+	var running []*melodyiov1alpha2.SchedulingDecesion
+	for i := range decesions.Items {
+		sd := &decesions.Items[i]
+		listed[sd.UID] = sd
+		if sd.UID != instance.UID && util.IsRunningSchedulingDecesion(sd) {
+			running = append(running, sd)
+		}
+	}
+	// A reserved decision is running until the cache observes it is running or completed
+	for _, sd := range r.reserved.list() {
+		if other, ok := listed[sd.UID]; sd.UID == instance.UID || (ok && (util.IsRunningSchedulingDecesion(other) || util.IsCompletedSchedulingDecesion(other))) {
+			continue
+		}
+		running = append(running, sd)
+	}
+	return running, nil
+}
+
+// getIncomingRequests returns the resource requests of the serving pods steered onto the node by the plan.
+func getIncomingRequests(instance *melodyiov1alpha2.SchedulingDecesion, deploy *appsv1.Deployment) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	podRequests := util.GetPodRequests(&deploy.Spec.Template.Spec)
+	for i := int32(0); i < instance.Status.Plan.SteerPods; i++ {
+		util.AddResourceList(requests, podRequests)
+	}
+	return requests
+}
+
+// getNodeRequests returns the total resource requests of the pods bound to the node which are not terminated.
+func (r *SchedulingDecesionReconciler) getNodeRequests(ctx context.Context, nodeName string) (corev1.ResourceList, error) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.MatchingFields{consts.PodNodeNameField: nodeName}); err != nil {
+		return nil, err
+	}
+	requests := corev1.ResourceList{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		util.AddResourceList(requests, util.GetPodRequests(&pod.Spec))
+	}
+	return requests, nil
+}
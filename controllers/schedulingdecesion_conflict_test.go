@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	melodyiov1alpha1 "melody/api/v1alpha1"
+	melodyiov1alpha2 "melody/api/v1alpha2"
+	util "melody/controllers/utils"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// newTargetDecesion returns a decision of the Inference moving the pod onto the node, running if started.
+func newTargetDecesion(name, inferenceName, podName, nodeName string, started bool) *melodyiov1alpha2.SchedulingDecesion {
+	sd := newTestDecesion(name, inferenceName, -1)
+	sd.Spec.Objective = melodyiov1alpha2.SchedulingObjective{
+		Type:       melodyiov1alpha2.Transition,
+		TargetPod:  melodyiov1alpha2.ObjectReference{Name: podName},
+		TargetNode: melodyiov1alpha2.ObjectReference{Name: nodeName},
+	}
+	sd.Status.Plan = &melodyiov1alpha2.SchedulingPlan{PreferredNode: nodeName, SteerPods: 1}
+	if started {
+		util.MarkSchedulingDecesionRunning(sd, "TransitionApplied", "")
+	}
+	return sd
+}
+
+func TestHoldForConflictLocksTarget(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		listed   []*melodyiov1alpha2.SchedulingDecesion
+		reserved []*melodyiov1alpha2.SchedulingDecesion
+		expected string
+	}{
+		{
+			name:     "no running decision",
+			listed:   []*melodyiov1alpha2.SchedulingDecesion{newTargetDecesion("a", "resnet", "resnet-0", "edge-2", false)},
+			expected: "",
+		},
+		{
+			name:     "running decision on the same pod",
+			listed:   []*melodyiov1alpha2.SchedulingDecesion{newTargetDecesion("a", "resnet", "resnet-0", "edge-2", true)},
+			expected: "TargetLocked",
+		},
+		{
+			name:     "running decision on another Inference",
+			listed:   []*melodyiov1alpha2.SchedulingDecesion{newTargetDecesion("a", "bert", "bert-0", "edge-2", true)},
+			expected: "",
+		},
+		{
+			name:     "reserved decision on the same pod not observed yet",
+			listed:   []*melodyiov1alpha2.SchedulingDecesion{newTargetDecesion("a", "resnet", "resnet-0", "edge-2", false)},
+			reserved: []*melodyiov1alpha2.SchedulingDecesion{newTargetDecesion("a", "resnet", "resnet-0", "edge-2", true)},
+			expected: "TargetLocked",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var objects []client.Object
+			for _, sd := range tc.listed {
+				objects = append(objects, sd)
+			}
+			r := &SchedulingDecesionReconciler{
+				Client:   newFakeClient(t, objects...),
+				Log:      logr.Discard(),
+				recorder: record.NewFakeRecorder(10),
+				reserved: newDecesionReservations(),
+			}
+			for _, sd := range tc.reserved {
+				r.reserved.reserve(sd)
+			}
+
+			instance := newTargetDecesion("pending", "resnet", "resnet-0", "edge-1", false)
+			instance.Status.Plan.PreferredNode = ""
+			held, result, err := r.holdForConflict(context.TODO(), instance, &melodyiov1alpha1.Inference{}, &appsv1.Deployment{}, &decesionPlan{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if held != (tc.expected != "") || instance.Status.Reason != tc.expected {
+				t.Fatalf("expected held with %q, got %v with %q", tc.expected, held, instance.Status.Reason)
+			}
+			if held && result.RequeueAfter <= 0 {
+				t.Errorf("expected the held decision to be retried, got %+v", result)
+			}
+		})
+	}
+}
+
+func TestHoldForConflictNodeOvercommit(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge-1"},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("4"), corev1.ResourceMemory: resource.MustParse("8Gi"),
+		}},
+	}
+	// The fake client ignores the field selectors, so only the pods on the target node are created
+	bound := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "bound", Namespace: "default"},
+		Spec: corev1.PodSpec{NodeName: node.Name, Containers: []corev1.Container{{
+			Name:      "serving",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2")}},
+		}}},
+	}
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "resnet", Namespace: "default"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:      "serving",
+			Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}},
+		}}}}},
+	}
+	withRequests := func(sd *melodyiov1alpha2.SchedulingDecesion, cpu string) *melodyiov1alpha2.SchedulingDecesion {
+		sd.Status.Plan.NodeRequests = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu)}
+		return sd
+	}
+
+	for _, tc := range []struct {
+		name     string
+		listed   []*melodyiov1alpha2.SchedulingDecesion
+		reserved []*melodyiov1alpha2.SchedulingDecesion
+		expected string
+	}{
+		{
+			name:     "fits",
+			expected: "",
+		},
+		{
+			name:     "fits with the pending move onto another node",
+			listed:   []*melodyiov1alpha2.SchedulingDecesion{withRequests(newTargetDecesion("a", "bert", "bert-0", "edge-2", true), "2")},
+			expected: "",
+		},
+		{
+			name:     "exceeds with the pending move of a running decision",
+			listed:   []*melodyiov1alpha2.SchedulingDecesion{withRequests(newTargetDecesion("a", "bert", "bert-0", "edge-1", true), "2")},
+			expected: "NodeOvercommitted",
+		},
+		{
+			name:     "exceeds with the pending move of a reserved decision not observed yet",
+			reserved: []*melodyiov1alpha2.SchedulingDecesion{withRequests(newTargetDecesion("a", "bert", "bert-0", "edge-1", true), "1500m")},
+			expected: "NodeOvercommitted",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			objects := []client.Object{node, bound}
+			for _, sd := range tc.listed {
+				objects = append(objects, sd)
+			}
+			r := &SchedulingDecesionReconciler{
+				Client:   newFakeClient(t, objects...),
+				Log:      logr.Discard(),
+				recorder: record.NewFakeRecorder(10),
+				reserved: newDecesionReservations(),
+			}
+			for _, sd := range tc.reserved {
+				r.reserved.reserve(sd)
+			}
+
+			instance := newTargetDecesion("pending", "resnet", "resnet-0", node.Name, false)
+			inference := &melodyiov1alpha1.Inference{ObjectMeta: metav1.ObjectMeta{Name: "resnet", Namespace: "default"}}
+			held, _, err := r.holdForConflict(context.TODO(), instance, inference, deploy, &decesionPlan{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if held != (tc.expected != "") || instance.Status.Reason != tc.expected {
+				t.Fatalf("expected held with %q, got %v with %q", tc.expected, held, instance.Status.Reason)
+			}
+			if got := instance.Status.Plan.NodeRequests[corev1.ResourceCPU]; got.Cmp(resource.MustParse("1")) != 0 {
+				t.Errorf("expected 1 cpu reserved on the node, got %s", got.String())
+			}
+		})
+	}
+}
+
+func TestReconcileReleasesReservation(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		mark func(sd *melodyiov1alpha2.SchedulingDecesion)
+	}{
+		{name: "succeeded", mark: func(sd *melodyiov1alpha2.SchedulingDecesion) {
+			util.MarkSchedulingDecesionSucceeded(sd, "TransitionSucceeded", "")
+		}},
+		{name: "failed", mark: func(sd *melodyiov1alpha2.SchedulingDecesion) {
+			util.MarkSchedulingDecesionFailed(sd, "Timeout", "")
+		}},
+		{name: "rejected", mark: func(sd *melodyiov1alpha2.SchedulingDecesion) {
+			util.MarkSchedulingDecesionRejected(sd, "Superseded", "")
+		}},
+		{name: "deleted"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reserved := newTargetDecesion("a", "resnet", "resnet-0", "edge-1", true)
+			var objects []client.Object
+			if tc.mark != nil {
+				completed := reserved.DeepCopy()
+				tc.mark(completed)
+				objects = append(objects, completed)
+			}
+			r := &SchedulingDecesionReconciler{
+				Client:   newFakeClient(t, objects...),
+				Log:      logr.Discard(),
+				Options:  &SchedulingOptions{},
+				recorder: record.NewFakeRecorder(10),
+				reserved: newDecesionReservations(),
+			}
+			r.reserved.reserve(reserved)
+			r.reserved.reserve(newTargetDecesion("b", "bert", "bert-0", "edge-1", true))
+
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: reserved.Name, Namespace: reserved.Namespace}}
+			if _, err := r.Reconcile(context.TODO(), req); err != nil {
+				t.Fatal(err)
+			}
+			held := r.reserved.list()
+			if len(held) != 1 || held[0].Name != "b" {
+				t.Errorf("expected only the reservation of b to be held, got %d reservations", len(held))
+			}
+		})
+	}
+}
@@ -18,45 +18,146 @@ package controllers
 
 import (
 	"context"
+	"reflect"
 
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	//"sigs.k8s.io/controller-runtime/pkg/log"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 
-	melodyiov1alpha1 "melody/api/v1alpha1"
+	melodyiov1alpha2 "melody/api/v1alpha2"
+	consts "melody/controllers/const"
+	util "melody/controllers/utils"
+	"melody/pkg/monitor"
+	"melody/pkg/replay"
 )
 
+const (
+	SchedulingDecesionControllerName = "schedulingdecesion-controller"
+)
+
+// NewSchedulingDecesionReconciler returns a new reconciler
+func NewSchedulingDecesionReconciler(mgr manager.Manager, opts *SchedulingOptions) *SchedulingDecesionReconciler {
+	r := &SchedulingDecesionReconciler{
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Options:  opts,
+		reserved: newDecesionReservations(),
+		pods:     kubernetes.NewForConfigOrDie(mgr.GetConfig()).CoreV1(),
+		recorder: mgr.GetEventRecorderFor(SchedulingDecesionControllerName),
+		Log:      logf.Log.WithName(SchedulingDecesionControllerName),
+	}
+
+	return r
+}
+
 // SchedulingDecesionReconciler reconciles a SchedulingDecesion object
 type SchedulingDecesionReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Log     logr.Logger
+	Scheme  *runtime.Scheme
+	Options *SchedulingOptions
+	Latency LatencyObserver
+	// Monitor observes the used resources of the nodes and the pods, the requested resources are used if not set.
+	Monitor monitor.Collector
+	Replay  *replay.Recorder
+	// Feedback reports the feedback of the decisions to the algorithm server, the feedback is not reported if not set.
+	Feedback FeedbackReporter
+	reserved *decesionReservations
+	// pods evicts the pods through the eviction subresource, which the controller-runtime client does not support.
+	pods     corev1client.PodsGetter
+	recorder record.EventRecorder
 }
 
 //+kubebuilder:rbac:groups=melody.io.melody.io,resources=schedulingdecesions,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=melody.io.melody.io,resources=schedulingdecesions/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=melody.io.melody.io,resources=schedulingdecesions/finalizers,verbs=update
+//+kubebuilder:rbac:groups=melody.io.melody.io,resources=inferences,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=apps,resources=replicasets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups=policy,resources=poddisruptionbudgets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
-// Reconcile is part of the main kubernetes reconciliation loop which aims to
-// move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the SchedulingDecesion object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
-//
-// For more details, check Reconcile and its Result here:
-// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.10.0/pkg/reconcile
+// Reconcile executes the SchedulingDecesion against the serving deployment of the target Inference,
+// and tracks the execution until the decision is completed.
 func (r *SchedulingDecesionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	//_ = log.FromContext(ctx)
+	logger := r.Log.WithValues("SchedulingDecesion", req.NamespacedName)
+
+	// 1) Fetch the scheduling decesion instance
+	original := &melodyiov1alpha2.SchedulingDecesion{}
+	err := r.Get(ctx, req.NamespacedName, original)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			logger.Info("try to get scheduling decesion, but it has been deleted", "key", req.String())
+			r.reserved.release(req.NamespacedName)
+			r.discardTransition(req.NamespacedName)
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "SchedulingDecesion instance get error")
+		return ctrl.Result{}, err
+	}
+
+	// 2) Completed decisions are never executed again, only the history of the Inference and the replay are cleaned
+	// up, and the feedback of the decision is recorded
+	if util.IsCompletedSchedulingDecesion(original) {
+		r.reserved.release(req.NamespacedName)
+		if !util.IsSucceededSchedulingDecesion(original) {
+			r.discardTransition(req.NamespacedName)
+		}
+		if err := r.cleanupDecesionHistory(ctx, original); err != nil {
+			logger.Error(err, "Cleanup scheduling decesion history error")
+			return ctrl.Result{}, err
+		}
+		r.sweepTransitions(ctx)
+		return r.reconcileFeedback(ctx, original)
+	}
+
+	// 3) Execute the decision, and track its progress
+	instance := original.DeepCopy()
+	result, err := r.reconcileDecesion(ctx, instance)
+	if err != nil {
+		logger.Error(err, "Reconcile scheduling decesion error")
+		return ctrl.Result{}, err
+	}
 
-	// TODO(user): your logic here
+	// 4) Compare status before-and-after reconciling and update changes to cluster.
+	if !reflect.DeepEqual(original.Status, instance.Status) {
+		if err = r.Status().Update(ctx, instance); err != nil {
+			if errors.IsConflict(err) {
+				// retry later when update operation violates with etcd concurrency control.
+				return ctrl.Result{Requeue: true}, nil
+			}
+			return ctrl.Result{}, err
+		}
+	}
 
-	return ctrl.Result{}, nil
+	return result, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *SchedulingDecesionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Index the pods by node, so that the resource requests on a target node can be listed from the cache
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &corev1.Pod{}, consts.PodNodeNameField, func(obj client.Object) []string {
+		nodeName := obj.(*corev1.Pod).Spec.NodeName
+		if nodeName == "" {
+			return nil
+		}
+		return []string{nodeName}
+	}); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&melodyiov1alpha1.SchedulingDecesion{}).
+		Named(SchedulingDecesionControllerName).
+		For(&melodyiov1alpha2.SchedulingDecesion{}).
 		Complete(r)
 }
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	melodyiov1alpha1 "melody/api/v1alpha1"
+	melodyiov1alpha2 "melody/api/v1alpha2"
+	consts "melody/controllers/const"
+	util "melody/controllers/utils"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// holdForDisruption checks the serving pods the plan of the decision disrupts against the minimum available of the
+// Inference and the PodDisruptionBudgets of the serving pods. A decision which scales the Inference below its minimum
+// available is rejected, as it never fits. Otherwise the decision is delayed in Pending until enough serving pods are
+// ready, and is rejected by the TTL if it never fits. It returns true if the decision is held.
+func (r *SchedulingDecesionReconciler) holdForDisruption(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion,
+	inference *melodyiov1alpha1.Inference, deploy *appsv1.Deployment, plan *decesionPlan) (bool, ctrl.Result, error) {
+	minAvailable := util.GetInferenceMinAvailable(inference, int32(r.Options.MinAvailable))
+	if plan.replicas < minAvailable {
+		r.markRejected(instance, "MinAvailableViolated",
+			fmt.Sprintf("Decision scales inference %s to %d replicas, below its minimum available %d", inference.GetName(), plan.replicas, minAvailable))
+		return true, ctrl.Result{}, nil
+	}
+
+	reason, message, err := r.checkDisruption(ctx, inference, disruptedReplicas(instance, plan))
+	if err != nil || reason == "" {
+		return false, ctrl.Result{}, err
+	}
+	return true, r.delayDecesion(instance, reason, message, consts.DefaultSchedulingRequeueInterval), nil
+}
+
+// checkDisruption checks if the serving pods of the Inference can afford disrupting the number of pods. It returns the
+// reason and message of the violation, or an empty reason if the disruption is allowed.
+func (r *SchedulingDecesionReconciler) checkDisruption(ctx context.Context, inference *melodyiov1alpha1.Inference, disrupted int32) (string, string, error) {
+	if disrupted <= 0 {
+		return "", "", nil
+	}
+
+	minAvailable := util.GetInferenceMinAvailable(inference, int32(r.Options.MinAvailable))
+	ready, err := r.countReadyPodsOnNode(ctx, inference, "")
+	if err != nil {
+		return "", "", err
+	}
+	if ready-disrupted < minAvailable {
+		return "MinAvailableNotMet", fmt.Sprintf("Disrupting %d of %d ready serving pod(s) breaks the minimum available %d",
+			disrupted, ready, minAvailable), nil
+	}
+
+	pdbs := &policyv1.PodDisruptionBudgetList{}
+	if err := r.List(ctx, pdbs, client.InNamespace(inference.GetNamespace())); err != nil {
+		return "", "", err
+	}
+	podLabels := labels.Set(util.ServicePodLabels(inference))
+	for i := range pdbs.Items {
+		pdb := &pdbs.Items[i]
+		if pdb.Spec.Selector == nil {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || !selector.Matches(podLabels) {
+			continue
+		}
+		if pdb.Status.DisruptionsAllowed < disrupted {
+			return "DisruptionBudgetExceeded", fmt.Sprintf("PodDisruptionBudget %s allows %d disruption(s), but %d serving pod(s) are disrupted",
+				pdb.GetName(), pdb.Status.DisruptionsAllowed, disrupted), nil
+		}
+	}
+	return "", "", nil
+}
+
+// disruptedReplicas returns the number of serving pods disrupted by applying the plan, that is the replicas scaled down
+// and the target pod evicted by a transition. The old pod retired by a TransitionScaling is checked once the new
+// replicas are ready.
+func disruptedReplicas(instance *melodyiov1alpha2.SchedulingDecesion, plan *decesionPlan) int32 {
+	var disrupted int32
+	if plan.replicas < plan.previous {
+		disrupted = plan.previous - plan.replicas
+	}
+	if instance.Spec.Objective.Type == melodyiov1alpha2.Transition {
+		disrupted++
+	}
+	return disrupted
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	melodyiov1alpha1 "melody/api/v1alpha1"
+	melodyiov1alpha2 "melody/api/v1alpha2"
+	util "melody/controllers/utils"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestDisruptedReplicas(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		objective  melodyiov1alpha2.SchedulingType
+		previous   int32
+		replicas   int32
+		disruption int32
+	}{
+		{name: "transition", objective: melodyiov1alpha2.Transition, previous: 3, replicas: 3, disruption: 1},
+		{name: "scaling up", objective: melodyiov1alpha2.Scaling, previous: 2, replicas: 4, disruption: 0},
+		{name: "scaling down", objective: melodyiov1alpha2.Scaling, previous: 4, replicas: 1, disruption: 3},
+		{name: "scaling unchanged", objective: melodyiov1alpha2.Scaling, previous: 2, replicas: 2, disruption: 0},
+		// The old pod is retired by a TransitionScaling once the new replicas are ready, so it is not disrupted up front
+		{name: "transition scaling up", objective: melodyiov1alpha2.TransitionScaling, previous: 2, replicas: 3, disruption: 0},
+		{name: "transition scaling down", objective: melodyiov1alpha2.TransitionScaling, previous: 3, replicas: 2, disruption: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			instance := &melodyiov1alpha2.SchedulingDecesion{}
+			instance.Spec.Objective.Type = tc.objective
+			plan := &decesionPlan{previous: tc.previous, replicas: tc.replicas}
+			if disrupted := disruptedReplicas(instance, plan); disrupted != tc.disruption {
+				t.Errorf("expected %d disrupted replicas, got %d", tc.disruption, disrupted)
+			}
+		})
+	}
+}
+
+func TestCheckDisruption(t *testing.T) {
+	inference := &melodyiov1alpha1.Inference{
+		ObjectMeta: metav1.ObjectMeta{Name: "resnet", Namespace: "default"},
+		Spec:       melodyiov1alpha1.InferenceSpec{MinAvailable: pointer.Int32(2)},
+	}
+	newPDB := func(name string, labels map[string]string, allowed int32) *policyv1.PodDisruptionBudget {
+		return &policyv1.PodDisruptionBudget{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       policyv1.PodDisruptionBudgetSpec{Selector: &metav1.LabelSelector{MatchLabels: labels}},
+			Status:     policyv1.PodDisruptionBudgetStatus{DisruptionsAllowed: allowed},
+		}
+	}
+
+	for _, tc := range []struct {
+		name      string
+		ready     int
+		disrupted int32
+		pdbs      []client.Object
+		expected  string
+	}{
+		{name: "nothing disrupted", ready: 1, disrupted: 0, expected: ""},
+		{name: "min available kept", ready: 3, disrupted: 1, expected: ""},
+		{name: "min available broken", ready: 3, disrupted: 2, expected: "MinAvailableNotMet"},
+		{
+			name: "budget allows", ready: 3, disrupted: 1,
+			pdbs:     []client.Object{newPDB("serving", util.ServicePodLabels(inference), 1)},
+			expected: "",
+		},
+		{
+			name: "budget exceeded", ready: 3, disrupted: 1,
+			pdbs:     []client.Object{newPDB("serving", util.ServicePodLabels(inference), 0)},
+			expected: "DisruptionBudgetExceeded",
+		},
+		{
+			name: "budget of other pods", ready: 3, disrupted: 1,
+			pdbs:     []client.Object{newPDB("other", map[string]string{"app": "other"}, 0)},
+			expected: "",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			objects := append([]client.Object{}, tc.pdbs...)
+			for i := 0; i < tc.ready; i++ {
+				objects = append(objects, &corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("resnet-%d", i), Namespace: "default", Labels: util.ServicePodLabels(inference)},
+					Status: corev1.PodStatus{
+						Phase:      corev1.PodRunning,
+						Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+					},
+				})
+			}
+			r := &SchedulingDecesionReconciler{
+				Client:  newFakeClient(t, objects...),
+				Options: &SchedulingOptions{MinAvailable: 1},
+			}
+			reason, message, err := r.checkDisruption(context.TODO(), inference, tc.disrupted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reason != tc.expected {
+				t.Errorf("expected reason %q, got %q: %s", tc.expected, reason, message)
+			}
+		})
+	}
+}
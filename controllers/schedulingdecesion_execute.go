@@ -0,0 +1,586 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	melodyiov1alpha1 "melody/api/v1alpha1"
+	melodyiov1alpha2 "melody/api/v1alpha2"
+	consts "melody/controllers/const"
+	util "melody/controllers/utils"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// reasonApplying is the reason of a running decision whose plan is persisted but not applied yet.
+const reasonApplying = "Applying"
+
+// decesionPlan is what a decision changes in the cluster.
+type decesionPlan struct {
+	// desired is the serving deployment once the decision is applied.
+	desired *appsv1.Deployment
+	// previous and replicas are the replicas of the Inference before and after the decision is applied.
+	previous int32
+	replicas int32
+	// noopReason and noopMessage explain why the decision changes nothing, empty if the decision changes the cluster.
+	noopReason  string
+	noopMessage string
+}
+
+// reconcileDecesion resolves the target Inference of the decision. A decision which is not applied yet is checked,
+// planned and then applied, or only evaluated in dry-run mode. A decision which is applied is tracked to completion.
+func (r *SchedulingDecesionReconciler) reconcileDecesion(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion) (ctrl.Result, error) {
+	inference, deploy, err := r.getTargetInference(ctx, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			r.markFailed(instance, "TargetNotFound", err.Error())
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if util.IsRunningSchedulingDecesion(instance) {
+		return r.trackDecesion(ctx, instance, inference, deploy)
+	}
+
+	// Decisions which require manual approval are only rejected by the approval timeout until they are approved, then
+	// by the TTL counted from the approval
+	approval, err := r.Options.RequiresApproval(inference.GetNamespace(), inference.GetLabels())
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if approval && util.IsApprovedSchedulingDecesion(instance) && instance.Status.ApprovedTime == nil {
+		now := metav1.Now()
+		instance.Status.ApprovedTime = &now
+	}
+	checkTTL := !approval || instance.Status.ApprovedTime != nil
+	if rejected, err := r.rejectStaleDecesion(ctx, instance, checkTTL); rejected || err != nil {
+		return ctrl.Result{}, err
+	}
+	plan, ok, err := r.planDecesion(ctx, instance, inference, deploy)
+	if !ok || err != nil {
+		return ctrl.Result{}, err
+	}
+	if r.isDryRun(instance) {
+		r.markEvaluated(instance, "DryRun", util.DescribeSchedulingPlan(instance))
+		return ctrl.Result{}, nil
+	}
+	if plan.noopReason != "" {
+		r.markSucceeded(instance, plan.noopReason, plan.noopMessage)
+		return ctrl.Result{}, nil
+	}
+	if approval {
+		if held, result := r.holdForApproval(instance); held {
+			return result, nil
+		}
+	}
+	if held, result, err := r.holdForRateLimit(ctx, instance); held || err != nil {
+		return result, err
+	}
+	if held, result, err := r.holdForDisruption(ctx, instance, inference, deploy, plan); held || err != nil {
+		return result, err
+	}
+	if held, result, err := r.holdForConflict(ctx, instance, inference, deploy, plan); held || err != nil {
+		return result, err
+	}
+	return r.applyDecesion(ctx, instance, inference, deploy, plan)
+}
+
+// isDryRun returns true if the decision is only evaluated, either requested by the decision or for all decisions.
+func (r *SchedulingDecesionReconciler) isDryRun(instance *melodyiov1alpha2.SchedulingDecesion) bool {
+	return instance.Spec.DryRun || r.Options.DryRun
+}
+
+// planDecesion computes what the decision changes without applying it, and records the plan in the status. It returns
+// false if the decision can not be applied, and the decision is marked as failed.
+func (r *SchedulingDecesionReconciler) planDecesion(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion,
+	inference *melodyiov1alpha1.Inference, deploy *appsv1.Deployment) (*decesionPlan, bool, error) {
+	objective := &instance.Spec.Objective
+	plan := &decesionPlan{
+		desired:  deploy.DeepCopy(),
+		previous: util.GetInferenceReplicas(inference),
+	}
+	plan.replicas = plan.previous
+	preferredNode := ""
+	var steerPods int32
+
+	switch objective.Type {
+	case melodyiov1alpha2.Transition, melodyiov1alpha2.TransitionScaling:
+		if ok, err := r.checkTargetNode(ctx, instance); !ok || err != nil {
+			return nil, false, err
+		}
+		preferredNode = objective.TargetNode.Name
+		steerPods = 1
+	case melodyiov1alpha2.Scaling:
+	default:
+		r.markFailed(instance, "UnsupportedType", fmt.Sprintf("Scheduling type %q is not supported", objective.Type))
+		return nil, false, nil
+	}
+	if objective.Type != melodyiov1alpha2.Transition {
+		plan.replicas = util.BoundInferenceReplicas(inference, objective.ScalingReplica)
+		plan.desired.Spec.Replicas = &plan.replicas
+	}
+
+	switch objective.Type {
+	case melodyiov1alpha2.Transition:
+		pod, err := r.getTargetPod(ctx, instance)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return nil, false, err
+			}
+			r.markFailed(instance, "TargetPodNotFound", fmt.Sprintf("Pod %s is not found", r.targetPodKey(instance)))
+			return nil, false, nil
+		}
+		if pod.Spec.NodeName == preferredNode {
+			plan.noopReason = "AlreadyOnTargetNode"
+			plan.noopMessage = fmt.Sprintf("Pod %s is already running on node %s", pod.Name, preferredNode)
+		}
+	case melodyiov1alpha2.TransitionScaling:
+		if delta := plan.replicas - plan.previous; delta > 0 {
+			steerPods += delta
+		}
+	case melodyiov1alpha2.Scaling:
+		if plan.replicas == plan.previous {
+			plan.noopReason = "ScalingUnchanged"
+			plan.noopMessage = fmt.Sprintf("Inference %s already has %d replicas", inference.GetName(), plan.replicas)
+		}
+	}
+
+	patch, err := client.MergeFrom(deploy).Data(plan.desired)
+	if err != nil {
+		return nil, false, err
+	}
+	instance.Status.PreviousReplicas = &plan.previous
+	instance.Status.NewReplicas = &plan.replicas
+	instance.Status.Plan = &melodyiov1alpha2.SchedulingPlan{
+		Deployment:      deploy.GetName(),
+		DeploymentPatch: string(patch),
+		PreferredNode:   preferredNode,
+		SteerPods:       steerPods,
+		ReplicaDelta:    plan.replicas - plan.previous,
+	}
+	if plan.noopReason != "" || string(patch) == "{}" {
+		instance.Status.Plan.DeploymentPatch = ""
+	}
+	if plan.noopReason != "" {
+		instance.Status.Plan.SteerPods = 0
+	}
+	return plan, true, nil
+}
+
+// applyDecesion starts the decision. The plan and the state before the decision are persisted in the status first, so
+// that a retry never plans the decision again from a cluster which is already changed, and the rollback restores the
+// state before the decision. The plan is then executed.
+func (r *SchedulingDecesionReconciler) applyDecesion(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion,
+	inference *melodyiov1alpha1.Inference, deploy *appsv1.Deployment, plan *decesionPlan) (ctrl.Result, error) {
+	logger := r.Log.WithValues("SchedulingDecesion", types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()})
+	objective := &instance.Spec.Objective
+
+	logger.Info("Applying scheduling decesion", "type", objective.Type, "deployment", deploy.GetName(), "patch", instance.Status.Plan.DeploymentPatch)
+	instance.Status.PreviousRevision = deploy.Annotations[consts.DeploymentRevisionAnnotation]
+	r.recordStateBefore(ctx, instance)
+	util.MarkSchedulingDecesionRunning(instance, reasonApplying,
+		fmt.Sprintf("Inference %s is changed from %d replicas on revision %q", inference.GetName(), plan.previous, instance.Status.PreviousRevision))
+	if err := r.Status().Update(ctx, instance); err != nil {
+		if errors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		logger.Error(err, "Persist scheduling plan error")
+		return ctrl.Result{}, err
+	}
+	r.beginTransition(ctx, instance)
+	r.reserved.reserve(instance)
+	return r.executeDecesion(ctx, instance, inference, deploy)
+}
+
+// executeDecesion executes the plan persisted in the status. The replicas of the Inference and the serving deployment
+// are updated, and the target pod of a Transition is evicted, which is retried while a disruption budget refuses it. The pod template is not changed, the pod replacing the
+// target pod and the pods added by the decision are steered onto the target node by the PodSteering webhook, so the
+// other serving pods are not rolled. Only what differs from the plan is changed, so it is retried until the decision
+// is marked as applied.
+func (r *SchedulingDecesionReconciler) executeDecesion(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion,
+	inference *melodyiov1alpha1.Inference, deploy *appsv1.Deployment) (ctrl.Result, error) {
+	logger := r.Log.WithValues("SchedulingDecesion", types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()})
+	objective := &instance.Spec.Objective
+
+	desired := deploy.DeepCopy()
+	replicas := util.GetInferenceReplicas(inference)
+	if objective.Type != melodyiov1alpha2.Transition && instance.Status.NewReplicas != nil {
+		replicas = *instance.Status.NewReplicas
+		desired.Spec.Replicas = &replicas
+	}
+	if replicas != util.GetInferenceReplicas(inference) {
+		inference.Spec.Replicas = &replicas
+		if err := r.Update(ctx, inference); err != nil {
+			logger.Error(err, "Scale inference error", "name", inference.GetName())
+			return ctrl.Result{}, err
+		}
+	}
+	patch := client.MergeFrom(deploy)
+	if data, err := patch.Data(desired); err != nil {
+		return ctrl.Result{}, err
+	} else if string(data) != "{}" {
+		if err := r.Patch(ctx, desired, patch); err != nil {
+			logger.Error(err, "Patch serving deployment error", "name", deploy.GetName())
+			return ctrl.Result{}, err
+		}
+	}
+	if objective.Type == melodyiov1alpha2.Transition {
+		pod, err := r.getTargetPod(ctx, instance)
+		if err == nil && pod.DeletionTimestamp == nil {
+			logger.Info("Evicting target pod", "pod", pod.GetName(), "node", pod.Spec.NodeName)
+			evicted, err := r.evictPod(ctx, pod)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if !evicted {
+				msg := fmt.Sprintf("Eviction of pod %s is refused by a disruption budget", pod.GetName())
+				if util.IsSchedulingDecesionTimeout(instance) {
+					r.markFailed(instance, "Timeout", fmt.Sprintf("%s for %v", msg, util.GetSchedulingDecesionDeadline(instance)))
+					return ctrl.Result{}, nil
+				}
+				if instance.Status.Message != msg {
+					util.MarkSchedulingDecesionRunning(instance, reasonApplying, msg)
+					r.recorder.Event(instance, corev1.EventTypeWarning, "EvictionDelayed", msg)
+				}
+				return ctrl.Result{RequeueAfter: consts.DefaultSchedulingRequeueInterval}, nil
+			}
+		} else if err != nil && !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+	}
+
+	var previous int32
+	if instance.Status.PreviousReplicas != nil {
+		previous = *instance.Status.PreviousReplicas
+	}
+	var msg string
+	switch objective.Type {
+	case melodyiov1alpha2.Transition:
+		msg = fmt.Sprintf("Pod %s is evicted and its replacement is moving onto node %s", objective.TargetPod.Name, objective.TargetNode.Name)
+	case melodyiov1alpha2.Scaling:
+		msg = fmt.Sprintf("Inference %s is scaling from %d to %d replicas", inference.GetName(), previous, replicas)
+		if replicas != objective.ScalingReplica {
+			msg = fmt.Sprintf("%s, bounded from %d replicas", msg, objective.ScalingReplica)
+		}
+	case melodyiov1alpha2.TransitionScaling:
+		msg = fmt.Sprintf("Deployment %s is scaling from %d to %d replicas onto node %s", deploy.GetName(), previous, replicas, objective.TargetNode.Name)
+	}
+	reason := string(objective.Type) + "Applied"
+	util.MarkSchedulingDecesionRunning(instance, reason, msg)
+	r.recorder.Event(instance, corev1.EventTypeNormal, reason, msg)
+	return ctrl.Result{RequeueAfter: consts.DefaultSchedulingRequeueInterval}, nil
+}
+
+// trackDecesion tracks the rollout of the serving deployment until the decision is completed. A Transition completes
+// once the pod replacing the target pod is ready on the target node. A TransitionScaling also retires the old pod once
+// the new replicas are ready on the target node, and is rolled back if it misses the deadline.
+func (r *SchedulingDecesionReconciler) trackDecesion(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion,
+	inference *melodyiov1alpha1.Inference, deploy *appsv1.Deployment) (ctrl.Result, error) {
+	logger := r.Log.WithValues("SchedulingDecesion", types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()})
+	objective := &instance.Spec.Objective
+	nodeName := objective.TargetNode.Name
+
+	// 0) Finish applying the plan if the decision is interrupted after the plan is persisted
+	if instance.Status.Reason == reasonApplying {
+		return r.executeDecesion(ctx, instance, inference, deploy)
+	}
+	if objective.Type == melodyiov1alpha2.Transition {
+		return r.trackTransition(ctx, instance, inference)
+	}
+
+	// 1) Wait for the serving deployment to be rolled out
+	scaled := instance.Status.NewReplicas == nil || (deploy.Spec.Replicas != nil && *deploy.Spec.Replicas == *instance.Status.NewReplicas)
+	if !scaled || !util.IsDeploymentRolledOut(deploy) {
+		if util.IsSchedulingDecesionTimeout(instance) {
+			msg := fmt.Sprintf("Deployment %s is not rolled out in %v", deploy.GetName(), util.GetSchedulingDecesionDeadline(instance))
+			if objective.Type == melodyiov1alpha2.TransitionScaling {
+				return r.rollbackDecesion(ctx, instance, inference, deploy, msg)
+			}
+			r.markFailed(instance, "Timeout", msg)
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{RequeueAfter: consts.DefaultSchedulingRequeueInterval}, nil
+	}
+	if objective.Type == melodyiov1alpha2.Scaling {
+		r.markSucceeded(instance, "ScalingSucceeded", fmt.Sprintf("Deployment %s is scaled to %d replicas", deploy.GetName(), *instance.Status.NewReplicas))
+		return ctrl.Result{}, nil
+	}
+
+	// 2) Check the serving pods on the target node
+	ready, err := r.countReadyPodsOnNode(ctx, inference, nodeName)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if ready == 0 {
+		return r.rollbackDecesion(ctx, instance, inference, deploy, fmt.Sprintf("No serving pod is ready on node %s after rollout", nodeName))
+	}
+
+	// 3) Retire the old pod if it survived the rollout, unless it breaks the disruption budgets
+	msg := fmt.Sprintf("%d serving pod(s) are ready on node %s, deployment %s has %d replicas", ready, nodeName, deploy.GetName(), *instance.Status.NewReplicas)
+	pod, err := r.getTargetPod(ctx, instance)
+	if err == nil && pod.Spec.NodeName != nodeName && pod.DeletionTimestamp == nil {
+		reason, violation, err := r.checkDisruption(ctx, inference, 1)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if reason != "" {
+			logger.Info("Retaining old serving pod", "pod", pod.GetName(), "reason", reason, "violation", violation)
+			msg = fmt.Sprintf("%s, old pod %s is retained: %s", msg, pod.GetName(), violation)
+		} else {
+			logger.Info("Retiring old serving pod", "pod", pod.GetName(), "node", pod.Spec.NodeName)
+			evicted, err := r.evictPod(ctx, pod)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if !evicted {
+				violation := fmt.Sprintf("eviction is refused by a disruption budget for %v", util.GetSchedulingDecesionDeadline(instance))
+				if !util.IsSchedulingDecesionTimeout(instance) {
+					logger.Info("Delaying retirement of old serving pod", "pod", pod.GetName())
+					return ctrl.Result{RequeueAfter: consts.DefaultSchedulingRequeueInterval}, nil
+				}
+				msg = fmt.Sprintf("%s, old pod %s is retained: %s", msg, pod.GetName(), violation)
+			}
+		}
+	} else if err != nil && !errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	r.markSucceeded(instance, "TransitionScalingSucceeded", msg)
+	return ctrl.Result{}, nil
+}
+
+// trackTransition tracks the pod replacing the target pod of a Transition. The decision succeeds once the replacement
+// is ready on the target node, and fails if the replacement is placed onto another node or misses the deadline.
+func (r *SchedulingDecesionReconciler) trackTransition(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion,
+	inference *melodyiov1alpha1.Inference) (ctrl.Result, error) {
+	nodeName := instance.Spec.Objective.TargetNode.Name
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(inference.Namespace), client.MatchingLabels(util.ServicePodLabels(inference))); err != nil {
+		return ctrl.Result{}, err
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Annotations[consts.AnnotationSchedulingDecesion] != instance.GetName() || pod.Spec.NodeName == "" {
+			continue
+		}
+		if pod.Spec.NodeName != nodeName {
+			r.markFailed(instance, "TargetNodeNotSelected", fmt.Sprintf("Pod %s is placed onto node %s instead of node %s", pod.GetName(), pod.Spec.NodeName, nodeName))
+			return ctrl.Result{}, nil
+		}
+		if util.IsPodReady(pod) {
+			r.markSucceeded(instance, "TransitionSucceeded", fmt.Sprintf("Pod %s is ready on node %s", pod.GetName(), nodeName))
+			return ctrl.Result{}, nil
+		}
+	}
+	if util.IsSchedulingDecesionTimeout(instance) {
+		if err := r.releaseSteeredPods(ctx, instance, inference); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.markFailed(instance, "Timeout", fmt.Sprintf("No serving pod is ready on node %s in %v", nodeName, util.GetSchedulingDecesionDeadline(instance)))
+		return ctrl.Result{}, nil
+	}
+	return ctrl.Result{RequeueAfter: consts.DefaultSchedulingRequeueInterval}, nil
+}
+
+// releaseSteeredPods deletes the pods steered by the decision which are not scheduled yet, as they are required onto
+// the target node and may never fit. Their ReplicaSet recreates them, and the new pods are not steered by the failed
+// decision.
+func (r *SchedulingDecesionReconciler) releaseSteeredPods(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion,
+	inference *melodyiov1alpha1.Inference) error {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(inference.Namespace), client.MatchingLabels(util.ServicePodLabels(inference))); err != nil {
+		return err
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Annotations[consts.AnnotationSchedulingDecesion] != instance.GetName() || pod.Spec.NodeName != "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		r.Log.Info("Releasing pending steered pod", "pod", pod.GetName(), "decision", instance.GetName())
+		if err := r.Delete(ctx, pod); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollbackDecesion restores the replicas of the Inference and the serving deployment, releases the pending pods steered
+// by the decision, and marks the decision as failed. The pod template is not changed by the decisions, so it is not
+// restored.
+func (r *SchedulingDecesionReconciler) rollbackDecesion(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion,
+	inference *melodyiov1alpha1.Inference, deploy *appsv1.Deployment, message string) (ctrl.Result, error) {
+	logger := r.Log.WithValues("SchedulingDecesion", types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()})
+
+	if instance.Status.PreviousReplicas != nil {
+		logger.Info("Rolling back decision", "deployment", deploy.GetName(), "replicas", *instance.Status.PreviousReplicas)
+		inference.Spec.Replicas = instance.Status.PreviousReplicas
+		if err := r.Update(ctx, inference); err != nil {
+			logger.Error(err, "Restore inference replicas error", "name", inference.GetName())
+			return ctrl.Result{}, err
+		}
+		patch := client.MergeFrom(deploy.DeepCopy())
+		deploy.Spec.Replicas = instance.Status.PreviousReplicas
+		if err := r.Patch(ctx, deploy, patch); err != nil {
+			logger.Error(err, "Restore serving deployment error", "name", deploy.GetName())
+			return ctrl.Result{}, err
+		}
+	}
+	if err := r.releaseSteeredPods(ctx, instance, inference); err != nil {
+		return ctrl.Result{}, err
+	}
+	r.markFailed(instance, "RolledBack", message)
+	return ctrl.Result{}, nil
+}
+
+// evictPod evicts the pod through the eviction subresource, so that the disruption budgets and the graceful termination
+// of the pod are respected. It returns false if the eviction is refused for now, which is retried later.
+func (r *SchedulingDecesionReconciler) evictPod(ctx context.Context, pod *corev1.Pod) (bool, error) {
+	eviction := &policyv1.Eviction{ObjectMeta: metav1.ObjectMeta{Name: pod.GetName(), Namespace: pod.GetNamespace()}}
+	err := r.pods.Pods(pod.GetNamespace()).EvictV1(ctx, eviction)
+	if errors.IsTooManyRequests(err) {
+		return false, nil
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	return true, nil
+}
+
+// checkTargetNode returns true if the target node exists and is schedulable, otherwise the decision is marked as failed.
+func (r *SchedulingDecesionReconciler) checkTargetNode(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion) (bool, error) {
+	nodeName := instance.Spec.Objective.TargetNode.Name
+	if nodeName == "" {
+		r.markFailed(instance, "InvalidTarget", "Target node is not specified")
+		return false, nil
+	}
+	node := &corev1.Node{}
+	if err := r.Get(ctx, types.NamespacedName{Name: nodeName}, node); err != nil {
+		if errors.IsNotFound(err) {
+			r.markFailed(instance, "NodeNotFound", fmt.Sprintf("Target node %s is not found", nodeName))
+			return false, nil
+		}
+		return false, err
+	}
+	if node.Spec.Unschedulable {
+		r.markFailed(instance, "NodeUnschedulable", fmt.Sprintf("Target node %s is unschedulable", nodeName))
+		return false, nil
+	}
+	return true, nil
+}
+
+// targetPodKey returns the key of the target pod, which defaults to the namespace of the decision.
+func (r *SchedulingDecesionReconciler) targetPodKey(instance *melodyiov1alpha2.SchedulingDecesion) types.NamespacedName {
+	return types.NamespacedName{Name: instance.Spec.Objective.TargetPod.Name, Namespace: util.GetTargetPodNamespace(instance)}
+}
+
+// getTargetPod returns the target pod of the decision. A pod which does not match the referenced UID is treated as
+// not found, as the referenced pod has been replaced.
+func (r *SchedulingDecesionReconciler) getTargetPod(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion) (*corev1.Pod, error) {
+	key := r.targetPodKey(instance)
+	pod := &corev1.Pod{}
+	if err := r.Get(ctx, key, pod); err != nil {
+		return nil, err
+	}
+	if uid := instance.Spec.Objective.TargetPod.UID; uid != "" && uid != pod.UID {
+		return nil, errors.NewNotFound(corev1.Resource("pods"), key.String())
+	}
+	return pod, nil
+}
+
+// getTargetInference returns the Inference the decision is applied to, and its serving deployment. The Inference is
+// resolved in the namespace of the decision from the reference of the decision, or from the labels of the target pod,
+// as the Inference controller and the feedback resolve it. The resolved Inference is
+// recorded as a label and an owner of the decision, so that it can still be found once the pod is replaced, and the
+// decision is garbage collected with the Inference.
+func (r *SchedulingDecesionReconciler) getTargetInference(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion) (*melodyiov1alpha1.Inference, *appsv1.Deployment, error) {
+	key := r.targetPodKey(instance)
+
+	name := util.InferenceNameForDecesion(instance)
+	if name == "" {
+		pod, err := r.getTargetPod(ctx, instance)
+		if err != nil {
+			return nil, nil, err
+		}
+		name = util.InferenceNameForPod(pod)
+		if name == "" {
+			return nil, nil, errors.NewNotFound(melodyiov1alpha1.GroupVersion.WithResource("inferences").GroupResource(), key.String())
+		}
+	}
+
+	inference := &melodyiov1alpha1.Inference{}
+	if err := r.Get(ctx, types.NamespacedName{Name: name, Namespace: instance.Namespace}, inference); err != nil {
+		return nil, nil, err
+	}
+	deploy := &appsv1.Deployment{}
+	if err := r.Get(ctx, types.NamespacedName{Name: util.GetServiceDeploymentName(inference), Namespace: inference.Namespace}, deploy); err != nil {
+		return nil, nil, err
+	}
+
+	if instance.Labels[consts.LabelInferenceName] != name || !util.IsOwnedByInference(instance, inference) {
+		if instance.Labels == nil {
+			instance.Labels = make(map[string]string)
+		}
+		instance.Labels[consts.LabelInferenceName] = name
+		if err := controllerutil.SetOwnerReference(inference, instance, r.Scheme); err != nil {
+			return nil, nil, err
+		}
+		if err := r.Update(ctx, instance); err != nil {
+			return nil, nil, err
+		}
+	}
+	return inference, deploy, nil
+}
+
+// countReadyPodsOnNode returns the number of ready serving pods of the Inference on the node, or on all nodes if the
+// node name is empty.
+func (r *SchedulingDecesionReconciler) countReadyPodsOnNode(ctx context.Context, inference *melodyiov1alpha1.Inference, nodeName string) (int32, error) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(inference.Namespace), client.MatchingLabels(util.ServicePodLabels(inference))); err != nil {
+		return 0, err
+	}
+	var ready int32
+	for i := range pods.Items {
+		if (nodeName == "" || pods.Items[i].Spec.NodeName == nodeName) && util.IsPodReady(&pods.Items[i]) {
+			ready++
+		}
+	}
+	return ready, nil
+}
+
+func (r *SchedulingDecesionReconciler) markSucceeded(instance *melodyiov1alpha2.SchedulingDecesion, reason, message string) {
+	util.MarkSchedulingDecesionSucceeded(instance, reason, message)
+	r.recorder.Event(instance, corev1.EventTypeNormal, reason, message)
+}
+
+func (r *SchedulingDecesionReconciler) markEvaluated(instance *melodyiov1alpha2.SchedulingDecesion, reason, message string) {
+	util.MarkSchedulingDecesionEvaluated(instance, reason, message)
+	r.recorder.Event(instance, corev1.EventTypeNormal, reason, message)
+}
+
+func (r *SchedulingDecesionReconciler) markRejected(instance *melodyiov1alpha2.SchedulingDecesion, reason, message string) {
+	util.MarkSchedulingDecesionRejected(instance, reason, message)
+	r.recorder.Event(instance, corev1.EventTypeWarning, reason, message)
+}
+
+// delayDecesion keeps the decision in Pending with the reason, and retries it after the interval. The event is only
+// recorded when the decision is delayed for a new reason.
+func (r *SchedulingDecesionReconciler) delayDecesion(instance *melodyiov1alpha2.SchedulingDecesion, reason, message string, after time.Duration) ctrl.Result {
+	if instance.Status.Reason != reason {
+		util.MarkSchedulingDecesionPending(instance, reason, message)
+		r.recorder.Event(instance, corev1.EventTypeWarning, reason, message)
+	}
+	return ctrl.Result{RequeueAfter: after}
+}
+
+func (r *SchedulingDecesionReconciler) markFailed(instance *melodyiov1alpha2.SchedulingDecesion, reason, message string) {
+	util.MarkSchedulingDecesionFailed(instance, reason, message)
+	r.recorder.Event(instance, corev1.EventTypeWarning, reason, message)
+}
@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	melodyiov1alpha1 "melody/api/v1alpha1"
+	melodyiov1alpha2 "melody/api/v1alpha2"
+	consts "melody/controllers/const"
+	util "melody/controllers/utils"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestExecuteDecesionIsIdempotent(t *testing.T) {
+	previous, replicas := int32(2), int32(3)
+	inference := &melodyiov1alpha1.Inference{
+		ObjectMeta: metav1.ObjectMeta{Name: "resnet", Namespace: "default"},
+		Spec:       melodyiov1alpha1.InferenceSpec{Replicas: &previous},
+	}
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "resnet", Namespace: "default"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &previous},
+	}
+	instance := &melodyiov1alpha2.SchedulingDecesion{
+		ObjectMeta: metav1.ObjectMeta{Name: "scale", Namespace: "default"},
+		Spec: melodyiov1alpha2.SchedulingDecesionSpec{
+			Objective: melodyiov1alpha2.SchedulingObjective{Type: melodyiov1alpha2.Scaling, ScalingReplica: replicas},
+		},
+	}
+	util.MarkSchedulingDecesionRunning(instance, reasonApplying, "")
+	instance.Status.PreviousReplicas = &previous
+	instance.Status.NewReplicas = &replicas
+	instance.Status.Plan = &melodyiov1alpha2.SchedulingPlan{Deployment: deploy.Name, ReplicaDelta: replicas - previous}
+
+	c := newFakeClient(t, inference, deploy)
+	r := &SchedulingDecesionReconciler{Client: c, Log: logr.Discard(), recorder: record.NewFakeRecorder(10)}
+	for i := 0; i < 2; i++ {
+		gotInference, gotDeploy := &melodyiov1alpha1.Inference{}, &appsv1.Deployment{}
+		if err := c.Get(context.TODO(), client.ObjectKeyFromObject(inference), gotInference); err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Get(context.TODO(), client.ObjectKeyFromObject(deploy), gotDeploy); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.executeDecesion(context.TODO(), instance, gotInference, gotDeploy); err != nil {
+			t.Fatalf("execute #%d: %v", i, err)
+		}
+		if instance.Status.Reason != "ScalingApplied" {
+			t.Errorf("execute #%d: reason = %q, want ScalingApplied", i, instance.Status.Reason)
+		}
+		if *instance.Status.PreviousReplicas != previous {
+			t.Errorf("execute #%d: previous replicas = %d, want %d", i, *instance.Status.PreviousReplicas, previous)
+		}
+	}
+
+	gotInference, gotDeploy := &melodyiov1alpha1.Inference{}, &appsv1.Deployment{}
+	_ = c.Get(context.TODO(), client.ObjectKeyFromObject(inference), gotInference)
+	_ = c.Get(context.TODO(), client.ObjectKeyFromObject(deploy), gotDeploy)
+	if got := util.GetInferenceReplicas(gotInference); got != replicas {
+		t.Errorf("inference replicas = %d, want %d", got, replicas)
+	}
+	if got := *gotDeploy.Spec.Replicas; got != replicas {
+		t.Errorf("deployment replicas = %d, want %d", got, replicas)
+	}
+}
+
+func TestTrackTransitionReleasesPendingSteeredPods(t *testing.T) {
+	inference := &melodyiov1alpha1.Inference{ObjectMeta: metav1.ObjectMeta{Name: "resnet", Namespace: "default"}}
+	newPod := func(name, decesion, nodeName string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "default", Labels: util.ServicePodLabels(inference),
+				Annotations: map[string]string{consts.AnnotationSchedulingDecesion: decesion},
+			},
+			Spec: corev1.PodSpec{NodeName: nodeName},
+		}
+	}
+	deadline := int32(60)
+	instance := &melodyiov1alpha2.SchedulingDecesion{
+		ObjectMeta: metav1.ObjectMeta{Name: "move", Namespace: "default"},
+		Spec: melodyiov1alpha2.SchedulingDecesionSpec{
+			Objective: melodyiov1alpha2.SchedulingObjective{
+				Type:       melodyiov1alpha2.Transition,
+				TargetNode: melodyiov1alpha2.ObjectReference{Name: "edge-1"},
+			},
+			DeadlineSeconds: &deadline,
+		},
+	}
+	util.MarkSchedulingDecesionRunning(instance, "TransitionApplied", "")
+	startTime := metav1.NewTime(time.Now().Add(-2 * time.Minute))
+	instance.Status.StartTime = &startTime
+
+	c := newFakeClient(t, newPod("pending", "move", ""), newPod("other", "other", ""), newPod("scheduled", "move", "edge-1"))
+	r := &SchedulingDecesionReconciler{Client: c, Log: logr.Discard(), recorder: record.NewFakeRecorder(10)}
+	if _, err := r.trackTransition(context.TODO(), instance, inference); err != nil {
+		t.Fatal(err)
+	}
+	if instance.Status.Reason != "Timeout" {
+		t.Errorf("reason = %q, want Timeout", instance.Status.Reason)
+	}
+	for name, exists := range map[string]bool{"pending": false, "other": true, "scheduled": true} {
+		err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: "default"}, &corev1.Pod{})
+		if exists != (err == nil) {
+			t.Errorf("pod %s: exists = %v, got %v", name, exists, err)
+		}
+	}
+}
+
+func TestExecuteTransitionEvictsTargetPod(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		refused    bool
+		started    time.Duration
+		wantReason string
+		wantPod    bool
+		wantQueue  bool
+	}{
+		{name: "evicted", started: time.Minute, wantReason: "TransitionApplied", wantQueue: true},
+		{name: "refused", refused: true, started: time.Minute, wantReason: reasonApplying, wantPod: true, wantQueue: true},
+		{name: "refused past deadline", refused: true, started: time.Hour, wantReason: "Timeout", wantPod: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			replicas := int32(2)
+			inference := &melodyiov1alpha1.Inference{
+				ObjectMeta: metav1.ObjectMeta{Name: "resnet", Namespace: "default"},
+				Spec:       melodyiov1alpha1.InferenceSpec{Replicas: &replicas},
+			}
+			deploy := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "resnet", Namespace: "default"},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			}
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "resnet-0", Namespace: "default", Labels: util.ServicePodLabels(inference)},
+				Spec:       corev1.PodSpec{NodeName: "cloud-1"},
+			}
+			deadline := int32(600)
+			instance := &melodyiov1alpha2.SchedulingDecesion{
+				ObjectMeta: metav1.ObjectMeta{Name: "move", Namespace: "default"},
+				Spec: melodyiov1alpha2.SchedulingDecesionSpec{
+					Objective: melodyiov1alpha2.SchedulingObjective{
+						Type:       melodyiov1alpha2.Transition,
+						TargetPod:  melodyiov1alpha2.ObjectReference{Name: pod.Name},
+						TargetNode: melodyiov1alpha2.ObjectReference{Name: "edge-1"},
+					},
+					DeadlineSeconds: &deadline,
+				},
+			}
+			util.MarkSchedulingDecesionRunning(instance, reasonApplying, "")
+			startTime := metav1.NewTime(time.Now().Add(-tc.started))
+			instance.Status.StartTime = &startTime
+
+			c := newFakeClient(t, inference, deploy, pod)
+			r := &SchedulingDecesionReconciler{
+				Client: c, Log: logr.Discard(), recorder: record.NewFakeRecorder(10), pods: newFakeEvictor(c, tc.refused),
+			}
+			result, err := r.executeDecesion(context.TODO(), instance, inference, deploy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if instance.Status.Reason != tc.wantReason {
+				t.Errorf("reason = %q, want %q", instance.Status.Reason, tc.wantReason)
+			}
+			if got := result.RequeueAfter > 0; got != tc.wantQueue {
+				t.Errorf("requeued = %v, want %v", got, tc.wantQueue)
+			}
+			err = c.Get(context.TODO(), client.ObjectKeyFromObject(pod), &corev1.Pod{})
+			if tc.wantPod != (err == nil) {
+				t.Errorf("pod exists = %v, got %v", tc.wantPod, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	melodyiov1alpha1 "melody/api/v1alpha1"
+	melodyiov1alpha2 "melody/api/v1alpha2"
+	util "melody/controllers/utils"
+	"melody/pkg/algorithm"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// LatencyObserver observes the serving latency of an Inference for the feedback of the decisions. ServingLatency
+// observes it from the serving metrics scraped into the status of the Inference. The latency is not observed if the
+// reconciler has no LatencyObserver.
+type LatencyObserver interface {
+	// ObserveLatency returns the serving latency of the Inference, or false if it is not observed.
+	ObserveLatency(ctx context.Context, inference *melodyiov1alpha1.Inference) (time.Duration, bool, error)
+}
+
+// FeedbackReporter reports the outcome of the decisions to the algorithm server, so that its algorithms learn from
+// them. It is implemented by the algorithm.Client.
+type FeedbackReporter interface {
+	ReportFeedback(ctx context.Context, feedback *algorithm.FeedbackRequest) error
+}
+
+// reconcileFeedback records the outcome of a succeeded decision once the cluster settles after it is completed. The
+// state after the decision is observed and compared with the state before it to compute the reward.
+func (r *SchedulingDecesionReconciler) reconcileFeedback(ctx context.Context, original *melodyiov1alpha2.SchedulingDecesion) (ctrl.Result, error) {
+	feedback := original.Status.Feedback
+	settle := r.Options.FeedbackSettleWindow
+	if settle <= 0 || !util.IsSucceededSchedulingDecesion(original) || feedback == nil || feedback.Before == nil || feedback.After != nil {
+		return ctrl.Result{}, nil
+	}
+	if wait := settle - time.Since(util.GetSchedulingDecesionCompletionTime(original).Time); wait > 0 {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	instance := original.DeepCopy()
+	after, err := r.observeState(ctx, instance)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	instance.Status.Feedback.After = after
+	instance.Status.Feedback.Reward = util.FormatDecimal(computeReward(instance.Status.Feedback.Before, after, objectiveWeights(instance.Spec.Weights), migrations(instance)))
+	if err := r.Status().Update(ctx, instance); err != nil {
+		if errors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	r.completeTransition(ctx, instance)
+	r.reportFeedback(ctx, instance)
+	return ctrl.Result{}, nil
+}
+
+// reportFeedback reports the feedback of the decision to the algorithm server. The report is best effort and is not
+// retried once the feedback is recorded.
+func (r *SchedulingDecesionReconciler) reportFeedback(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion) {
+	if r.Feedback == nil {
+		return
+	}
+	feedback := instance.Status.Feedback
+	err := r.Feedback.ReportFeedback(ctx, &algorithm.FeedbackRequest{
+		Decision: instance.GetName(),
+		Action:   decesionAction(instance),
+		Before:   nodeStates(feedback.Before),
+		After:    nodeStates(feedback.After),
+		Reward:   util.ParseDecimal(feedback.Reward),
+	})
+	if err != nil {
+		r.Log.Error(err, "Report feedback error", "name", instance.GetName())
+	}
+}
+
+// nodeStates returns the node utilizations of the observed state in the protocol of the algorithm server.
+func nodeStates(state *melodyiov1alpha2.SchedulingState) algorithm.ClusterState {
+	cluster := algorithm.ClusterState{}
+	for _, node := range state.Nodes {
+		cluster.Nodes = append(cluster.Nodes, algorithm.NodeState{
+			Name:   node.Name,
+			CPU:    util.ParseDecimal(node.CPU),
+			Memory: util.ParseDecimal(node.Memory),
+		})
+	}
+	return cluster
+}
+
+// recordStateBefore records the state before the decision is applied, which fails silently as the feedback is best
+// effort and never blocks the decision.
+func (r *SchedulingDecesionReconciler) recordStateBefore(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion) {
+	if r.Options.FeedbackSettleWindow <= 0 {
+		return
+	}
+	before, err := r.observeState(ctx, instance)
+	if err != nil {
+		r.Log.Error(err, "Observe state before decision error", "name", instance.GetName())
+		return
+	}
+	instance.Status.Feedback = &melodyiov1alpha2.SchedulingFeedback{Before: before}
+}
+
+// observeState observes the requested utilization of the schedulable nodes, and the latency of the target Inference.
+func (r *SchedulingDecesionReconciler) observeState(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion) (*melodyiov1alpha2.SchedulingState, error) {
+	state := &melodyiov1alpha2.SchedulingState{ObservedTime: metav1.Now()}
+
+	nodes := &corev1.NodeList{}
+	if err := r.List(ctx, nodes); err != nil {
+		return nil, err
+	}
+	observed := algorithm.ClusterState{}
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if node.Spec.Unschedulable {
+			continue
+		}
+		requests, err := r.getNodeRequests(ctx, node.Name)
+		if err != nil {
+			return nil, err
+		}
+		observed.Nodes = append(observed.Nodes, algorithm.NodeState{
+			Name:   node.Name,
+			CPU:    utilization(requests, node.Status.Allocatable, corev1.ResourceCPU),
+			Memory: utilization(requests, node.Status.Allocatable, corev1.ResourceMemory),
+		})
+	}
+	// The used resources are rewarded the same as they are scheduled on
+	if r.Monitor != nil {
+		if err := r.Monitor.Collect(ctx, &observed); err != nil {
+			return nil, err
+		}
+	}
+	for _, node := range observed.Nodes {
+		state.Nodes = append(state.Nodes, melodyiov1alpha2.NodeState{
+			Name:   node.Name,
+			CPU:    util.FormatDecimal(node.CPU),
+			Memory: util.FormatDecimal(node.Memory),
+		})
+	}
+	cpuImbalance, memoryImbalance := observed.Imbalances()
+	state.CPUImbalance = util.FormatDecimal(cpuImbalance)
+	state.MemoryImbalance = util.FormatDecimal(memoryImbalance)
+
+	if r.Latency == nil {
+		return state, nil
+	}
+	inference := &melodyiov1alpha1.Inference{}
+	if err := r.Get(ctx, types.NamespacedName{Name: util.InferenceNameForDecesion(instance), Namespace: instance.Namespace}, inference); err != nil {
+		return nil, err
+	}
+	latency, ok, err := r.Latency.ObserveLatency(ctx, inference)
+	if err != nil {
+		return nil, err
+	}
+	if ok {
+		state.Latency = &metav1.Duration{Duration: latency}
+	}
+	return state, nil
+}
+
+// computeReward rewards the decision for reducing the imbalance of the cpu and memory utilizations of the nodes, and
+// penalizes it for increasing the latency of the Inference relative to the latency before the decision, and for the
+// pods it moved, weighted by the weights of the decision.
+func computeReward(before, after *melodyiov1alpha2.SchedulingState, weights algorithm.ObjectiveWeights, migrations int) float64 {
+	var latencyIncrease float64
+	if before.Latency != nil && after.Latency != nil {
+		latencyIncrease = algorithm.LatencyIncrease(float64(before.Latency.Duration), float64(after.Latency.Duration))
+	}
+	return weights.Reward(util.ParseDecimal(before.CPUImbalance)-util.ParseDecimal(after.CPUImbalance),
+		util.ParseDecimal(before.MemoryImbalance)-util.ParseDecimal(after.MemoryImbalance), latencyIncrease, migrations)
+}
+
+// migrations returns the number of serving pods the decision moved onto another node.
+func migrations(instance *melodyiov1alpha2.SchedulingDecesion) int {
+	if instance.Spec.Objective.Type == melodyiov1alpha2.Scaling {
+		return 0
+	}
+	return 1
+}
+
+// utilization returns the ratio of the requests to the allocatable of the resource, or 0 if nothing is allocatable.
+func utilization(requests, allocatable corev1.ResourceList, name corev1.ResourceName) float64 {
+	capacity, ok := allocatable[name]
+	if !ok || capacity.IsZero() {
+		return 0
+	}
+	requested := requests[name]
+	return float64(requested.MilliValue()) / float64(capacity.MilliValue())
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	melodyiov1alpha2 "melody/api/v1alpha2"
+	util "melody/controllers/utils"
+	"melody/pkg/algorithm"
+	"melody/pkg/algorithm/stub"
+)
+
+func TestComputeReward(t *testing.T) {
+	latency := func(ms int) *metav1.Duration {
+		return &metav1.Duration{Duration: time.Duration(ms) * time.Millisecond}
+	}
+	before := &melodyiov1alpha2.SchedulingState{CPUImbalance: "0.3000", MemoryImbalance: "0.2000", Latency: latency(100)}
+	tests := []struct {
+		name       string
+		after      *melodyiov1alpha2.SchedulingState
+		weights    algorithm.ObjectiveWeights
+		migrations int
+		want       float64
+	}{
+		{
+			name:    "balanced",
+			after:   &melodyiov1alpha2.SchedulingState{CPUImbalance: "0.1000", MemoryImbalance: "0.1000", Latency: latency(100)},
+			weights: algorithm.DefaultObjectiveWeights(),
+			want:    0.2 + 0.1,
+		},
+		{
+			name:    "slower",
+			after:   &melodyiov1alpha2.SchedulingState{CPUImbalance: "0.3000", MemoryImbalance: "0.2000", Latency: latency(150)},
+			weights: algorithm.DefaultObjectiveWeights(),
+			want:    -0.5,
+		},
+		{
+			name:    "latency not observed after",
+			after:   &melodyiov1alpha2.SchedulingState{CPUImbalance: "0.3000", MemoryImbalance: "0.2000"},
+			weights: algorithm.DefaultObjectiveWeights(),
+		},
+		{
+			name:       "weighted with migration cost",
+			after:      &melodyiov1alpha2.SchedulingState{CPUImbalance: "0.1000", MemoryImbalance: "0.2000", Latency: latency(110)},
+			weights:    algorithm.ObjectiveWeights{CPUBalance: 2, Latency: 1, MigrationCost: 0.1},
+			migrations: 1,
+			want:       2*0.2 - 0.1 - 0.1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := computeReward(before, tt.after, tt.weights, tt.migrations); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("computeReward() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileFeedbackReportsToAlgorithmServer(t *testing.T) {
+	server := stub.NewServer(nil)
+	defer server.Close()
+	config := algorithm.NewConfig()
+	config.Endpoint = server.URL
+	client, err := algorithm.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge-1"},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("4"), corev1.ResourceMemory: resource.MustParse("8Gi"),
+		}},
+	}
+	instance := newTestDecesion("move", "resnet", time.Hour)
+	instance.Spec.Objective = melodyiov1alpha2.SchedulingObjective{
+		Type:       melodyiov1alpha2.Transition,
+		TargetPod:  melodyiov1alpha2.ObjectReference{Name: "resnet-0"},
+		TargetNode: melodyiov1alpha2.ObjectReference{Name: "edge-1"},
+	}
+	util.MarkSchedulingDecesionSucceeded(instance, "TransitionSucceeded", "")
+	completed := metav1.NewTime(time.Now().Add(-time.Hour))
+	instance.Status.CompletionTime = &completed
+	instance.Status.Feedback = &melodyiov1alpha2.SchedulingFeedback{Before: &melodyiov1alpha2.SchedulingState{
+		Nodes: []melodyiov1alpha2.NodeState{{Name: "edge-1", CPU: "0.5", Memory: "0.25"}},
+	}}
+
+	opts := NewSchedulingOptions()
+	r := &SchedulingDecesionReconciler{
+		Client: newFakeClient(t, node, instance), Log: logr.Discard(), Options: opts,
+		recorder: record.NewFakeRecorder(10), Feedback: client,
+	}
+	if _, err := r.reconcileFeedback(context.TODO(), instance); err != nil {
+		t.Fatal(err)
+	}
+
+	feedbacks := server.Feedbacks()
+	if len(feedbacks) != 1 {
+		t.Fatalf("expected 1 feedback, got %d", len(feedbacks))
+	}
+	feedback := feedbacks[0]
+	if feedback.Decision != "move" || feedback.Action.Type != algorithm.ActionTransition || feedback.Action.TargetNode != "edge-1" {
+		t.Errorf("unexpected feedback of %s: %+v", feedback.Decision, feedback.Action)
+	}
+	if len(feedback.Before.Nodes) != 1 || feedback.Before.Nodes[0].CPU != 0.5 || feedback.Before.Nodes[0].Memory != 0.25 {
+		t.Errorf("unexpected state before: %+v", feedback.Before)
+	}
+	if len(feedback.After.Nodes) != 1 || feedback.After.Nodes[0].Name != "edge-1" {
+		t.Errorf("unexpected state after: %+v", feedback.After)
+	}
+}
@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	melodyiov1alpha2 "melody/api/v1alpha2"
+	util "melody/controllers/utils"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rejectStaleDecesion rejects the decision if it is older than the TTL, or if it is superseded by a newer decision
+// targeting the same pod or Inference. The TTL of an approved decision counts from its approval. It returns true if
+// the decision is rejected.
+func (r *SchedulingDecesionReconciler) rejectStaleDecesion(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion, checkTTL bool) (bool, error) {
+	if ttl := r.Options.DecesionTTL; checkTTL && ttl > 0 && !instance.Spec.ResultTime.IsZero() {
+		since := instance.Spec.ResultTime.Time
+		if approved := instance.Status.ApprovedTime; approved != nil && approved.After(since) {
+			since = approved.Time
+		}
+		if age := time.Since(since); age > ttl {
+			r.markRejected(instance, "Expired", fmt.Sprintf("Decision is made %v ago, which is older than the TTL %v", age.Round(time.Second), ttl))
+			return true, nil
+		}
+	}
+
+	decesions := &melodyiov1alpha2.SchedulingDecesionList{}
+	if err := r.List(ctx, decesions, client.InNamespace(instance.GetNamespace())); err != nil {
+		return false, err
+	}
+	for i := range decesions.Items {
+		other := &decesions.Items[i]
+		// The decisions which are only evaluated do not change the cluster, so they do not supersede the others
+		if other.UID == instance.UID || util.IsRejectedSchedulingDecesion(other) || util.IsEvaluatedSchedulingDecesion(other) || other.Spec.DryRun {
+			continue
+		}
+		if supersedes(other, instance) {
+			r.markRejected(instance, "Superseded", fmt.Sprintf("Decision is superseded by the newer decision %s", other.GetName()))
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// supersedes returns true if the decision other supersedes the decision, that is a newer decision targeting the same
+// pod, or a decision for the same Inference made in a later round. The decisions made in the same round for the
+// different pods of an Inference, i.e. by a SchedulingPolicy or Spread, do not supersede each other.
+func supersedes(other, instance *melodyiov1alpha2.SchedulingDecesion) bool {
+	pod, otherPod := &instance.Spec.Objective.TargetPod, &other.Spec.Objective.TargetPod
+	if pod.Name != "" && pod.Name == otherPod.Name && util.GetTargetPodNamespace(instance) == util.GetTargetPodNamespace(other) {
+		return util.IsNewerSchedulingDecesion(other, instance)
+	}
+	name := util.InferenceNameForDecesion(instance)
+	return name != "" && name == util.InferenceNameForDecesion(other) && instance.Spec.ResultTime.Before(&other.Spec.ResultTime)
+}
+
+// holdForApproval holds the decision in PendingApproval until it is approved with the approval annotation, and the plan
+// of the decision is shown for the operators to review. The decision is rejected if it is not approved in time.
+// It returns true if the decision is held.
+func (r *SchedulingDecesionReconciler) holdForApproval(instance *melodyiov1alpha2.SchedulingDecesion) (bool, ctrl.Result) {
+	if util.IsApprovedSchedulingDecesion(instance) {
+		return false, ctrl.Result{}
+	}
+
+	timeout := r.Options.ApprovalTimeout
+	waited := time.Since(instance.CreationTimestamp.Time)
+	if timeout > 0 && waited > timeout {
+		r.markRejected(instance, "ApprovalTimeout", fmt.Sprintf("Decision is not approved in %v", timeout))
+		return true, ctrl.Result{}
+	}
+	if !util.IsPendingApprovalSchedulingDecesion(instance) {
+		msg := fmt.Sprintf("Decision is held until it is annotated with %s=true. %s", melodyiov1alpha2.AnnotationApproved, util.DescribeSchedulingPlan(instance))
+		util.MarkSchedulingDecesionPendingApproval(instance, "ApprovalRequired", msg)
+		r.recorder.Event(instance, corev1.EventTypeNormal, "ApprovalRequired", msg)
+	}
+	if timeout > 0 {
+		return true, ctrl.Result{RequeueAfter: timeout - waited}
+	}
+	return true, ctrl.Result{}
+}
+
+// holdForRateLimit delays the decision in Pending while the decisions applied in the cluster within the last minute
+// reach the limit, or while the Inference is cooling down from its last applied decision. The decisions reserved by
+// this controller are counted even if the cache does not observe them yet. A delayed decision is rejected by the TTL if
+// it is delayed for too long. It returns true if the decision is held.
+func (r *SchedulingDecesionReconciler) holdForRateLimit(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion) (bool, ctrl.Result, error) {
+	limit, cooldown := r.Options.MaxDecesionsPerMinute, r.Options.DecesionCooldown
+	if limit <= 0 && cooldown <= 0 {
+		return false, ctrl.Result{}, nil
+	}
+
+	decesions := &melodyiov1alpha2.SchedulingDecesionList{}
+	if err := r.List(ctx, decesions); err != nil {
+		return false, ctrl.Result{}, err
+	}
+	listed := make(map[types.UID]*melodyiov1alpha2.SchedulingDecesion, len(decesions.Items))
+	for i := range decesions.Items {
+		listed[decesions.Items[i].UID] = &decesions.Items[i]
+	}
+	// A reserved decision is applied before the cache observes its start time
+	for _, sd := range r.reserved.list() {
+		if other, ok := listed[sd.UID]; !ok || other.Status.StartTime == nil {
+			listed[sd.UID] = sd
+		}
+	}
+
+	now := time.Now()
+	name := util.InferenceNameForDecesion(instance)
+	var applied []time.Time
+	var lastApplied time.Time
+	for _, other := range listed {
+		if other.UID == instance.UID || other.Status.StartTime == nil {
+			continue
+		}
+		startTime := other.Status.StartTime.Time
+		if now.Sub(startTime) < time.Minute {
+			applied = append(applied, startTime)
+		}
+		if other.Namespace == instance.Namespace && util.InferenceNameForDecesion(other) == name && startTime.After(lastApplied) {
+			lastApplied = startTime
+		}
+	}
+
+	if cooldown > 0 && now.Sub(lastApplied) < cooldown {
+		wait := cooldown - now.Sub(lastApplied)
+		return true, r.delayDecesion(instance, "CoolingDown",
+			fmt.Sprintf("Inference %s is cooling down from its last decision for %v", name, wait.Round(time.Second)), wait), nil
+	}
+	if limit > 0 && len(applied) >= limit {
+		// The decision is retried once the oldest decisions leave the window
+		sort.Slice(applied, func(i, j int) bool { return applied[i].Before(applied[j]) })
+		wait := time.Minute - now.Sub(applied[len(applied)-limit])
+		return true, r.delayDecesion(instance, "RateLimited",
+			fmt.Sprintf("%d decisions are applied in the cluster within the last minute, which reaches the limit %d", len(applied), limit), wait), nil
+	}
+	return false, ctrl.Result{}, nil
+}
+
+// cleanupDecesionHistory deletes the completed decisions of the Inference beyond the history limit, the most recently
+// completed decisions are retained.
+func (r *SchedulingDecesionReconciler) cleanupDecesionHistory(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion) error {
+	limit := r.Options.DecesionHistoryLimit
+	name := util.InferenceNameForDecesion(instance)
+	if limit <= 0 || name == "" {
+		return nil
+	}
+
+	decesions := &melodyiov1alpha2.SchedulingDecesionList{}
+	if err := r.List(ctx, decesions, client.InNamespace(instance.GetNamespace())); err != nil {
+		return err
+	}
+	var completed []*melodyiov1alpha2.SchedulingDecesion
+	for i := range decesions.Items {
+		sd := &decesions.Items[i]
+		if util.InferenceNameForDecesion(sd) == name && util.IsCompletedSchedulingDecesion(sd) && sd.DeletionTimestamp == nil {
+			completed = append(completed, sd)
+		}
+	}
+	if len(completed) <= limit {
+		return nil
+	}
+
+	sort.Slice(completed, func(i, j int) bool {
+		return util.GetSchedulingDecesionCompletionTime(completed[j]).Before(util.GetSchedulingDecesionCompletionTime(completed[i]))
+	})
+	for _, sd := range completed[limit:] {
+		r.Log.Info("Deleting scheduling decesion beyond the history limit", "name", sd.GetName(), "inference", name, "limit", limit)
+		if err := r.Delete(ctx, sd); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		r.discardTransition(types.NamespacedName{Name: sd.GetName(), Namespace: sd.GetNamespace()})
+	}
+	return nil
+}
@@ -0,0 +1,307 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	melodyiov1alpha2 "melody/api/v1alpha2"
+	util "melody/controllers/utils"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// newTestDecesion returns a decision of the Inference in the default namespace, started the given time ago, or not
+// started if ago is negative.
+func newTestDecesion(name, inferenceName string, ago time.Duration) *melodyiov1alpha2.SchedulingDecesion {
+	sd := &melodyiov1alpha2.SchedulingDecesion{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name)},
+		Spec: melodyiov1alpha2.SchedulingDecesionSpec{
+			InferenceRef: &corev1.LocalObjectReference{Name: inferenceName},
+		},
+	}
+	if ago >= 0 {
+		startTime := metav1.NewTime(time.Now().Add(-ago))
+		sd.Status.StartTime = &startTime
+	}
+	return sd
+}
+
+func TestHoldForRateLimit(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		limit    int
+		cooldown time.Duration
+		listed   []*melodyiov1alpha2.SchedulingDecesion
+		reserved []*melodyiov1alpha2.SchedulingDecesion
+		expected string
+	}{
+		{
+			name:     "disabled",
+			listed:   []*melodyiov1alpha2.SchedulingDecesion{newTestDecesion("a", "resnet", time.Second)},
+			expected: "",
+		},
+		{
+			name:     "window below limit",
+			limit:    2,
+			listed:   []*melodyiov1alpha2.SchedulingDecesion{newTestDecesion("a", "bert", 10*time.Second)},
+			expected: "",
+		},
+		{
+			name:  "window reaches limit",
+			limit: 2,
+			listed: []*melodyiov1alpha2.SchedulingDecesion{
+				newTestDecesion("a", "bert", 10*time.Second), newTestDecesion("b", "vgg", 30*time.Second),
+			},
+			expected: "RateLimited",
+		},
+		{
+			name:  "window excludes older decisions",
+			limit: 2,
+			listed: []*melodyiov1alpha2.SchedulingDecesion{
+				newTestDecesion("a", "bert", 10*time.Second), newTestDecesion("b", "vgg", 2*time.Minute),
+			},
+			expected: "",
+		},
+		{
+			name:     "window counts reserved decisions not observed yet",
+			limit:    2,
+			listed:   []*melodyiov1alpha2.SchedulingDecesion{newTestDecesion("a", "bert", 10*time.Second), newTestDecesion("b", "vgg", -1)},
+			reserved: []*melodyiov1alpha2.SchedulingDecesion{newTestDecesion("b", "vgg", time.Second)},
+			expected: "RateLimited",
+		},
+		{
+			name:     "window counts reserved decisions not listed yet",
+			limit:    1,
+			reserved: []*melodyiov1alpha2.SchedulingDecesion{newTestDecesion("a", "bert", time.Second)},
+			expected: "RateLimited",
+		},
+		{
+			name:     "cooling down",
+			cooldown: time.Minute,
+			listed:   []*melodyiov1alpha2.SchedulingDecesion{newTestDecesion("a", "resnet", 10*time.Second)},
+			expected: "CoolingDown",
+		},
+		{
+			name:     "cooled down",
+			cooldown: time.Minute,
+			listed:   []*melodyiov1alpha2.SchedulingDecesion{newTestDecesion("a", "resnet", 2*time.Minute)},
+			expected: "",
+		},
+		{
+			name:     "cooldown of another inference",
+			cooldown: time.Minute,
+			listed:   []*melodyiov1alpha2.SchedulingDecesion{newTestDecesion("a", "bert", 10*time.Second)},
+			expected: "",
+		},
+		{
+			name:     "cooldown of reserved decision not observed yet",
+			cooldown: time.Minute,
+			listed:   []*melodyiov1alpha2.SchedulingDecesion{newTestDecesion("a", "resnet", -1)},
+			reserved: []*melodyiov1alpha2.SchedulingDecesion{newTestDecesion("a", "resnet", time.Second)},
+			expected: "CoolingDown",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var objects []client.Object
+			for _, sd := range tc.listed {
+				objects = append(objects, sd)
+			}
+			r := &SchedulingDecesionReconciler{
+				Client:   newFakeClient(t, objects...),
+				Log:      logr.Discard(),
+				Options:  &SchedulingOptions{MaxDecesionsPerMinute: tc.limit, DecesionCooldown: tc.cooldown},
+				recorder: record.NewFakeRecorder(10),
+				reserved: newDecesionReservations(),
+			}
+			for _, sd := range tc.reserved {
+				r.reserved.reserve(sd)
+			}
+
+			instance := newTestDecesion("pending", "resnet", -1)
+			held, result, err := r.holdForRateLimit(context.TODO(), instance)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if held != (tc.expected != "") || instance.Status.Reason != tc.expected {
+				t.Fatalf("expected held with %q, got %v with %q", tc.expected, held, instance.Status.Reason)
+			}
+			if held && result.RequeueAfter <= 0 {
+				t.Errorf("expected the held decision to be retried, got %+v", result)
+			}
+		})
+	}
+}
+
+func TestRejectStaleDecesion(t *testing.T) {
+	now := time.Now()
+	withResultTime := func(sd *melodyiov1alpha2.SchedulingDecesion, ago time.Duration) *melodyiov1alpha2.SchedulingDecesion {
+		sd.Spec.ResultTime = metav1.NewTime(now.Add(-ago))
+		return sd
+	}
+	rejected := withResultTime(newTestDecesion("rejected", "resnet", -1), time.Second)
+	rejected.Status.Phase = melodyiov1alpha2.DecesionRejected
+	samePod := withResultTime(newTestDecesion("same-pod", "bert", -1), time.Second)
+	samePod.Spec.Objective.TargetPod = melodyiov1alpha2.ObjectReference{Name: "resnet-0", Namespace: "default"}
+	// The decisions of a round are made at the same result time for the different pods of the Inference
+	sameRound := withResultTime(newTestDecesion("same-round", "resnet", -1), 10*time.Second)
+	sameRound.Spec.Objective.TargetPod = melodyiov1alpha2.ObjectReference{Name: "resnet-1", Namespace: "default"}
+	dryRun := withResultTime(newTestDecesion("dry-run", "resnet", -1), time.Second)
+	dryRun.Spec.DryRun = true
+	evaluated := withResultTime(newTestDecesion("evaluated", "resnet", -1), time.Second)
+	evaluated.Status.Phase = melodyiov1alpha2.DecesionEvaluated
+
+	for _, tc := range []struct {
+		name     string
+		ttl      time.Duration
+		checkTTL bool
+		ago      time.Duration
+		approved time.Duration
+		others   []*melodyiov1alpha2.SchedulingDecesion
+		expected string
+	}{
+		{name: "fresh", ttl: time.Minute, checkTTL: true, ago: 10 * time.Second, expected: ""},
+		{name: "expired", ttl: time.Minute, checkTTL: true, ago: 2 * time.Minute, expected: "Expired"},
+		{name: "expired but not checked", ttl: time.Minute, ago: 2 * time.Minute, expected: ""},
+		{name: "ttl disabled", checkTTL: true, ago: time.Hour, expected: ""},
+		{name: "approved recently", ttl: time.Minute, checkTTL: true, ago: time.Hour, approved: 10 * time.Second, expected: ""},
+		{name: "approved long ago", ttl: time.Minute, checkTTL: true, ago: time.Hour, approved: 2 * time.Minute, expected: "Expired"},
+		{
+			name:     "superseded by newer decision of the inference",
+			ago:      10 * time.Second,
+			others:   []*melodyiov1alpha2.SchedulingDecesion{withResultTime(newTestDecesion("newer", "resnet", -1), time.Second)},
+			expected: "Superseded",
+		},
+		{
+			name:     "older decision of the inference",
+			ago:      10 * time.Second,
+			others:   []*melodyiov1alpha2.SchedulingDecesion{withResultTime(newTestDecesion("older", "resnet", -1), time.Minute)},
+			expected: "",
+		},
+		{
+			name:     "newer decision of another inference",
+			ago:      10 * time.Second,
+			others:   []*melodyiov1alpha2.SchedulingDecesion{withResultTime(newTestDecesion("other", "bert", -1), time.Second)},
+			expected: "",
+		},
+		{
+			name:     "newer rejected decision",
+			ago:      10 * time.Second,
+			others:   []*melodyiov1alpha2.SchedulingDecesion{rejected},
+			expected: "",
+		},
+		{
+			name:     "superseded by newer decision of the target pod",
+			ago:      10 * time.Second,
+			others:   []*melodyiov1alpha2.SchedulingDecesion{samePod},
+			expected: "Superseded",
+		},
+		{
+			name:     "decision of another pod in the same round",
+			ago:      10 * time.Second,
+			others:   []*melodyiov1alpha2.SchedulingDecesion{sameRound},
+			expected: "",
+		},
+		{
+			name:     "newer dry-run decision",
+			ago:      10 * time.Second,
+			others:   []*melodyiov1alpha2.SchedulingDecesion{dryRun},
+			expected: "",
+		},
+		{
+			name:     "newer evaluated decision",
+			ago:      10 * time.Second,
+			others:   []*melodyiov1alpha2.SchedulingDecesion{evaluated},
+			expected: "",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var objects []client.Object
+			for _, sd := range tc.others {
+				objects = append(objects, sd.DeepCopy())
+			}
+			r := &SchedulingDecesionReconciler{
+				Client:   newFakeClient(t, objects...),
+				Log:      logr.Discard(),
+				Options:  &SchedulingOptions{DecesionTTL: tc.ttl},
+				recorder: record.NewFakeRecorder(10),
+			}
+
+			instance := withResultTime(newTestDecesion("pending", "resnet", -1), tc.ago)
+			instance.Spec.Objective.TargetPod = melodyiov1alpha2.ObjectReference{Name: "resnet-0", Namespace: "default"}
+			if tc.approved > 0 {
+				approvedTime := metav1.NewTime(now.Add(-tc.approved))
+				instance.Status.ApprovedTime = &approvedTime
+			}
+			stale, err := r.rejectStaleDecesion(context.TODO(), instance, tc.checkTTL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stale != (tc.expected != "") || instance.Status.Reason != tc.expected {
+				t.Fatalf("expected stale with %q, got %v with %q", tc.expected, stale, instance.Status.Reason)
+			}
+			if stale && !util.IsRejectedSchedulingDecesion(instance) {
+				t.Errorf("expected the stale decision to be rejected, got %s", instance.Status.Phase)
+			}
+		})
+	}
+}
+
+func TestCleanupDecesionHistory(t *testing.T) {
+	now := time.Now()
+	completed := func(name, inferenceName string, ago time.Duration) *melodyiov1alpha2.SchedulingDecesion {
+		sd := newTestDecesion(name, inferenceName, ago+time.Minute)
+		completionTime := metav1.NewTime(now.Add(-ago))
+		sd.Status.Phase = melodyiov1alpha2.DecesionSucceeded
+		sd.Status.CompletionTime = &completionTime
+		return sd
+	}
+	running := newTestDecesion("running", "resnet", time.Second)
+	running.Status.Phase = melodyiov1alpha2.DecesionRunning
+
+	for _, tc := range []struct {
+		name     string
+		limit    int
+		expected []string
+	}{
+		{name: "unlimited", limit: 0, expected: []string{"a", "b", "c", "d", "other", "running"}},
+		{name: "below limit", limit: 4, expected: []string{"a", "b", "c", "d", "other", "running"}},
+		{name: "beyond limit", limit: 2, expected: []string{"a", "b", "other", "running"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			// The decisions a to d of resnet are completed from the most recently to the least recently
+			objects := []client.Object{
+				completed("c", "resnet", 3*time.Minute), completed("a", "resnet", time.Minute),
+				completed("d", "resnet", 4*time.Minute), completed("b", "resnet", 2*time.Minute),
+				completed("other", "bert", time.Hour), running.DeepCopy(),
+			}
+			r := &SchedulingDecesionReconciler{
+				Client:  newFakeClient(t, objects...),
+				Log:     logr.Discard(),
+				Options: &SchedulingOptions{DecesionHistoryLimit: tc.limit},
+			}
+			if err := r.cleanupDecesionHistory(context.TODO(), completed("a", "resnet", time.Minute)); err != nil {
+				t.Fatal(err)
+			}
+
+			decesions := &melodyiov1alpha2.SchedulingDecesionList{}
+			if err := r.List(context.TODO(), decesions); err != nil {
+				t.Fatal(err)
+			}
+			var retained []string
+			for _, sd := range decesions.Items {
+				retained = append(retained, sd.GetName())
+			}
+			sort.Strings(retained)
+			if !reflect.DeepEqual(retained, tc.expected) {
+				t.Errorf("expected %v to be retained, got %v", tc.expected, retained)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	melodyiov1alpha1 "melody/api/v1alpha1"
+	melodyiov1alpha2 "melody/api/v1alpha2"
+	util "melody/controllers/utils"
+	"melody/pkg/algorithm"
+)
+
+// beginTransition records the cluster state and the action of the decision before it is applied into the replay, so
+// that the transition is completed with the reward by the feedback. The replay is best effort and never blocks the
+// decision.
+func (r *SchedulingDecesionReconciler) beginTransition(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion) {
+	if r.Replay == nil || instance.Status.Feedback == nil {
+		return
+	}
+	state, err := r.observeClusterState(ctx)
+	if err != nil {
+		r.Log.Error(err, "Observe cluster state for replay error", "name", instance.GetName())
+		return
+	}
+	var algorithmName string
+	if instance.Spec.Algorithm != nil {
+		algorithmName = string(*instance.Spec.Algorithm)
+	}
+	if err := r.Replay.Begin(replayKey(instance), algorithmName, state, decesionAction(instance)); err != nil {
+		r.Log.Error(err, "Record replay transition error", "name", instance.GetName())
+	}
+}
+
+// completeTransition completes the transition of the decision with the reward and the cluster state after it.
+func (r *SchedulingDecesionReconciler) completeTransition(ctx context.Context, instance *melodyiov1alpha2.SchedulingDecesion) {
+	if r.Replay == nil {
+		return
+	}
+	state, err := r.observeClusterState(ctx)
+	if err != nil {
+		r.Log.Error(err, "Observe cluster state for replay error", "name", instance.GetName())
+		return
+	}
+	reward := util.ParseDecimal(instance.Status.Feedback.Reward)
+	if err := r.Replay.Complete(replayKey(instance), reward, state); err != nil {
+		r.Log.Error(err, "Record replay transition error", "name", instance.GetName())
+	}
+}
+
+// discardTransition drops the transition of the decision which is never rewarded, i.e. it failed, was rolled back or
+// was deleted before its feedback is observed.
+func (r *SchedulingDecesionReconciler) discardTransition(key types.NamespacedName) {
+	if r.Replay == nil {
+		return
+	}
+	if err := r.Replay.Discard(key.String()); err != nil {
+		r.Log.Error(err, "Discard replay transition error", "name", key.Name)
+	}
+}
+
+// sweepTransitions drops the pending transitions of the decisions which no longer exist, i.e. deleted while the
+// manager was down.
+func (r *SchedulingDecesionReconciler) sweepTransitions(ctx context.Context) {
+	if r.Replay == nil {
+		return
+	}
+	pending, err := r.Replay.Pending()
+	if err != nil {
+		r.Log.Error(err, "List pending replay transitions error")
+		return
+	}
+	for _, decision := range pending {
+		parts := strings.SplitN(decision, "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := types.NamespacedName{Namespace: parts[0], Name: parts[1]}
+		if err := r.Get(ctx, key, &melodyiov1alpha2.SchedulingDecesion{}); errors.IsNotFound(err) {
+			r.discardTransition(key)
+		}
+	}
+}
+
+// observeClusterState observes the cluster state of all Inferences the same way the scheduling policies do.
+func (r *SchedulingDecesionReconciler) observeClusterState(ctx context.Context) (algorithm.ClusterState, error) {
+	inferences := &melodyiov1alpha1.InferenceList{}
+	if err := r.List(ctx, inferences); err != nil {
+		return algorithm.ClusterState{}, err
+	}
+	return gatherClusterState(ctx, r.Client, r.Latency, r.Monitor, inferences.Items)
+}
+
+func replayKey(instance *melodyiov1alpha2.SchedulingDecesion) string {
+	return types.NamespacedName{Name: instance.GetName(), Namespace: instance.GetNamespace()}.String()
+}
+
+// decesionAction returns the action of the decision in the protocol of the algorithm server.
+func decesionAction(instance *melodyiov1alpha2.SchedulingDecesion) algorithm.Action {
+	objective := &instance.Spec.Objective
+	return algorithm.Action{
+		Type:      algorithm.ActionType(objective.Type),
+		Inference: util.InferenceNameForDecesion(instance),
+		Namespace: instance.GetNamespace(),
+		TargetPod: algorithm.ObjectReference{
+			Name:      objective.TargetPod.Name,
+			Namespace: objective.TargetPod.Namespace,
+			UID:       string(objective.TargetPod.UID),
+		},
+		TargetNode:     objective.TargetNode.Name,
+		ScalingReplica: objective.ScalingReplica,
+	}
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	util "melody/controllers/utils"
+	"melody/pkg/algorithm"
+	"melody/pkg/replay"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestReconcileDeletedDecesionDiscardsTransition(t *testing.T) {
+	recorder, err := replay.NewRecorder(t.TempDir(), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := types.NamespacedName{Name: "move", Namespace: "default"}
+	if err := recorder.Begin(key.String(), "DQN", algorithm.ClusterState{}, algorithm.Action{}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &SchedulingDecesionReconciler{
+		Client:   newFakeClient(t),
+		Log:      logr.Discard(),
+		Replay:   recorder,
+		reserved: newDecesionReservations(),
+	}
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: key}); err != nil {
+		t.Fatal(err)
+	}
+	pending, err := filepath.Glob(filepath.Join(recorder.Dir, "pending", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("expected the pending transition of the deleted decision to be discarded, got %v", pending)
+	}
+}
+
+func TestReconcileCompletedDecesionSweepsTransitions(t *testing.T) {
+	recorder, err := replay.NewRecorder(t.TempDir(), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a is succeeded and retained, b is beyond the history limit, c was deleted while the manager was down, and d is
+	// still running
+	decesions := map[string]time.Duration{"a": time.Minute, "b": time.Hour, "d": -1}
+	var objects []client.Object
+	for name, ago := range decesions {
+		sd := newTestDecesion(name, "resnet", -1)
+		if ago >= 0 {
+			util.MarkSchedulingDecesionSucceeded(sd, "TransitionSucceeded", "")
+			completed := metav1.NewTime(time.Now().Add(-ago))
+			sd.Status.CompletionTime = &completed
+		} else {
+			util.MarkSchedulingDecesionRunning(sd, "TransitionApplied", "")
+		}
+		objects = append(objects, sd)
+	}
+	for _, name := range []string{"a", "b", "c", "d"} {
+		if err := recorder.Begin("default/"+name, "DQN", algorithm.ClusterState{}, algorithm.Action{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := &SchedulingDecesionReconciler{
+		Client:   newFakeClient(t, objects...),
+		Log:      logr.Discard(),
+		Options:  &SchedulingOptions{DecesionHistoryLimit: 1},
+		Replay:   recorder,
+		recorder: record.NewFakeRecorder(10),
+		reserved: newDecesionReservations(),
+	}
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "a", Namespace: "default"}}); err != nil {
+		t.Fatal(err)
+	}
+	pending, err := recorder.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(pending)
+	if expected := []string{"default/a", "default/d"}; !reflect.DeepEqual(pending, expected) {
+		t.Errorf("expected the pending transitions %v, got %v", expected, pending)
+	}
+}
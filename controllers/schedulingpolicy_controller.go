@@ -0,0 +1,421 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	melodyiov1alpha1 "melody/api/v1alpha1"
+	melodyiov1alpha2 "melody/api/v1alpha2"
+	consts "melody/controllers/const"
+	"melody/pkg/algorithm"
+	"melody/pkg/monitor"
+	"melody/pkg/scheduler"
+)
+
+const (
+	SchedulingPolicyControllerName = "schedulingpolicy-controller"
+	// defaultPolicyInterval is the interval of a policy without interval.
+	defaultPolicyInterval = time.Minute
+)
+
+// NewSchedulingPolicyReconciler returns a new reconciler, which runs the built-in algorithms in process, and calls the
+// algorithm server for the other algorithms.
+func NewSchedulingPolicyReconciler(mgr manager.Manager, opts *PolicyOptions, algorithmClient scheduler.AlgorithmClient) *SchedulingPolicyReconciler {
+	r := &SchedulingPolicyReconciler{
+		Client:     mgr.GetClient(),
+		Scheme:     mgr.GetScheme(),
+		Options:    opts,
+		Schedulers: scheduler.Builtin(),
+		Algorithm:  algorithmClient,
+		breakers:   make(map[melodyiov1alpha2.SchedulingAlgorithm]*scheduler.CircuitBreaker),
+		recorder:   mgr.GetEventRecorderFor(SchedulingPolicyControllerName),
+		Log:        logf.Log.WithName(SchedulingPolicyControllerName),
+	}
+
+	return r
+}
+
+// SchedulingPolicyReconciler reconciles a SchedulingPolicy object
+type SchedulingPolicyReconciler struct {
+	client.Client
+	Log     logr.Logger
+	Scheme  *runtime.Scheme
+	Options *PolicyOptions
+	// Schedulers is the registry of the algorithms, the algorithms not registered are called on the algorithm server.
+	Schedulers scheduler.Registry
+	Algorithm  scheduler.AlgorithmClient
+	Latency    LatencyObserver
+	// Monitor observes the used resources of the nodes and the pods, the requested resources are used if not set.
+	Monitor  monitor.Collector
+	recorder record.EventRecorder
+
+	// breakers are the circuit breakers by algorithm, which are shared by the policies of the same algorithm.
+	mu       sync.Mutex
+	breakers map[melodyiov1alpha2.SchedulingAlgorithm]*scheduler.CircuitBreaker
+}
+
+// schedulerFor returns the scheduler of the algorithm, which falls back to the fallback algorithm through its circuit
+// breaker.
+func (r *SchedulingPolicyReconciler) schedulerFor(algorithmName melodyiov1alpha2.SchedulingAlgorithm) (scheduler.Scheduler, error) {
+	fallbackAlgorithm := melodyiov1alpha2.SchedulingAlgorithm(r.Options.FallbackAlgorithm)
+	fallback, hasFallback := r.Schedulers[fallbackAlgorithm]
+	primary, ok := r.Schedulers[algorithmName]
+	if !ok && r.Algorithm != nil {
+		primary, ok = scheduler.NewRemote(r.Algorithm, algorithmName), true
+	}
+	if !ok && !hasFallback {
+		return nil, fmt.Errorf("scheduling algorithm %q is not supported without an algorithm server", algorithmName)
+	}
+	if algorithmName == fallbackAlgorithm || !hasFallback {
+		return primary, nil
+	}
+	if !ok {
+		// Without a scheduler, the policies of the algorithm always run the fallback algorithm
+		primary = scheduler.SchedulerFunc(func(ctx context.Context, state algorithm.ClusterState, weights algorithm.ObjectiveWeights) ([]scheduler.Decision, error) {
+			return nil, fmt.Errorf("scheduling algorithm %q is not supported without an algorithm server", algorithmName)
+		})
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	breaker, ok := r.breakers[algorithmName]
+	if !ok {
+		breaker = scheduler.NewCircuitBreaker(primary, algorithmName, fallback, fallbackAlgorithm,
+			r.Options.FailureThreshold, r.Options.OpenDuration, r.Options.AlgorithmTimeout)
+		r.breakers[algorithmName] = breaker
+	}
+	return breaker, nil
+}
+
+//+kubebuilder:rbac:groups=melody.io.melody.io,resources=schedulingpolicies,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=melody.io.melody.io,resources=schedulingpolicies/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=melody.io.melody.io,resources=schedulingpolicies/finalizers,verbs=update
+//+kubebuilder:rbac:groups=melody.io.melody.io,resources=schedulingdecesions,verbs=get;list;watch;create
+//+kubebuilder:rbac:groups=melody.io.melody.io,resources=schedulingdecesions/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=melody.io.melody.io,resources=inferences,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=metrics.k8s.io,resources=nodes;pods,verbs=get;list
+
+// Reconcile runs a scheduling round of the SchedulingPolicy once its interval elapses, which gathers the state of the
+// selected Inferences, calls the algorithm, and creates the decisions for the actions.
+func (r *SchedulingPolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := r.Log.WithValues("SchedulingPolicy", req.NamespacedName)
+
+	// 1) Fetch the scheduling policy instance
+	original := &melodyiov1alpha2.SchedulingPolicy{}
+	err := r.Get(ctx, req.NamespacedName, original)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			logger.Info("try to get scheduling policy, but it has been deleted", "key", req.String())
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "SchedulingPolicy instance get error")
+		return ctrl.Result{}, err
+	}
+	if original.Spec.Suspend {
+		return ctrl.Result{}, nil
+	}
+
+	// 2) Wait for the interval since the last round
+	interval := defaultPolicyInterval
+	if original.Spec.IntervalSeconds > 0 {
+		interval = time.Duration(original.Spec.IntervalSeconds) * time.Second
+	}
+	if last := original.Status.LastScheduleTime; last != nil {
+		if wait := interval - time.Since(last.Time); wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+	}
+
+	// 3) Claim the round before any decision is created, so that a round retried after a conflict or an error waits
+	// for the next interval instead of creating the decisions again
+	claimed := original.DeepCopy()
+	now := metav1.Now()
+	claimed.Status.LastScheduleTime = &now
+	if err := r.Status().Update(ctx, claimed); err != nil {
+		if errors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		return ctrl.Result{}, err
+	}
+	original = claimed
+
+	// 4) Run a scheduling round
+	instance := original.DeepCopy()
+	if err := r.schedulePolicy(ctx, instance); err != nil {
+		logger.Error(err, "Schedule policy error")
+		return ctrl.Result{}, err
+	}
+
+	// 5) Compare status before-and-after reconciling and update changes to cluster.
+	if !reflect.DeepEqual(original.Status, instance.Status) {
+		if err = r.Status().Update(ctx, instance); err != nil {
+			if errors.IsConflict(err) {
+				// retry later when update operation violates with etcd concurrency control.
+				return ctrl.Result{Requeue: true}, nil
+			}
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: interval}, nil
+}
+
+// schedulePolicy runs a scheduling round of the policy, and records the result in the status. A failure of the
+// algorithm is recorded and retried in the next round.
+func (r *SchedulingPolicyReconciler) schedulePolicy(ctx context.Context, policy *melodyiov1alpha2.SchedulingPolicy) error {
+	selector, err := metav1.LabelSelectorAsSelector(&policy.Spec.Selector)
+	if err != nil {
+		r.markPolicy(policy, corev1.EventTypeWarning, "InvalidSelector", err.Error(), nil)
+		return nil
+	}
+	inferences := &melodyiov1alpha1.InferenceList{}
+	if err := r.List(ctx, inferences, client.InNamespace(policy.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return err
+	}
+	policy.Status.SelectedInferences = int32(len(inferences.Items))
+	if len(inferences.Items) == 0 {
+		r.markPolicy(policy, corev1.EventTypeNormal, "NoInference", "No inference is selected", nil)
+		return nil
+	}
+
+	var created []corev1.LocalObjectReference
+	var failures, fallbackReasons []string
+	failureReason := ""
+	skipped := 0
+	for _, group := range groupByAlgorithm(policy, inferences.Items) {
+		// The round is claimed already, so a failure is reported in the status instead of dropping the rest of the round
+		state, err := gatherClusterState(ctx, r.Client, r.Latency, r.Monitor, group.inferences)
+		if err != nil {
+			failureReason, failures = "ClusterStateError", append(failures, err.Error())
+			continue
+		}
+		sched, err := r.schedulerFor(group.algorithm)
+		if err != nil {
+			failureReason, failures = "UnsupportedAlgorithm", append(failures, err.Error())
+			continue
+		}
+		var decisions []scheduler.Decision
+		if breaker, ok := sched.(*scheduler.CircuitBreaker); ok {
+			var reason string
+			decisions, reason, err = breaker.ScheduleWithFallback(ctx, state, objectiveWeights(policy.Spec.Weights))
+			if reason != "" {
+				fallbackReasons = append(fallbackReasons, reason)
+			}
+		} else {
+			decisions, err = sched.Schedule(ctx, state, objectiveWeights(policy.Spec.Weights))
+		}
+		if err != nil {
+			failureReason, failures = "AlgorithmError", append(failures, err.Error())
+			continue
+		}
+
+		for i := range decisions {
+			decision := &decisions[i]
+			if !isDecisionAllowed(policy, group.inferences, decision) {
+				skipped++
+				continue
+			}
+			sd, err := r.newDecesion(policy, group.algorithm, decision)
+			if err == nil {
+				err = r.Create(ctx, sd)
+			}
+			if err != nil {
+				failureReason = "CreateError"
+				failures = append(failures, fmt.Sprintf("failed to create the decision of inference %s: %v", decision.Inference.Name, err))
+				continue
+			}
+			r.explainDecesion(ctx, sd, decision.Explanation)
+			created = append(created, corev1.LocalObjectReference{Name: sd.Name})
+		}
+	}
+	r.markFallback(policy, strings.Join(fallbackReasons, "; "))
+
+	msg := fmt.Sprintf("%d decision(s) are created for %d inference(s)", len(created), len(inferences.Items))
+	if skipped > 0 {
+		msg = fmt.Sprintf("%s, %d decision(s) are not allowed by the policy", msg, skipped)
+	}
+	if len(failures) > 0 {
+		r.markPolicy(policy, corev1.EventTypeWarning, failureReason, fmt.Sprintf("%s, %s", strings.Join(failures, "; "), msg), created)
+		return nil
+	}
+	r.markPolicy(policy, corev1.EventTypeNormal, "Scheduled", msg, created)
+	return nil
+}
+
+// algorithmGroup is the Inferences scheduled by the same algorithm.
+type algorithmGroup struct {
+	algorithm  melodyiov1alpha2.SchedulingAlgorithm
+	inferences []melodyiov1alpha1.Inference
+}
+
+// groupByAlgorithm groups the Inferences by their scheduling algorithm, which defaults to the algorithm of the policy.
+// The groups are sorted by algorithm.
+func groupByAlgorithm(policy *melodyiov1alpha2.SchedulingPolicy, inferences []melodyiov1alpha1.Inference) []algorithmGroup {
+	indexes := make(map[melodyiov1alpha2.SchedulingAlgorithm]int)
+	var groups []algorithmGroup
+	for i := range inferences {
+		algorithmName := policy.Spec.Algorithm
+		if name := inferences[i].Spec.SchedulingAlgorithm; name != "" {
+			algorithmName = melodyiov1alpha2.SchedulingAlgorithm(name)
+		}
+		index, ok := indexes[algorithmName]
+		if !ok {
+			index = len(groups)
+			indexes[algorithmName] = index
+			groups = append(groups, algorithmGroup{algorithm: algorithmName})
+		}
+		groups[index].inferences = append(groups[index].inferences, inferences[i])
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].algorithm < groups[j].algorithm })
+	return groups
+}
+
+// newDecesion returns the SchedulingDecesion of the decision requested from the algorithm, which is owned by the
+// policy.
+func (r *SchedulingPolicyReconciler) newDecesion(policy *melodyiov1alpha2.SchedulingPolicy, requested melodyiov1alpha2.SchedulingAlgorithm,
+	decision *scheduler.Decision) (*melodyiov1alpha2.SchedulingDecesion, error) {
+	algorithmName := requested
+	if decision.Algorithm != "" {
+		algorithmName = decision.Algorithm
+	}
+	name := decision.Inference.Name
+	sd := &melodyiov1alpha2.SchedulingDecesion{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: name + "-",
+			Namespace:    policy.Namespace,
+			Labels: map[string]string{
+				consts.LabelInferenceName:        name,
+				consts.LabelSchedulingPolicyName: policy.Name,
+			},
+		},
+		Spec: melodyiov1alpha2.SchedulingDecesionSpec{
+			InferenceRef: &corev1.LocalObjectReference{Name: name},
+			Algorithm:    &algorithmName,
+			Objective:    decision.Objective,
+			ResultTime:   roundTime(policy),
+			Weights:      policy.Spec.Weights.DeepCopy(),
+		},
+	}
+	if algorithmName != requested {
+		sd.Annotations = map[string]string{consts.AnnotationFallbackFrom: string(requested)}
+	}
+	if err := controllerutil.SetControllerReference(policy, sd, r.Scheme); err != nil {
+		return nil, err
+	}
+	return sd, nil
+}
+
+// roundTime returns the time of the current round of the policy, which is the result time of all the decisions of the
+// round, so that the decisions of a round do not supersede each other.
+func roundTime(policy *melodyiov1alpha2.SchedulingPolicy) metav1.Time {
+	if policy.Status.LastScheduleTime != nil {
+		return *policy.Status.LastScheduleTime
+	}
+	return metav1.Now()
+}
+
+// isDecisionAllowed returns true if the decision targets an Inference selected by the policy with an allowed type.
+func isDecisionAllowed(policy *melodyiov1alpha2.SchedulingPolicy, inferences []melodyiov1alpha1.Inference, decision *scheduler.Decision) bool {
+	if decision.Inference.Namespace != "" && decision.Inference.Namespace != policy.Namespace {
+		return false
+	}
+	selected := false
+	for i := range inferences {
+		if inferences[i].Name == decision.Inference.Name {
+			selected = true
+			break
+		}
+	}
+	if !selected {
+		return false
+	}
+	if len(policy.Spec.AllowedTypes) == 0 {
+		return true
+	}
+	for _, t := range policy.Spec.AllowedTypes {
+		if t == decision.Objective.Type {
+			return true
+		}
+	}
+	return false
+}
+
+// markFallback sets the FallbackActive condition of the policy, and records an event while the fallback is active.
+func (r *SchedulingPolicyReconciler) markFallback(policy *melodyiov1alpha2.SchedulingPolicy, reason string) {
+	if reason == "" {
+		if meta.IsStatusConditionTrue(policy.Status.Conditions, melodyiov1alpha2.PolicyFallbackActive) {
+			r.recorder.Event(policy, corev1.EventTypeNormal, "AlgorithmRecovered", "Selected algorithms are recovered")
+		}
+		meta.SetStatusCondition(&policy.Status.Conditions, metav1.Condition{
+			Type:    melodyiov1alpha2.PolicyFallbackActive,
+			Status:  metav1.ConditionFalse,
+			Reason:  "AlgorithmAvailable",
+			Message: "Decisions are produced by the selected algorithms",
+		})
+		return
+	}
+	msg := fmt.Sprintf("%s, decisions are produced by fallback algorithm %s", reason, r.Options.FallbackAlgorithm)
+	meta.SetStatusCondition(&policy.Status.Conditions, metav1.Condition{
+		Type:    melodyiov1alpha2.PolicyFallbackActive,
+		Status:  metav1.ConditionTrue,
+		Reason:  "AlgorithmUnavailable",
+		Message: msg,
+	})
+	r.recorder.Event(policy, corev1.EventTypeWarning, "FallbackActive", msg)
+}
+
+// markPolicy records the result of the round in the status of the policy.
+func (r *SchedulingPolicyReconciler) markPolicy(policy *melodyiov1alpha2.SchedulingPolicy, eventType, reason, message string, decesions []corev1.LocalObjectReference) {
+	now := metav1.Now()
+	policy.Status.LastScheduleTime = &now
+	policy.Status.LastDecesions = decesions
+	policy.Status.Reason = reason
+	policy.Status.Message = message
+	if eventType == corev1.EventTypeWarning || len(decesions) > 0 {
+		r.recorder.Event(policy, eventType, reason, message)
+	}
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *SchedulingPolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(SchedulingPolicyControllerName).
+		For(&melodyiov1alpha2.SchedulingPolicy{}).
+		Complete(r)
+}
@@ -0,0 +1,269 @@
+package controllers
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	melodyiov1alpha1 "melody/api/v1alpha1"
+	melodyiov1alpha2 "melody/api/v1alpha2"
+	"melody/pkg/algorithm"
+	"melody/pkg/scheduler"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newTestInference(name, algorithmName string) melodyiov1alpha1.Inference {
+	return melodyiov1alpha1.Inference{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"app": "edge"}},
+		Spec:       melodyiov1alpha1.InferenceSpec{SchedulingAlgorithm: algorithmName},
+	}
+}
+
+func TestGroupByAlgorithm(t *testing.T) {
+	policy := &melodyiov1alpha2.SchedulingPolicy{Spec: melodyiov1alpha2.SchedulingPolicySpec{Algorithm: melodyiov1alpha2.GreedyScheduling}}
+	tests := []struct {
+		name       string
+		inferences []melodyiov1alpha1.Inference
+		want       map[melodyiov1alpha2.SchedulingAlgorithm][]string
+		order      []melodyiov1alpha2.SchedulingAlgorithm
+	}{
+		{name: "no inference"},
+		{
+			name:       "policy algorithm",
+			inferences: []melodyiov1alpha1.Inference{newTestInference("a", ""), newTestInference("b", "")},
+			want:       map[melodyiov1alpha2.SchedulingAlgorithm][]string{melodyiov1alpha2.GreedyScheduling: {"a", "b"}},
+			order:      []melodyiov1alpha2.SchedulingAlgorithm{melodyiov1alpha2.GreedyScheduling},
+		},
+		{
+			name: "own algorithms sorted",
+			inferences: []melodyiov1alpha1.Inference{
+				newTestInference("a", "spread"), newTestInference("b", ""), newTestInference("c", "DQN"), newTestInference("d", "spread"),
+			},
+			want: map[melodyiov1alpha2.SchedulingAlgorithm][]string{
+				melodyiov1alpha2.DQNScheduling:    {"c"},
+				melodyiov1alpha2.GreedyScheduling: {"b"},
+				melodyiov1alpha2.SpreadScheduling: {"a", "d"},
+			},
+			order: []melodyiov1alpha2.SchedulingAlgorithm{melodyiov1alpha2.DQNScheduling, melodyiov1alpha2.GreedyScheduling, melodyiov1alpha2.SpreadScheduling},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := groupByAlgorithm(policy, tt.inferences)
+			var order []melodyiov1alpha2.SchedulingAlgorithm
+			got := make(map[melodyiov1alpha2.SchedulingAlgorithm][]string)
+			for _, group := range groups {
+				order = append(order, group.algorithm)
+				for _, inference := range group.inferences {
+					got[group.algorithm] = append(got[group.algorithm], inference.Name)
+				}
+			}
+			if !reflect.DeepEqual(order, tt.order) {
+				t.Errorf("groupByAlgorithm() order = %v, want %v", order, tt.order)
+			}
+			if len(got) > 0 || len(tt.want) > 0 {
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("groupByAlgorithm() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestIsDecisionAllowed(t *testing.T) {
+	inferences := []melodyiov1alpha1.Inference{newTestInference("resnet", "")}
+	decision := func(namespace, name string, schedulingType melodyiov1alpha2.SchedulingType) *scheduler.Decision {
+		return &scheduler.Decision{
+			Inference: types.NamespacedName{Namespace: namespace, Name: name},
+			Objective: melodyiov1alpha2.SchedulingObjective{Type: schedulingType},
+		}
+	}
+	tests := []struct {
+		name     string
+		allowed  []melodyiov1alpha2.SchedulingType
+		decision *scheduler.Decision
+		want     bool
+	}{
+		{name: "all types allowed", decision: decision("default", "resnet", melodyiov1alpha2.Transition), want: true},
+		{name: "namespace defaults to the policy", decision: decision("", "resnet", melodyiov1alpha2.Scaling), want: true},
+		{name: "other namespace", decision: decision("other", "resnet", melodyiov1alpha2.Transition)},
+		{name: "not selected", decision: decision("default", "bert", melodyiov1alpha2.Transition)},
+		{
+			name:     "allowed type",
+			allowed:  []melodyiov1alpha2.SchedulingType{melodyiov1alpha2.Scaling, melodyiov1alpha2.Transition},
+			decision: decision("default", "resnet", melodyiov1alpha2.Transition),
+			want:     true,
+		},
+		{
+			name:     "type not allowed",
+			allowed:  []melodyiov1alpha2.SchedulingType{melodyiov1alpha2.Scaling},
+			decision: decision("default", "resnet", melodyiov1alpha2.TransitionScaling),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &melodyiov1alpha2.SchedulingPolicy{
+				ObjectMeta: metav1.ObjectMeta{Name: "edge", Namespace: "default"},
+				Spec:       melodyiov1alpha2.SchedulingPolicySpec{AllowedTypes: tt.allowed},
+			}
+			if got := isDecisionAllowed(policy, inferences, tt.decision); got != tt.want {
+				t.Errorf("isDecisionAllowed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// conflictingClient fails the first status update of a policy after a decision is created with a conflict, as if the
+// policy was changed while its round was running.
+type conflictingClient struct {
+	client.Client
+	created, conflicted bool
+}
+
+func (c *conflictingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.created = true
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *conflictingClient) Status() client.StatusWriter {
+	return &conflictingStatusWriter{StatusWriter: c.Client.Status(), c: c}
+}
+
+type conflictingStatusWriter struct {
+	client.StatusWriter
+	c *conflictingClient
+}
+
+func (w *conflictingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if _, ok := obj.(*melodyiov1alpha2.SchedulingPolicy); ok && w.c.created && !w.c.conflicted {
+		w.c.conflicted = true
+		return errors.NewConflict(melodyiov1alpha2.GroupVersion.WithResource("schedulingpolicies").GroupResource(), obj.GetName(), nil)
+	}
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func TestReconcilePolicyCreatesDecisionsOncePerRound(t *testing.T) {
+	const testAlgorithm melodyiov1alpha2.SchedulingAlgorithm = "test"
+	inference := newTestInference("resnet", "")
+	policy := &melodyiov1alpha2.SchedulingPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge", Namespace: "default", UID: "policy-uid"},
+		Spec: melodyiov1alpha2.SchedulingPolicySpec{
+			Selector:        metav1.LabelSelector{MatchLabels: map[string]string{"app": "edge"}},
+			IntervalSeconds: 3600,
+			Algorithm:       testAlgorithm,
+		},
+	}
+	c := &conflictingClient{Client: newFakeClient(t, &inference, policy)}
+	r := &SchedulingPolicyReconciler{
+		Client:  c,
+		Log:     logr.Discard(),
+		Scheme:  c.Scheme(),
+		Options: &PolicyOptions{FallbackAlgorithm: string(testAlgorithm)},
+		Schedulers: scheduler.Registry{testAlgorithm: scheduler.SchedulerFunc(
+			func(ctx context.Context, state algorithm.ClusterState, weights algorithm.ObjectiveWeights) ([]scheduler.Decision, error) {
+				return []scheduler.Decision{{
+					Inference: types.NamespacedName{Namespace: "default", Name: "resnet"},
+					Objective: melodyiov1alpha2.SchedulingObjective{Type: melodyiov1alpha2.Scaling, ScalingReplica: 2},
+				}}, nil
+			})},
+		recorder: record.NewFakeRecorder(10),
+	}
+
+	// The round is retried after the conflict
+	for i := 0; i < 2; i++ {
+		if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(policy)}); err != nil {
+			t.Fatalf("reconcile #%d: %v", i, err)
+		}
+	}
+	decesions := &melodyiov1alpha2.SchedulingDecesionList{}
+	if err := c.List(context.TODO(), decesions); err != nil {
+		t.Fatal(err)
+	}
+	if !c.conflicted {
+		t.Fatal("expected the status update of the round to conflict")
+	}
+	if len(decesions.Items) != 1 {
+		t.Errorf("expected 1 decision in the round, got %d", len(decesions.Items))
+	}
+	got := &melodyiov1alpha2.SchedulingPolicy{}
+	if err := c.Get(context.TODO(), client.ObjectKeyFromObject(policy), got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.LastScheduleTime == nil {
+		t.Fatalf("expected the round to be claimed, got %+v", got.Status)
+	}
+	for _, sd := range decesions.Items {
+		if !sd.Spec.ResultTime.Equal(got.Status.LastScheduleTime) {
+			t.Errorf("expected decision %s to be made at the round %v, got %v", sd.Name, got.Status.LastScheduleTime, sd.Spec.ResultTime)
+		}
+	}
+}
+
+// failingClient fails the creation of the decisions of an Inference.
+type failingClient struct {
+	client.Client
+	inference string
+}
+
+func (c *failingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if obj.GetLabels()[melodyiov1alpha2.LabelInferenceName] == c.inference {
+		return errors.NewServiceUnavailable("etcd is unavailable")
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestReconcilePolicyReportsFailedCreate(t *testing.T) {
+	const testAlgorithm melodyiov1alpha2.SchedulingAlgorithm = "test"
+	resnet, bert := newTestInference("resnet", ""), newTestInference("bert", "")
+	policy := &melodyiov1alpha2.SchedulingPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge", Namespace: "default", UID: "policy-uid"},
+		Spec: melodyiov1alpha2.SchedulingPolicySpec{
+			Selector:        metav1.LabelSelector{MatchLabels: map[string]string{"app": "edge"}},
+			IntervalSeconds: 3600,
+			Algorithm:       testAlgorithm,
+		},
+	}
+	c := &failingClient{Client: newFakeClient(t, &resnet, &bert, policy), inference: "resnet"}
+	r := &SchedulingPolicyReconciler{
+		Client:  c,
+		Log:     logr.Discard(),
+		Scheme:  c.Scheme(),
+		Options: &PolicyOptions{FallbackAlgorithm: string(testAlgorithm)},
+		Schedulers: scheduler.Registry{testAlgorithm: scheduler.SchedulerFunc(
+			func(ctx context.Context, state algorithm.ClusterState, weights algorithm.ObjectiveWeights) ([]scheduler.Decision, error) {
+				var decisions []scheduler.Decision
+				for _, name := range []string{"resnet", "bert"} {
+					decisions = append(decisions, scheduler.Decision{
+						Inference: types.NamespacedName{Namespace: "default", Name: name},
+						Objective: melodyiov1alpha2.SchedulingObjective{Type: melodyiov1alpha2.Scaling, ScalingReplica: 2},
+					})
+				}
+				return decisions, nil
+			})},
+		recorder: record.NewFakeRecorder(10),
+	}
+
+	if _, err := r.Reconcile(context.TODO(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(policy)}); err != nil {
+		t.Fatal(err)
+	}
+	decesions := &melodyiov1alpha2.SchedulingDecesionList{}
+	if err := c.List(context.TODO(), decesions); err != nil {
+		t.Fatal(err)
+	}
+	if len(decesions.Items) != 1 || decesions.Items[0].Labels[melodyiov1alpha2.LabelInferenceName] != "bert" {
+		t.Fatalf("expected the decision of bert to be created, got %d decision(s)", len(decesions.Items))
+	}
+	got := &melodyiov1alpha2.SchedulingPolicy{}
+	if err := c.Get(context.TODO(), client.ObjectKeyFromObject(policy), got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.Reason != "CreateError" || len(got.Status.LastDecesions) != 1 {
+		t.Errorf("expected the failed create to be reported with the created decision, got %+v", got.Status)
+	}
+}
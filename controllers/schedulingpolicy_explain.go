@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	melodyiov1alpha2 "melody/api/v1alpha2"
+	util "melody/controllers/utils"
+	"melody/pkg/algorithm"
+)
+
+// maxExplainedCandidates is the number of the best candidate nodes recorded for a decision.
+const maxExplainedCandidates = 5
+
+// explainDecesion records the explanation of the algorithm in the status of the created decision, and in an event of
+// the decision. The explanation is best effort and never fails the scheduling round.
+func (r *SchedulingPolicyReconciler) explainDecesion(ctx context.Context, sd *melodyiov1alpha2.SchedulingDecesion, explanation *algorithm.Explanation) {
+	if explanation == nil || len(explanation.Candidates)+len(explanation.Features) == 0 {
+		return
+	}
+	explained := newSchedulingExplanation(explanation)
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		instance := &melodyiov1alpha2.SchedulingDecesion{}
+		if err := r.Get(ctx, types.NamespacedName{Name: sd.Name, Namespace: sd.Namespace}, instance); err != nil {
+			return err
+		}
+		instance.Status.Explanation = explained
+		return r.Status().Update(ctx, instance)
+	})
+	if err != nil {
+		r.Log.Error(err, "Record scheduling decesion explanation error", "name", sd.Name)
+	}
+	r.recorder.Event(sd, corev1.EventTypeNormal, "Explained", explanationMessage(sd, explained))
+}
+
+// newSchedulingExplanation returns the explanation of the decision, with the best candidates only.
+func newSchedulingExplanation(explanation *algorithm.Explanation) *melodyiov1alpha2.SchedulingExplanation {
+	explained := &melodyiov1alpha2.SchedulingExplanation{}
+	for i, candidate := range explanation.Candidates {
+		if i == maxExplainedCandidates {
+			break
+		}
+		explained.Candidates = append(explained.Candidates, melodyiov1alpha2.NodeScore{
+			Node:  candidate.Node,
+			Score: util.FormatDecimal(candidate.Score),
+		})
+	}
+	for _, feature := range explanation.Features {
+		explained.Features = append(explained.Features, melodyiov1alpha2.FeatureImportance{
+			Name:       feature.Name,
+			Value:      util.FormatDecimal(feature.Value),
+			Importance: util.FormatDecimal(feature.Importance),
+		})
+	}
+	return explained
+}
+
+// explanationMessage summarizes the explanation, i.e. "Algorithm DQN scored nodes edge-3=3.9, edge-1=2, most
+// important features edge-1/cpu=0.9 (importance 0.9)".
+func explanationMessage(sd *melodyiov1alpha2.SchedulingDecesion, explanation *melodyiov1alpha2.SchedulingExplanation) string {
+	algorithmName := "unknown"
+	if sd.Spec.Algorithm != nil {
+		algorithmName = string(*sd.Spec.Algorithm)
+	}
+	parts := []string{fmt.Sprintf("Algorithm %s decided %s", algorithmName, sd.Spec.Objective.Type)}
+	if len(explanation.Candidates) > 0 {
+		scores := make([]string, 0, len(explanation.Candidates))
+		for _, candidate := range explanation.Candidates {
+			scores = append(scores, fmt.Sprintf("%s=%s", candidate.Node, candidate.Score))
+		}
+		parts = append(parts, "scored nodes "+strings.Join(scores, ", "))
+	}
+	if len(explanation.Features) > 0 {
+		features := make([]string, 0, len(explanation.Features))
+		for _, feature := range explanation.Features {
+			features = append(features, fmt.Sprintf("%s=%s (importance %s)", feature.Name, feature.Value, feature.Importance))
+		}
+		parts = append(parts, "most important features "+strings.Join(features, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
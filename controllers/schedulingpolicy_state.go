@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	melodyiov1alpha1 "melody/api/v1alpha1"
+	melodyiov1alpha2 "melody/api/v1alpha2"
+	util "melody/controllers/utils"
+	"melody/pkg/algorithm"
+	"melody/pkg/monitor"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// gatherClusterState observes the requested utilization of the schedulable nodes, and the serving pods, latency and
+// serving metrics of the Inferences, which is the state the scheduling algorithms decide on. The used resources
+// observed by the collector replace the requested resources if the collector is set.
+func gatherClusterState(ctx context.Context, c client.Reader, latency LatencyObserver, collector monitor.Collector, inferences []melodyiov1alpha1.Inference) (algorithm.ClusterState, error) {
+	state := algorithm.ClusterState{}
+
+	pods := &corev1.PodList{}
+	if err := c.List(ctx, pods); err != nil {
+		return state, err
+	}
+	requests := make(map[string]corev1.ResourceList)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName == "" || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		if requests[pod.Spec.NodeName] == nil {
+			requests[pod.Spec.NodeName] = corev1.ResourceList{}
+		}
+		util.AddResourceList(requests[pod.Spec.NodeName], util.GetPodRequests(&pod.Spec))
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := c.List(ctx, nodes); err != nil {
+		return state, err
+	}
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		if node.Spec.Unschedulable {
+			continue
+		}
+		state.Nodes = append(state.Nodes, algorithm.NodeState{
+			Name:                node.Name,
+			AllocatableMilliCPU: node.Status.Allocatable.Cpu().MilliValue(),
+			AllocatableMemory:   node.Status.Allocatable.Memory().Value(),
+			CPU:                 utilization(requests[node.Name], node.Status.Allocatable, corev1.ResourceCPU),
+			Memory:              utilization(requests[node.Name], node.Status.Allocatable, corev1.ResourceMemory),
+		})
+	}
+
+	for i := range inferences {
+		inference := &inferences[i]
+		selector := labels.SelectorFromSet(util.ServicePodLabels(inference))
+		inferenceState := algorithm.InferenceState{
+			Name:      inference.Name,
+			Namespace: inference.Namespace,
+			Replicas:  util.GetInferenceReplicas(inference),
+		}
+		for j := range pods.Items {
+			pod := &pods.Items[j]
+			if pod.Namespace != inference.Namespace || pod.DeletionTimestamp != nil || !selector.Matches(labels.Set(pod.Labels)) {
+				continue
+			}
+			podRequests := util.GetPodRequests(&pod.Spec)
+			inferenceState.Pods = append(inferenceState.Pods, algorithm.PodState{
+				Name:      pod.Name,
+				Namespace: pod.Namespace,
+				UID:       string(pod.UID),
+				Node:      pod.Spec.NodeName,
+				MilliCPU:  podRequests.Cpu().MilliValue(),
+				Memory:    podRequests.Memory().Value(),
+			})
+		}
+		if latency != nil {
+			observed, ok, err := latency.ObserveLatency(ctx, inference)
+			if err != nil {
+				return state, err
+			}
+			if ok {
+				inferenceState.LatencyMilliseconds = float64(observed.Microseconds()) / 1000
+			}
+		}
+		if metrics, ok := inferenceServingMetrics(inference); ok {
+			inferenceState.LatencyP99Milliseconds = metrics.LatencyP99Milliseconds
+			inferenceState.RequestsPerSecond = metrics.RequestsPerSecond
+			inferenceState.BatchUtilization = metrics.BatchUtilization
+		}
+		state.Inferences = append(state.Inferences, inferenceState)
+	}
+	if collector != nil {
+		if err := collector.Collect(ctx, &state); err != nil {
+			return state, fmt.Errorf("collect used resources: %v", err)
+		}
+	}
+	return state, nil
+}
+
+// objectiveWeights returns the weights of the objectives in the protocol of the algorithm server, the default weights
+// if not set.
+func objectiveWeights(weights *melodyiov1alpha2.ObjectiveWeights) algorithm.ObjectiveWeights {
+	if weights == nil {
+		return algorithm.DefaultObjectiveWeights()
+	}
+	return algorithm.ObjectiveWeights{
+		CPUBalance:    float64(weights.CPUBalance),
+		MemoryBalance: float64(weights.MemoryBalance),
+		Latency:       float64(weights.Latency),
+		MigrationCost: float64(weights.MigrationCost),
+	}
+}
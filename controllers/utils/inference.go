@@ -34,3 +34,35 @@ func GetDBStorageEndpoint() string {
 		consts.DefaultControllerNamespace,
 		consts.DefaultMorphlingDBManagerServicePort)
 }
+
+// GetInferenceReplicas returns the expected serving replicas of the Inference, which defaults to 1.
+func GetInferenceReplicas(t *melodyiov1alpha1.Inference) int32 {
+	if t.Spec.Replicas == nil {
+		return 1
+	}
+	return *t.Spec.Replicas
+}
+
+// BoundInferenceReplicas clamps the replicas into the min and max replicas of the Inference.
+func BoundInferenceReplicas(t *melodyiov1alpha1.Inference, replicas int32) int32 {
+	min := int32(1)
+	if t.Spec.MinReplicas != nil {
+		min = *t.Spec.MinReplicas
+	}
+	if replicas < min {
+		replicas = min
+	}
+	if t.Spec.MaxReplicas != nil && replicas > *t.Spec.MaxReplicas {
+		replicas = *t.Spec.MaxReplicas
+	}
+	return replicas
+}
+
+// GetInferenceMinAvailable returns the number of serving pods of the Inference which must stay ready, which defaults
+// to defaultMinAvailable.
+func GetInferenceMinAvailable(t *melodyiov1alpha1.Inference, defaultMinAvailable int32) int32 {
+	if t.Spec.MinAvailable == nil {
+		return defaultMinAvailable
+	}
+	return *t.Spec.MinAvailable
+}
@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"testing"
+
+	"k8s.io/utils/pointer"
+	melodyiov1alpha1 "melody/api/v1alpha1"
+)
+
+func TestBoundInferenceReplicas(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		minReplicas *int32
+		maxReplicas *int32
+		replicas    int32
+		expected    int32
+	}{
+		{name: "within default bounds", replicas: 3, expected: 3},
+		{name: "below default min", replicas: 0, expected: 1},
+		{name: "zero min", minReplicas: pointer.Int32(0), replicas: 0, expected: 0},
+		{name: "below min", minReplicas: pointer.Int32(2), maxReplicas: pointer.Int32(4), replicas: 1, expected: 2},
+		{name: "above max", minReplicas: pointer.Int32(2), maxReplicas: pointer.Int32(4), replicas: 6, expected: 4},
+		{name: "at bounds", minReplicas: pointer.Int32(2), maxReplicas: pointer.Int32(2), replicas: 2, expected: 2},
+		{name: "unbounded max", minReplicas: pointer.Int32(2), replicas: 100, expected: 100},
+		// The webhook rejects min above max, the max wins if such an Inference is stored anyway
+		{name: "min above max", minReplicas: pointer.Int32(5), maxReplicas: pointer.Int32(3), replicas: 1, expected: 3},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			inference := &melodyiov1alpha1.Inference{
+				Spec: melodyiov1alpha1.InferenceSpec{MinReplicas: tc.minReplicas, MaxReplicas: tc.maxReplicas},
+			}
+			if replicas := BoundInferenceReplicas(inference, tc.replicas); replicas != tc.expected {
+				t.Errorf("expected %d replicas, got %d", tc.expected, replicas)
+			}
+		})
+	}
+}
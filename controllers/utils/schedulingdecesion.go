@@ -0,0 +1,314 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	melodyv1alpha1 "melody/api/v1alpha1"
+	melodyv1alpha2 "melody/api/v1alpha2"
+	consts "melody/controllers/const"
+)
+
+// SchedulingDecesion related
+
+func IsCompletedSchedulingDecesion(sd *melodyv1alpha2.SchedulingDecesion) bool {
+	return IsSucceededSchedulingDecesion(sd) || IsFailedSchedulingDecesion(sd) || IsRejectedSchedulingDecesion(sd) ||
+		IsEvaluatedSchedulingDecesion(sd)
+}
+
+func IsSucceededSchedulingDecesion(sd *melodyv1alpha2.SchedulingDecesion) bool {
+	return sd.Status.Phase == melodyv1alpha2.DecesionSucceeded
+}
+
+func IsFailedSchedulingDecesion(sd *melodyv1alpha2.SchedulingDecesion) bool {
+	return sd.Status.Phase == melodyv1alpha2.DecesionFailed
+}
+
+func IsPendingApprovalSchedulingDecesion(sd *melodyv1alpha2.SchedulingDecesion) bool {
+	return sd.Status.Phase == melodyv1alpha2.DecesionPendingApproval
+}
+
+// IsApprovedSchedulingDecesion returns true if the decision is approved by an operator.
+func IsApprovedSchedulingDecesion(sd *melodyv1alpha2.SchedulingDecesion) bool {
+	return sd.Annotations[melodyv1alpha2.AnnotationApproved] == "true"
+}
+
+func IsEvaluatedSchedulingDecesion(sd *melodyv1alpha2.SchedulingDecesion) bool {
+	return sd.Status.Phase == melodyv1alpha2.DecesionEvaluated
+}
+
+func IsRejectedSchedulingDecesion(sd *melodyv1alpha2.SchedulingDecesion) bool {
+	return sd.Status.Phase == melodyv1alpha2.DecesionRejected
+}
+
+func IsRunningSchedulingDecesion(sd *melodyv1alpha2.SchedulingDecesion) bool {
+	return sd.Status.Phase == melodyv1alpha2.DecesionRunning
+}
+
+func setPhaseSchedulingDecesion(sd *melodyv1alpha2.SchedulingDecesion, phase melodyv1alpha2.SchedulingDecesionPhase, status corev1.ConditionStatus, reason, message string) {
+	now := metav1.Now()
+	sd.Status.Phase = phase
+	sd.Status.Status = status
+	sd.Status.Reason = reason
+	sd.Status.Message = message
+	sd.Status.LastUpdateTime = now
+}
+
+func MarkSchedulingDecesionPending(sd *melodyv1alpha2.SchedulingDecesion, reason, message string) {
+	setPhaseSchedulingDecesion(sd, melodyv1alpha2.DecesionPending, corev1.ConditionUnknown, reason, message)
+}
+
+func MarkSchedulingDecesionPendingApproval(sd *melodyv1alpha2.SchedulingDecesion, reason, message string) {
+	setPhaseSchedulingDecesion(sd, melodyv1alpha2.DecesionPendingApproval, corev1.ConditionUnknown, reason, message)
+}
+
+func MarkSchedulingDecesionRunning(sd *melodyv1alpha2.SchedulingDecesion, reason, message string) {
+	setPhaseSchedulingDecesion(sd, melodyv1alpha2.DecesionRunning, corev1.ConditionUnknown, reason, message)
+	sd.Status.Used = true
+	if sd.Status.StartTime == nil {
+		now := metav1.Now()
+		sd.Status.StartTime = &now
+	}
+}
+
+func MarkSchedulingDecesionSucceeded(sd *melodyv1alpha2.SchedulingDecesion, reason, message string) {
+	setPhaseSchedulingDecesion(sd, melodyv1alpha2.DecesionSucceeded, corev1.ConditionTrue, reason, message)
+	now := metav1.Now()
+	sd.Status.CompletionTime = &now
+}
+
+func MarkSchedulingDecesionFailed(sd *melodyv1alpha2.SchedulingDecesion, reason, message string) {
+	setPhaseSchedulingDecesion(sd, melodyv1alpha2.DecesionFailed, corev1.ConditionFalse, reason, message)
+	now := metav1.Now()
+	sd.Status.CompletionTime = &now
+}
+
+func MarkSchedulingDecesionEvaluated(sd *melodyv1alpha2.SchedulingDecesion, reason, message string) {
+	setPhaseSchedulingDecesion(sd, melodyv1alpha2.DecesionEvaluated, corev1.ConditionTrue, reason, message)
+	now := metav1.Now()
+	sd.Status.CompletionTime = &now
+}
+
+// DescribeSchedulingPlan returns a human readable description of the plan of the decision.
+func DescribeSchedulingPlan(sd *melodyv1alpha2.SchedulingDecesion) string {
+	plan := sd.Status.Plan
+	if plan == nil {
+		return "Decision changes nothing"
+	}
+	msg := fmt.Sprintf("Would patch deployment %s", plan.Deployment)
+	if plan.DeploymentPatch == "" {
+		msg = fmt.Sprintf("Would keep deployment %s", plan.Deployment)
+	}
+	if plan.SteerPods > 0 {
+		msg = fmt.Sprintf("%s and steer %d new pod(s) onto node %s", msg, plan.SteerPods, plan.PreferredNode)
+	}
+	if sd.Status.PreviousReplicas != nil && sd.Status.NewReplicas != nil {
+		msg = fmt.Sprintf("%s, replicas %d -> %d (%+d)", msg, *sd.Status.PreviousReplicas, *sd.Status.NewReplicas, plan.ReplicaDelta)
+	}
+	if plan.DeploymentPatch == "" {
+		if plan.SteerPods == 0 {
+			return fmt.Sprintf("%s, which is a no-op", msg)
+		}
+		return msg
+	}
+	return fmt.Sprintf("%s: %s", msg, plan.DeploymentPatch)
+}
+
+func MarkSchedulingDecesionRejected(sd *melodyv1alpha2.SchedulingDecesion, reason, message string) {
+	setPhaseSchedulingDecesion(sd, melodyv1alpha2.DecesionRejected, corev1.ConditionFalse, reason, message)
+	now := metav1.Now()
+	sd.Status.CompletionTime = &now
+}
+
+// IsNewerSchedulingDecesion returns true if the decision a is made after the decision b. Decisions made at the same
+// time are ordered by their creation time and name.
+func IsNewerSchedulingDecesion(a, b *melodyv1alpha2.SchedulingDecesion) bool {
+	if !a.Spec.ResultTime.Equal(&b.Spec.ResultTime) {
+		return b.Spec.ResultTime.Before(&a.Spec.ResultTime)
+	}
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return b.CreationTimestamp.Before(&a.CreationTimestamp)
+	}
+	return a.Name > b.Name
+}
+
+// IsSameSchedulingTarget returns true if the decisions target the same Inference or the same pod.
+func IsSameSchedulingTarget(a, b *melodyv1alpha2.SchedulingDecesion) bool {
+	if name := InferenceNameForDecesion(a); name != "" && name == InferenceNameForDecesion(b) {
+		return true
+	}
+	podA, podB := &a.Spec.Objective.TargetPod, &b.Spec.Objective.TargetPod
+	return podA.Name != "" && podA.Name == podB.Name && podA.Namespace == podB.Namespace
+}
+
+// GetSchedulingDecesionCompletionTime returns the completion time of the decision, or its creation time if the
+// decision is not completed.
+func GetSchedulingDecesionCompletionTime(sd *melodyv1alpha2.SchedulingDecesion) *metav1.Time {
+	if sd.Status.CompletionTime != nil {
+		return sd.Status.CompletionTime
+	}
+	return &sd.CreationTimestamp
+}
+
+// GetSchedulingDecesionDeadline returns the deadline of the decision, which defaults to consts.DefaultSchedulingTimeout.
+func GetSchedulingDecesionDeadline(sd *melodyv1alpha2.SchedulingDecesion) time.Duration {
+	if sd.Spec.DeadlineSeconds == nil {
+		return consts.DefaultSchedulingTimeout
+	}
+	return time.Duration(*sd.Spec.DeadlineSeconds) * time.Second
+}
+
+// IsSchedulingDecesionTimeout returns true if the decision has been running longer than its deadline.
+func IsSchedulingDecesionTimeout(sd *melodyv1alpha2.SchedulingDecesion) bool {
+	if sd.Status.StartTime == nil {
+		return false
+	}
+	return metav1.Now().Sub(sd.Status.StartTime.Time) > GetSchedulingDecesionDeadline(sd)
+}
+
+// InferenceNameForDecesion returns the name of the Inference the decision is applied to, which is referenced by the
+// decision or recorded in its labels. An empty name is returned if the Inference is not resolved yet.
+func InferenceNameForDecesion(sd *melodyv1alpha2.SchedulingDecesion) string {
+	if sd.Spec.InferenceRef != nil && sd.Spec.InferenceRef.Name != "" {
+		return sd.Spec.InferenceRef.Name
+	}
+	return sd.Labels[consts.LabelInferenceName]
+}
+
+// IsOwnedByInference returns true if the decision has an owner reference to the Inference.
+func IsOwnedByInference(sd *melodyv1alpha2.SchedulingDecesion, inference *melodyv1alpha1.Inference) bool {
+	for _, ref := range sd.OwnerReferences {
+		if ref.UID == inference.UID {
+			return true
+		}
+	}
+	return false
+}
+
+// GetActiveDecesion returns the running decision which is applied earliest among the decisions, or nil if none is running.
+func GetActiveDecesion(decesions []melodyv1alpha2.SchedulingDecesion) *melodyv1alpha2.SchedulingDecesion {
+	var active *melodyv1alpha2.SchedulingDecesion
+	for i := range decesions {
+		sd := &decesions[i]
+		if !IsRunningSchedulingDecesion(sd) {
+			continue
+		}
+		if active == nil || (sd.Status.StartTime != nil && active.Status.StartTime != nil && sd.Status.StartTime.Before(active.Status.StartTime)) {
+			active = sd
+		}
+	}
+	return active
+}
+
+// InferenceNameForPod returns the name of the Inference which owns the serving pod.
+func InferenceNameForPod(pod *corev1.Pod) string {
+	return pod.Labels[consts.LabelInferenceName]
+}
+
+// GetTargetPodNamespace returns the namespace of the target pod of the decision, which defaults to the namespace of the
+// decision.
+func GetTargetPodNamespace(sd *melodyv1alpha2.SchedulingDecesion) string {
+	if ns := sd.Spec.Objective.TargetPod.Namespace; ns != "" {
+		return ns
+	}
+	return sd.Namespace
+}
+
+// SetRequiredNode requires the new pod to be scheduled onto the target node, and stamps the pod with the decision name
+// which steered it. The requirement is added to each node selector term of the pod itself, so the pod still satisfies
+// its own requirements.
+func SetRequiredNode(pod *corev1.Pod, nodeName, decesionName string) {
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	pod.Annotations[consts.AnnotationSchedulingDecesion] = decesionName
+
+	if pod.Spec.Affinity == nil {
+		pod.Spec.Affinity = &corev1.Affinity{}
+	}
+	if pod.Spec.Affinity.NodeAffinity == nil {
+		pod.Spec.Affinity.NodeAffinity = &corev1.NodeAffinity{}
+	}
+	affinity := pod.Spec.Affinity.NodeAffinity
+	if affinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		affinity.RequiredDuringSchedulingIgnoredDuringExecution = &corev1.NodeSelector{}
+	}
+	selector := affinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if len(selector.NodeSelectorTerms) == 0 {
+		selector.NodeSelectorTerms = []corev1.NodeSelectorTerm{{}}
+	}
+	for i := range selector.NodeSelectorTerms {
+		term := &selector.NodeSelectorTerms[i]
+		term.MatchFields = append(term.MatchFields, corev1.NodeSelectorRequirement{
+			Key:      consts.NodeNameField,
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{nodeName},
+		})
+	}
+}
+
+// IsDeploymentRolledOut returns true if all replicas of the deployment are updated and available.
+func IsDeploymentRolledOut(deploy *appsv1.Deployment) bool {
+	if deploy.Generation > deploy.Status.ObservedGeneration {
+		return false
+	}
+	replicas := int32(1)
+	if deploy.Spec.Replicas != nil {
+		replicas = *deploy.Spec.Replicas
+	}
+	return deploy.Status.UpdatedReplicas == replicas &&
+		deploy.Status.Replicas == replicas &&
+		deploy.Status.AvailableReplicas == replicas
+}
+
+// IsPodReady returns true if the pod is running and its Ready condition is true.
+func IsPodReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPodRequests returns the total resource requests of the containers of the pod.
+func GetPodRequests(spec *corev1.PodSpec) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for i := range spec.Containers {
+		AddResourceList(requests, spec.Containers[i].Resources.Requests)
+	}
+	return requests
+}
+
+// AddResourceList adds the resources of src into dst.
+func AddResourceList(dst, src corev1.ResourceList) {
+	for name, quantity := range src {
+		if value, ok := dst[name]; ok {
+			value.Add(quantity)
+			dst[name] = value
+		} else {
+			dst[name] = quantity.DeepCopy()
+		}
+	}
+}
+
+// FormatDecimal formats the value as a decimal for the status.
+func FormatDecimal(value float64) string {
+	return strconv.FormatFloat(value, 'f', 4, 64)
+}
+
+// ParseDecimal parses the decimal formatted by FormatDecimal, an invalid decimal is parsed as 0.
+func ParseDecimal(value string) float64 {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return f
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	consts "melody/controllers/const"
+)
+
+func TestSetRequiredNode(t *testing.T) {
+	onNode := corev1.NodeSelectorRequirement{Key: consts.NodeNameField, Operator: corev1.NodeSelectorOpIn, Values: []string{"edge-1"}}
+	zone := corev1.NodeSelectorRequirement{Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a"}}
+	arch := corev1.NodeSelectorRequirement{Key: "arch", Operator: corev1.NodeSelectorOpIn, Values: []string{"arm64"}}
+
+	for _, tc := range []struct {
+		name     string
+		affinity *corev1.Affinity
+		expected []corev1.NodeSelectorTerm
+	}{
+		{
+			name:     "without affinity",
+			expected: []corev1.NodeSelectorTerm{{MatchFields: []corev1.NodeSelectorRequirement{onNode}}},
+		},
+		{
+			name: "with own terms",
+			affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{MatchExpressions: []corev1.NodeSelectorRequirement{zone}},
+					{MatchExpressions: []corev1.NodeSelectorRequirement{arch}},
+				}},
+			}},
+			// Each term of the pod is kept, and also requires the node
+			expected: []corev1.NodeSelectorTerm{
+				{MatchExpressions: []corev1.NodeSelectorRequirement{zone}, MatchFields: []corev1.NodeSelectorRequirement{onNode}},
+				{MatchExpressions: []corev1.NodeSelectorRequirement{arch}, MatchFields: []corev1.NodeSelectorRequirement{onNode}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := &corev1.Pod{Spec: corev1.PodSpec{Affinity: tc.affinity}}
+			SetRequiredNode(pod, "edge-1", "move")
+			if pod.Annotations[consts.AnnotationSchedulingDecesion] != "move" {
+				t.Errorf("expected the pod to be stamped with the decision, got %v", pod.Annotations)
+			}
+			terms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+			if !reflect.DeepEqual(terms, tc.expected) {
+				t.Errorf("expected terms %+v, got %+v", tc.expected, terms)
+			}
+		})
+	}
+}
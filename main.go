@@ -19,6 +19,7 @@ package main
 import (
 	"flag"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -32,7 +33,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	melodyiov1alpha1 "melody/api/v1alpha1"
+	melodyiov1alpha2 "melody/api/v1alpha2"
 	"melody/controllers"
+	"melody/pkg/algorithm"
+	"melody/pkg/dqn"
+	"melody/pkg/monitor"
+	"melody/pkg/replay"
+	"melody/pkg/scheduler"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -45,6 +52,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(melodyiov1alpha1.AddToScheme(scheme))
+	utilruntime.Must(melodyiov1alpha2.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -61,6 +69,42 @@ func main() {
 		Development: true,
 	}
 	opts.BindFlags(flag.CommandLine)
+	schedulingOpts := controllers.NewSchedulingOptions()
+	schedulingOpts.BindFlags(flag.CommandLine)
+	policyOpts := controllers.NewPolicyOptions()
+	policyOpts.BindFlags(flag.CommandLine)
+	algorithmConfig := algorithm.NewConfig()
+	algorithmConfig.BindFlags(flag.CommandLine)
+	prometheusConfig := monitor.NewPrometheusConfig()
+	prometheusConfig.BindFlags(flag.CommandLine)
+	var metricsServerFallback bool
+	flag.BoolVar(&metricsServerFallback, "metrics-server-fallback", true,
+		"Observe the used resources from the metrics.k8s.io API of metrics-server if Prometheus is not configured or fails.")
+	var dqnModelPath string
+	var dqnReloadInterval time.Duration
+	flag.StringVar(&dqnModelPath, "dqn-model-path", "",
+		"The DQN weight file evaluated in process for the DQN algorithm, i.e. mounted from a ConfigMap. "+
+			"The DQN algorithm is called on the algorithm server if empty.")
+	flag.DurationVar(&dqnReloadInterval, "dqn-reload-interval", 30*time.Second, "The interval to reload the DQN weight file.")
+	var algorithmRegistryPath string
+	flag.StringVar(&algorithmRegistryPath, "algorithm-registry", "",
+		"The YAML file registering the scheduling algorithms by name, each with its own endpoint and parameters.")
+	var servingMetricsInterval, servingMetricsTimeout time.Duration
+	flag.DurationVar(&servingMetricsInterval, "serving-metrics-interval", 30*time.Second,
+		"The interval to scrape the metrics of the serving containers, the metrics are not scraped if 0.")
+	flag.DurationVar(&servingMetricsTimeout, "serving-metrics-timeout", 5*time.Second,
+		"The timeout of each scrape of the metrics of a serving container.")
+	var replayDir string
+	var replayMaxFileBytes int64
+	var replayMaxFiles int
+	flag.StringVar(&replayDir, "replay-dir", "",
+		"The directory the transitions of the scheduling decisions are recorded into for offline training, i.e. on a PVC. "+
+			"The transitions are not recorded if empty.")
+	flag.Int64Var(&replayMaxFileBytes, "replay-max-file-bytes", 64<<20, "The size to rotate the replay file.")
+	flag.IntVar(&replayMaxFiles, "replay-max-files", 10, "The number of rotated replay files retained, 0 retains all.")
+	var algorithmFeedback bool
+	flag.BoolVar(&algorithmFeedback, "algorithm-feedback", false,
+		"Report the feedback of the scheduling decisions to the algorithm server, once it is observed after --feedback-settle-window.")
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
@@ -78,20 +122,114 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = (&controllers.InferenceReconciler{
+	inferenceReconciler := &controllers.InferenceReconciler{
 		Client: mgr.GetClient(),
 		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	}
+	var latency controllers.LatencyObserver
+	if servingMetricsInterval > 0 {
+		if err = mgr.Add(controllers.NewServingMetricsScraper(mgr, servingMetricsInterval, servingMetricsTimeout)); err != nil {
+			setupLog.Error(err, "unable to set up serving metrics scraper")
+			os.Exit(1)
+		}
+		latency = controllers.ServingLatency{}
+	}
+	if err = inferenceReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Inference")
 		os.Exit(1)
 	}
-	if err = (&controllers.SchedulingDecesionReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	var sources []monitor.Source
+	if prometheusConfig.Endpoint != "" {
+		prometheus, err := monitor.NewPrometheusCollector(prometheusConfig)
+		if err != nil {
+			setupLog.Error(err, "unable to create Prometheus collector")
+			os.Exit(1)
+		}
+		sources = append(sources, monitor.Source{Name: monitor.SourcePrometheus, Collector: prometheus})
+	}
+	if metricsServerFallback {
+		sources = append(sources, monitor.Source{
+			Name:      monitor.SourceMetricsServer,
+			Collector: monitor.NewMetricsServerCollector(mgr.GetAPIReader()),
+		})
+	}
+	var collector monitor.Collector
+	if len(sources) > 0 {
+		names := make([]string, 0, len(sources))
+		for _, source := range sources {
+			names = append(names, source.Name)
+		}
+		setupLog.Info("monitoring the used resources", "sources", names)
+		collector = monitor.NewFallbackCollector(ctrl.Log.WithName("monitor"), sources...)
+	}
+	decesionReconciler := controllers.NewSchedulingDecesionReconciler(mgr, schedulingOpts)
+	decesionReconciler.Monitor = collector
+	decesionReconciler.Latency = latency
+	if replayDir != "" {
+		recorder, err := replay.NewRecorder(replayDir, replayMaxFileBytes, replayMaxFiles)
+		if err != nil {
+			setupLog.Error(err, "unable to create replay recorder")
+			os.Exit(1)
+		}
+		recorder.Log = ctrl.Log.WithName("replay")
+		if err = mgr.AddMetricsExtraHandler("/replay", recorder); err != nil {
+			setupLog.Error(err, "unable to set up replay export")
+			os.Exit(1)
+		}
+		decesionReconciler.Replay = recorder
+	}
+	algorithmClient, err := algorithm.NewClient(algorithmConfig)
+	if err != nil {
+		setupLog.Error(err, "unable to create algorithm client")
+		os.Exit(1)
+	}
+	if algorithmFeedback {
+		decesionReconciler.Feedback = algorithmClient
+	}
+	if err = decesionReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "SchedulingDecesion")
 		os.Exit(1)
 	}
+	policyReconciler := controllers.NewSchedulingPolicyReconciler(mgr, policyOpts, algorithmClient)
+	policyReconciler.Monitor = collector
+	policyReconciler.Latency = latency
+	if dqnModelPath != "" {
+		loader := dqn.NewLoader(dqnModelPath, dqnReloadInterval, ctrl.Log.WithName("dqn"))
+		if err = mgr.Add(loader); err != nil {
+			setupLog.Error(err, "unable to set up DQN model loader")
+			os.Exit(1)
+		}
+		if err = policyReconciler.Schedulers.Register(melodyiov1alpha2.DQNScheduling, scheduler.NewDQN(loader)); err != nil {
+			setupLog.Error(err, "unable to register DQN algorithm")
+			os.Exit(1)
+		}
+	}
+	if algorithmRegistryPath != "" {
+		registry, err := scheduler.LoadRegistryConfig(algorithmRegistryPath)
+		if err != nil {
+			setupLog.Error(err, "unable to load algorithm registry")
+			os.Exit(1)
+		}
+		if err = policyReconciler.Schedulers.RegisterAll(registry, algorithmConfig, algorithmClient); err != nil {
+			setupLog.Error(err, "unable to register algorithms")
+			os.Exit(1)
+		}
+	}
+	if err = policyReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "SchedulingPolicy")
+		os.Exit(1)
+	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&melodyiov1alpha1.Inference{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Inference")
+			os.Exit(1)
+		}
+		if err = (&melodyiov1alpha2.SchedulingDecesion{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "SchedulingDecesion")
+			os.Exit(1)
+		}
+		mgr.GetWebhookServer().Register(controllers.PodSteeringPath, controllers.NewPodSteering(mgr))
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -0,0 +1,215 @@
+package algorithm
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultEndpoint is the base URL of the algorithm service in the cluster.
+const DefaultEndpoint = "http://morphling-algorithm-server:9996"
+
+// Config configures the client of the algorithm server.
+type Config struct {
+	// Endpoint is the base URL of the algorithm server, i.e. http://morphling-algorithm-server:9996.
+	Endpoint string
+	// Timeout is the timeout of each attempt of a request.
+	Timeout time.Duration
+	// Retries is the number of retries of a request which fails with a network error or a 5xx status.
+	Retries int
+	// RetryBackoff is the backoff before the first retry, which is doubled for each retry.
+	RetryBackoff time.Duration
+	// CAFile verifies the certificate of the server. The system roots are used if empty.
+	CAFile string
+	// CertFile and KeyFile is the client certificate for mutual TLS.
+	CertFile string
+	KeyFile  string
+	// InsecureSkipVerify skips verifying the certificate of the server.
+	InsecureSkipVerify bool
+}
+
+// NewConfig returns the default config, which talks to the algorithm service in the cluster.
+func NewConfig() *Config {
+	return &Config{
+		Endpoint:     DefaultEndpoint,
+		Timeout:      10 * time.Second,
+		Retries:      3,
+		RetryBackoff: 500 * time.Millisecond,
+	}
+}
+
+// BindFlags binds the config to the command line flags.
+func (c *Config) BindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Endpoint, "algorithm-endpoint", c.Endpoint, "The base URL of the algorithm server, use https:// to enable TLS.")
+	fs.DurationVar(&c.Timeout, "algorithm-timeout", c.Timeout, "The timeout of each request to the algorithm server.")
+	fs.IntVar(&c.Retries, "algorithm-retries", c.Retries, "The number of retries of a failed request to the algorithm server.")
+	fs.DurationVar(&c.RetryBackoff, "algorithm-retry-backoff", c.RetryBackoff, "The backoff before the first retry, doubled for each retry.")
+	fs.StringVar(&c.CAFile, "algorithm-ca-file", c.CAFile, "The CA to verify the certificate of the algorithm server.")
+	fs.StringVar(&c.CertFile, "algorithm-cert-file", c.CertFile, "The client certificate for mutual TLS with the algorithm server.")
+	fs.StringVar(&c.KeyFile, "algorithm-key-file", c.KeyFile, "The client key for mutual TLS with the algorithm server.")
+	fs.BoolVar(&c.InsecureSkipVerify, "algorithm-insecure-skip-verify", c.InsecureSkipVerify,
+		"Skip verifying the certificate of the algorithm server.")
+}
+
+// TLSConfig returns the TLS config of the client, or nil if TLS is not configured.
+func (c *Config) TLSConfig() (*tls.Config, error) {
+	if !strings.HasPrefix(c.Endpoint, "https://") && c.CAFile == "" && c.CertFile == "" {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{InsecureSkipVerify: c.InsecureSkipVerify}
+	if c.CAFile != "" {
+		ca, err := ioutil.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificate is found in %s", c.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// StatusError is returned when the algorithm server responds with a non 2xx status.
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("algorithm server responded %d: %s", e.StatusCode, e.Message)
+}
+
+// retriable returns true if the request may succeed when it is retried.
+func (e *StatusError) retriable() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
+
+// Client talks to the algorithm server with the JSON over HTTP protocol.
+type Client struct {
+	config *Config
+	http   *http.Client
+}
+
+// NewClient returns a client of the algorithm server with the config.
+func NewClient(config *Config) (*Client, error) {
+	if config.Endpoint == "" {
+		return nil, errors.New("algorithm server endpoint is not specified")
+	}
+	tlsConfig, err := config.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &Client{
+		config: config,
+		http:   &http.Client{Transport: transport, Timeout: config.Timeout},
+	}, nil
+}
+
+// Schedule requests the scheduling actions for the cluster state with the algorithm of the request.
+func (c *Client) Schedule(ctx context.Context, req *ScheduleRequest) (*ScheduleResponse, error) {
+	req.APIVersion = APIVersion
+	resp := &ScheduleResponse{}
+	if err := c.do(ctx, http.MethodPost, SchedulePath, req, resp); err != nil {
+		return nil, err
+	}
+	if resp.APIVersion != APIVersion {
+		return nil, fmt.Errorf("algorithm server responded with api version %q, expected %q", resp.APIVersion, APIVersion)
+	}
+	return resp, nil
+}
+
+// ReportFeedback reports the outcome of an applied action.
+func (c *Client) ReportFeedback(ctx context.Context, feedback *FeedbackRequest) error {
+	feedback.APIVersion = APIVersion
+	return c.do(ctx, http.MethodPost, FeedbackPath, feedback, nil)
+}
+
+// Healthz returns nil if the algorithm server is serving.
+func (c *Client) Healthz(ctx context.Context) error {
+	return c.do(ctx, http.MethodGet, HealthzPath, nil, nil)
+}
+
+// do sends the request and decodes the response into out, and retries the request with exponential backoff if it fails
+// with a network error or a retriable status.
+func (c *Client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return err
+		}
+	}
+
+	backoff := c.config.RetryBackoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = c.attempt(ctx, method, path, body, out)
+		var statusErr *StatusError
+		if err == nil || (errors.As(err, &statusErr) && !statusErr.retriable()) || attempt >= c.config.Retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func (c *Client) attempt(ctx context.Context, method, path string, body []byte, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.config.Endpoint, "/")+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		errResp := &ErrorResponse{}
+		if json.Unmarshal(data, errResp) != nil || errResp.Error == "" {
+			errResp.Error = strings.TrimSpace(string(data))
+		}
+		return &StatusError{StatusCode: resp.StatusCode, Message: errResp.Error}
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,203 @@
+package algorithm_test
+
+import (
+	"context"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"melody/pkg/algorithm"
+	"melody/pkg/algorithm/stub"
+)
+
+func newTestClient(t *testing.T, endpoint string, configure func(*algorithm.Config)) *algorithm.Client {
+	t.Helper()
+	config := algorithm.NewConfig()
+	config.Endpoint = endpoint
+	config.RetryBackoff = time.Millisecond
+	if configure != nil {
+		configure(config)
+	}
+	client, err := algorithm.NewClient(config)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return client
+}
+
+func TestSchedule(t *testing.T) {
+	server := stub.NewServer(func(req *algorithm.ScheduleRequest) ([]algorithm.Action, error) {
+		return []algorithm.Action{{
+			Type:       algorithm.ActionTransition,
+			Inference:  req.State.Inferences[0].Name,
+			Namespace:  req.State.Inferences[0].Namespace,
+			TargetPod:  algorithm.ObjectReference{Name: req.State.Inferences[0].Pods[0].Name},
+			TargetNode: "edge-2",
+		}}, nil
+	})
+	defer server.Close()
+	client := newTestClient(t, server.URL, nil)
+
+	state := algorithm.ClusterState{
+		Nodes: []algorithm.NodeState{{Name: "edge-1", CPU: 0.9}, {Name: "edge-2", CPU: 0.1}},
+		Inferences: []algorithm.InferenceState{{
+			Name: "resnet", Namespace: "default", Replicas: 1,
+			Pods: []algorithm.PodState{{Name: "resnet-0", Namespace: "default", Node: "edge-1"}},
+		}},
+	}
+	resp, err := client.Schedule(context.Background(), &algorithm.ScheduleRequest{Algorithm: "DQN", State: state})
+	if err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	if len(resp.Actions) != 1 || resp.Actions[0].TargetNode != "edge-2" || resp.Actions[0].TargetPod.Name != "resnet-0" {
+		t.Errorf("unexpected actions %+v", resp.Actions)
+	}
+	requests := server.Requests()
+	if len(requests) != 1 || requests[0].APIVersion != algorithm.APIVersion || requests[0].Algorithm != "DQN" {
+		t.Errorf("unexpected requests %+v", requests)
+	}
+}
+
+func TestScheduleRetriesUnavailableServer(t *testing.T) {
+	server := stub.NewServer(nil)
+	defer server.Close()
+	server.FailNext(2)
+	client := newTestClient(t, server.URL, func(c *algorithm.Config) { c.Retries = 2 })
+
+	resp, err := client.Schedule(context.Background(), &algorithm.ScheduleRequest{})
+	if err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	if len(resp.Actions) != 0 {
+		t.Errorf("unexpected actions %+v", resp.Actions)
+	}
+}
+
+func TestScheduleGivesUpAfterRetries(t *testing.T) {
+	server := stub.NewServer(nil)
+	defer server.Close()
+	server.FailNext(3)
+	client := newTestClient(t, server.URL, func(c *algorithm.Config) { c.Retries = 2 })
+
+	_, err := client.Schedule(context.Background(), &algorithm.ScheduleRequest{})
+	var statusErr *algorithm.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503 error, got %v", err)
+	}
+	if n := len(server.Requests()); n != 0 {
+		t.Errorf("expected no request served, got %d", n)
+	}
+}
+
+func TestScheduleRetriesServerErrors(t *testing.T) {
+	calls := 0
+	server := stub.NewServer(func(req *algorithm.ScheduleRequest) ([]algorithm.Action, error) {
+		calls++
+		return nil, errors.New("boom")
+	})
+	defer server.Close()
+	client := newTestClient(t, server.URL, nil)
+
+	_, err := client.Schedule(context.Background(), &algorithm.ScheduleRequest{})
+	var statusErr *algorithm.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusInternalServerError || statusErr.Message != "boom" {
+		t.Fatalf("expected 500 error, got %v", err)
+	}
+	if calls != 4 {
+		t.Errorf("expected 4 attempts, got %d", calls)
+	}
+}
+
+func TestScheduleDoesNotRetryClientErrors(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}))
+	defer server.Close()
+	client := newTestClient(t, server.URL, nil)
+
+	_, err := client.Schedule(context.Background(), &algorithm.ScheduleRequest{})
+	var statusErr *algorithm.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusBadRequest || statusErr.Message != "bad request" {
+		t.Fatalf("expected 400 error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 attempt, got %d", calls)
+	}
+}
+
+func TestReportFeedback(t *testing.T) {
+	server := stub.NewServer(nil)
+	defer server.Close()
+	client := newTestClient(t, server.URL, nil)
+
+	if err := client.ReportFeedback(context.Background(), &algorithm.FeedbackRequest{Decision: "sd-1", Reward: 0.5}); err != nil {
+		t.Fatalf("ReportFeedback: %v", err)
+	}
+	feedbacks := server.Feedbacks()
+	if len(feedbacks) != 1 || feedbacks[0].Decision != "sd-1" || feedbacks[0].APIVersion != algorithm.APIVersion {
+		t.Errorf("unexpected feedbacks %+v", feedbacks)
+	}
+}
+
+func TestScheduleRejectsOtherAPIVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"apiVersion":"v2","actions":[]}`))
+	}))
+	defer server.Close()
+	client := newTestClient(t, server.URL, nil)
+
+	if _, err := client.Schedule(context.Background(), &algorithm.ScheduleRequest{}); err == nil {
+		t.Fatal("expected api version mismatch")
+	}
+}
+
+func TestScheduleTimeout(t *testing.T) {
+	block := make(chan struct{})
+	server := stub.NewServer(func(req *algorithm.ScheduleRequest) ([]algorithm.Action, error) {
+		<-block
+		return nil, nil
+	})
+	defer server.Close()
+	defer close(block)
+	client := newTestClient(t, server.URL, func(c *algorithm.Config) {
+		c.Timeout = 50 * time.Millisecond
+		c.Retries = 0
+	})
+
+	start := time.Now()
+	if _, err := client.Schedule(context.Background(), &algorithm.ScheduleRequest{}); err == nil {
+		t.Fatal("expected timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("request is not timed out, took %v", elapsed)
+	}
+}
+
+func TestHealthzWithTLS(t *testing.T) {
+	server := stub.NewTLSServer(nil)
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := ioutil.WriteFile(caFile, ca, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	client := newTestClient(t, server.URL, func(c *algorithm.Config) { c.CAFile = caFile })
+	if err := client.Healthz(context.Background()); err != nil {
+		t.Fatalf("Healthz: %v", err)
+	}
+
+	untrusted := newTestClient(t, server.URL, func(c *algorithm.Config) { c.Retries = 0 })
+	if err := untrusted.Healthz(context.Background()); err == nil {
+		t.Error("expected the untrusted certificate to be rejected")
+	}
+}
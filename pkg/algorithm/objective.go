@@ -0,0 +1,70 @@
+package algorithm
+
+import "math"
+
+// ObjectiveWeights weighs the objectives of the scheduling against each other. The weights are relative, an objective
+// with weight 0 is ignored.
+type ObjectiveWeights struct {
+	// CPUBalance and MemoryBalance weigh balancing the cpu and memory utilizations of the nodes.
+	CPUBalance    float64 `json:"cpuBalance"`
+	MemoryBalance float64 `json:"memoryBalance"`
+	// Latency weighs the serving latency of the Inferences.
+	Latency float64 `json:"latency"`
+	// MigrationCost weighs the cost of moving a serving pod onto another node.
+	MigrationCost float64 `json:"migrationCost"`
+}
+
+// DefaultObjectiveWeights weighs the balance and the latency equally, and ignores the migration cost.
+func DefaultObjectiveWeights() ObjectiveWeights {
+	return ObjectiveWeights{CPUBalance: 1, MemoryBalance: 1, Latency: 1}
+}
+
+// Reward rewards the reductions of the cpu and memory imbalances of the nodes, and penalizes the relative increase of
+// the latency and the pods moved onto other nodes.
+func (w ObjectiveWeights) Reward(cpuImbalanceReduction, memoryImbalanceReduction, latencyIncrease float64, migrations int) float64 {
+	return w.CPUBalance*cpuImbalanceReduction + w.MemoryBalance*memoryImbalanceReduction -
+		w.Latency*latencyIncrease - w.MigrationCost*float64(migrations)
+}
+
+// Load returns the load of the node, the mean of its cpu and memory utilizations weighted by the balance weights.
+func (w ObjectiveWeights) Load(node *NodeState) float64 {
+	if total := w.CPUBalance + w.MemoryBalance; total > 0 {
+		return (w.CPUBalance*node.CPU + w.MemoryBalance*node.Memory) / total
+	}
+	return (node.CPU + node.Memory) / 2
+}
+
+// Imbalance returns the imbalance of the utilizations of the nodes, their population standard deviation.
+func Imbalance(utilizations []float64) float64 {
+	if len(utilizations) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, u := range utilizations {
+		sum += u
+	}
+	mean := sum / float64(len(utilizations))
+	var variance float64
+	for _, u := range utilizations {
+		variance += (u - mean) * (u - mean)
+	}
+	return math.Sqrt(variance / float64(len(utilizations)))
+}
+
+// Imbalances returns the imbalances of the cpu and memory utilizations of the nodes of the state.
+func (s *ClusterState) Imbalances() (cpu, memory float64) {
+	cpus, memories := make([]float64, len(s.Nodes)), make([]float64, len(s.Nodes))
+	for i := range s.Nodes {
+		cpus[i], memories[i] = s.Nodes[i].CPU, s.Nodes[i].Memory
+	}
+	return Imbalance(cpus), Imbalance(memories)
+}
+
+// LatencyIncrease returns the increase of the latency relative to the latency before, or 0 if either latency is not
+// observed.
+func LatencyIncrease(before, after float64) float64 {
+	if before <= 0 || after <= 0 {
+		return 0
+	}
+	return (after - before) / before
+}
@@ -0,0 +1,67 @@
+package algorithm_test
+
+import (
+	"math"
+	"testing"
+
+	"melody/pkg/algorithm"
+)
+
+func TestImbalance(t *testing.T) {
+	tests := []struct {
+		name         string
+		utilizations []float64
+		want         float64
+	}{
+		{name: "no nodes"},
+		{name: "one node", utilizations: []float64{0.7}},
+		{name: "balanced", utilizations: []float64{0.4, 0.4, 0.4}},
+		{name: "imbalanced", utilizations: []float64{0.2, 0.8}, want: 0.3},
+		{name: "population", utilizations: []float64{0, 0, 0.6}, want: math.Sqrt(0.08)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := algorithm.Imbalance(tt.utilizations); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("algorithm.Imbalance(%v) = %v, want %v", tt.utilizations, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClusterStateImbalances(t *testing.T) {
+	state := &algorithm.ClusterState{Nodes: []algorithm.NodeState{{Name: "edge-1", CPU: 0.2, Memory: 0.5}, {Name: "edge-2", CPU: 0.8, Memory: 0.5}}}
+	cpu, memory := state.Imbalances()
+	if math.Abs(cpu-0.3) > 1e-9 || memory != 0 {
+		t.Errorf("Imbalances() = %v, %v, want 0.3, 0", cpu, memory)
+	}
+}
+
+func TestLatencyIncrease(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after float64
+		want          float64
+	}{
+		{name: "not observed before", after: 20},
+		{name: "not observed after", before: 20},
+		{name: "doubled", before: 10, after: 20, want: 1},
+		{name: "halved", before: 20, after: 10, want: -0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := algorithm.LatencyIncrease(tt.before, tt.after); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("algorithm.LatencyIncrease(%v, %v) = %v, want %v", tt.before, tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReward(t *testing.T) {
+	weights := algorithm.ObjectiveWeights{CPUBalance: 2, MemoryBalance: 1, Latency: 4, MigrationCost: 0.5}
+	if got, want := weights.Reward(0.1, 0.2, 0.05, 2), 2*0.1+0.2-4*0.05-0.5*2; math.Abs(got-want) > 1e-9 {
+		t.Errorf("Reward() = %v, want %v", got, want)
+	}
+	if got := (algorithm.ObjectiveWeights{}).Reward(0.1, 0.2, 0.05, 2); got != 0 {
+		t.Errorf("Reward() with zero weights = %v, want 0", got)
+	}
+}
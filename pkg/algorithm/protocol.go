@@ -0,0 +1,163 @@
+package algorithm
+
+// APIVersion is the version of the protocol between the controller and the algorithm server. The requests and responses
+// carry the version, and the endpoints are served under the version prefix, i.e. /v1/schedule.
+const APIVersion = "v1"
+
+const (
+	// SchedulePath is the endpoint to request the scheduling actions for the cluster state.
+	SchedulePath = "/" + APIVersion + "/schedule"
+	// FeedbackPath is the endpoint to report the outcome of an applied action.
+	FeedbackPath = "/" + APIVersion + "/feedback"
+	// HealthzPath is the endpoint to check the algorithm server is serving.
+	HealthzPath = "/" + APIVersion + "/healthz"
+)
+
+// ActionType is the type of a scheduling action, which matches the scheduling types of SchedulingDecesion.
+type ActionType string
+
+const (
+	ActionTransition        ActionType = "Transition"
+	ActionScaling           ActionType = "Scaling"
+	ActionTransitionScaling ActionType = "TransitionScaling"
+)
+
+// NodeState is the state of a node observed by the controller.
+type NodeState struct {
+	// Name is the name of the node.
+	Name string `json:"name"`
+	// AllocatableMilliCPU and AllocatableMemory are the allocatable resources of the node, in millicores and bytes.
+	AllocatableMilliCPU int64 `json:"allocatableMilliCPU"`
+	AllocatableMemory   int64 `json:"allocatableMemory"`
+	// CPU and Memory are the utilizations of the node, as ratios between 0 and 1. They are the used resources if the
+	// usage is monitored, and the requested resources otherwise.
+	CPU    float64 `json:"cpu"`
+	Memory float64 `json:"memory"`
+	// Network is the network throughput of the node as a ratio of its capacity, 0 if it is not monitored.
+	Network float64 `json:"network,omitempty"`
+}
+
+// PodState is a serving pod of an Inference.
+type PodState struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	UID       string `json:"uid,omitempty"`
+	// Node is the node the pod is bound to, empty if the pod is not scheduled.
+	Node string `json:"node,omitempty"`
+	// MilliCPU and Memory are the resource requests of the pod, in millicores and bytes.
+	MilliCPU int64 `json:"milliCPU,omitempty"`
+	Memory   int64 `json:"memory,omitempty"`
+	// UsageMilliCPU and UsageMemory are the resources used by the pod, in millicores and bytes, 0 if they are not
+	// monitored.
+	UsageMilliCPU int64 `json:"usageMilliCPU,omitempty"`
+	UsageMemory   int64 `json:"usageMemory,omitempty"`
+}
+
+// InferenceState is the state of an Inference observed by the controller.
+type InferenceState struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	// Replicas is the expected serving replicas of the Inference.
+	Replicas int32 `json:"replicas"`
+	// Pods are the serving pods of the Inference.
+	Pods []PodState `json:"pods,omitempty"`
+	// LatencyMilliseconds is the serving latency of the Inference, 0 if it is not observed.
+	LatencyMilliseconds float64 `json:"latencyMilliseconds,omitempty"`
+	// LatencyP99Milliseconds is the 99th percentile of the serving latency, 0 if it is not observed.
+	LatencyP99Milliseconds float64 `json:"latencyP99Milliseconds,omitempty"`
+	// RequestsPerSecond is the request rate of the serving pods, 0 if it is not observed.
+	RequestsPerSecond float64 `json:"requestsPerSecond,omitempty"`
+	// BatchUtilization is the mean size of the processed batches as a ratio of the batch size, 0 if it is not observed.
+	BatchUtilization float64 `json:"batchUtilization,omitempty"`
+}
+
+// ClusterState is the state of the cluster sent to the algorithm server.
+type ClusterState struct {
+	Nodes      []NodeState      `json:"nodes"`
+	Inferences []InferenceState `json:"inferences"`
+}
+
+// ObjectReference references a pod targeted by an action.
+type ObjectReference struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	UID       string `json:"uid,omitempty"`
+}
+
+// Action is a scheduling action returned by the algorithm server, which is turned into a SchedulingDecesion.
+type Action struct {
+	Type ActionType `json:"type"`
+	// Inference is the name of the Inference the action is applied to.
+	Inference string `json:"inference"`
+	// Namespace is the namespace of the Inference.
+	Namespace string `json:"namespace"`
+	// TargetPod is the serving pod moved by a transition.
+	TargetPod ObjectReference `json:"targetPod,omitempty"`
+	// TargetNode is the node the serving pods are moved onto by a transition.
+	TargetNode string `json:"targetNode,omitempty"`
+	// ScalingReplica is the expected replicas of the Inference set by a scaling.
+	ScalingReplica int32 `json:"scalingReplica,omitempty"`
+	// Explanation explains why the algorithm decided the action, optional.
+	Explanation *Explanation `json:"explanation,omitempty"`
+}
+
+// Explanation explains why the algorithm decided an action, so that the scheduling is not a black box.
+type Explanation struct {
+	// Candidates are the candidate nodes of the action with their scores, i.e. the Q-values of DQN, the higher the
+	// better.
+	Candidates []CandidateScore `json:"candidates,omitempty"`
+	// Features are the input features which mattered the most for the action.
+	Features []FeatureImportance `json:"features,omitempty"`
+}
+
+// CandidateScore is the score of a candidate node.
+type CandidateScore struct {
+	Node  string  `json:"node"`
+	Score float64 `json:"score"`
+}
+
+// FeatureImportance is an input feature with its importance for the action, i.e. how much the score of the action
+// changes without the feature.
+type FeatureImportance struct {
+	// Name is the name of the feature, i.e. edge-1/cpu.
+	Name       string  `json:"name"`
+	Value      float64 `json:"value"`
+	Importance float64 `json:"importance"`
+}
+
+// ScheduleRequest requests the scheduling actions for the cluster state.
+type ScheduleRequest struct {
+	APIVersion string `json:"apiVersion"`
+	// Algorithm is the scheduling algorithm to use, i.e. DQN, or the default algorithm of the server if empty.
+	Algorithm string       `json:"algorithm,omitempty"`
+	State     ClusterState `json:"state"`
+	// Vector is the state as the normalized vector of ClusterState.Vector, for the algorithms which decide on a fixed
+	// size input.
+	Vector []float64 `json:"vector,omitempty"`
+	// Weights weigh the objectives the actions are decided for.
+	Weights ObjectiveWeights `json:"weights"`
+	// Parameters are the parameters the algorithm is registered with, i.e. the discount factor of PPO.
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// ScheduleResponse returns the scheduling actions, which are empty if nothing should be changed.
+type ScheduleResponse struct {
+	APIVersion string   `json:"apiVersion"`
+	Actions    []Action `json:"actions"`
+}
+
+// FeedbackRequest reports the outcome of an applied action to the algorithm server.
+type FeedbackRequest struct {
+	APIVersion string `json:"apiVersion"`
+	// Decision is the name of the SchedulingDecesion of the action.
+	Decision string       `json:"decision"`
+	Action   Action       `json:"action"`
+	Before   ClusterState `json:"before"`
+	After    ClusterState `json:"after"`
+	Reward   float64      `json:"reward"`
+}
+
+// ErrorResponse is returned by the algorithm server with a non 2xx status.
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
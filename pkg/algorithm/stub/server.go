@@ -0,0 +1,140 @@
+// Package stub provides a local algorithm server for tests, which speaks the protocol of the algorithm package.
+package stub
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+
+	"melody/pkg/algorithm"
+)
+
+// ScheduleFunc returns the scheduling actions for the request.
+type ScheduleFunc func(req *algorithm.ScheduleRequest) ([]algorithm.Action, error)
+
+// Server is a local algorithm server. It returns the actions of the ScheduleFunc, or no action if it is nil, and
+// records the feedbacks it receives.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	schedule  ScheduleFunc
+	failures  int
+	requests  []algorithm.ScheduleRequest
+	feedbacks []algorithm.FeedbackRequest
+}
+
+// NewServer starts a local algorithm server serving plain HTTP.
+func NewServer(schedule ScheduleFunc) *Server {
+	s := &Server{schedule: schedule}
+	s.Server = httptest.NewServer(s.handler())
+	return s
+}
+
+// NewTLSServer starts a local algorithm server serving HTTPS, whose certificate is trusted by s.Client().
+func NewTLSServer(schedule ScheduleFunc) *Server {
+	s := &Server{schedule: schedule}
+	s.Server = httptest.NewTLSServer(s.handler())
+	return s
+}
+
+// FailNext makes the next n requests fail with 503 Service Unavailable.
+func (s *Server) FailNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = n
+}
+
+// Requests returns the schedule requests received.
+func (s *Server) Requests() []algorithm.ScheduleRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]algorithm.ScheduleRequest(nil), s.requests...)
+}
+
+// Feedbacks returns the feedbacks received.
+func (s *Server) Feedbacks() []algorithm.FeedbackRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]algorithm.FeedbackRequest(nil), s.feedbacks...)
+}
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(algorithm.SchedulePath, s.fail(func(w http.ResponseWriter, r *http.Request) {
+		req := &algorithm.ScheduleRequest{}
+		if !decode(w, r, req, &req.APIVersion) {
+			return
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, *req)
+		schedule := s.schedule
+		s.mu.Unlock()
+
+		resp := &algorithm.ScheduleResponse{APIVersion: algorithm.APIVersion, Actions: []algorithm.Action{}}
+		if schedule != nil {
+			actions, err := schedule(req)
+			if err != nil {
+				writeJSON(w, http.StatusInternalServerError, &algorithm.ErrorResponse{Error: err.Error()})
+				return
+			}
+			resp.Actions = append(resp.Actions, actions...)
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}))
+	mux.HandleFunc(algorithm.FeedbackPath, s.fail(func(w http.ResponseWriter, r *http.Request) {
+		req := &algorithm.FeedbackRequest{}
+		if !decode(w, r, req, &req.APIVersion) {
+			return
+		}
+		s.mu.Lock()
+		s.feedbacks = append(s.feedbacks, *req)
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	mux.HandleFunc(algorithm.HealthzPath, s.fail(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	return mux
+}
+
+// fail responds 503 Service Unavailable while there are failures left, otherwise serves the request with the handler.
+func (s *Server) fail(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		failing := s.failures > 0
+		if failing {
+			s.failures--
+		}
+		s.mu.Unlock()
+		if failing {
+			writeJSON(w, http.StatusServiceUnavailable, &algorithm.ErrorResponse{Error: "stub server is failing"})
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// decode decodes the request body, and responds 400 Bad Request if it is invalid or of another api version.
+func decode(w http.ResponseWriter, r *http.Request, req interface{}, apiVersion *string) bool {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, &algorithm.ErrorResponse{Error: "method is not allowed"})
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		writeJSON(w, http.StatusBadRequest, &algorithm.ErrorResponse{Error: err.Error()})
+		return false
+	}
+	if *apiVersion != algorithm.APIVersion {
+		writeJSON(w, http.StatusBadRequest, &algorithm.ErrorResponse{Error: "unsupported api version " + *apiVersion})
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
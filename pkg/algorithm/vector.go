@@ -0,0 +1,81 @@
+package algorithm
+
+import "sort"
+
+// NodeFeatures are the features of each node in the state vector.
+var NodeFeatures = []string{"cpu", "memory", "network"}
+
+// InferenceFeatures are the features of each Inference in the state vector.
+var InferenceFeatures = []string{"cpu", "memory"}
+
+// Vector returns the state as a vector of features between 0 and 1. The vector holds the NodeFeatures of the nodes
+// sorted by name, then the InferenceFeatures of the Inferences sorted by namespace and name, which are the resources
+// used by the pods of the Inference as ratios of the allocatable resources of the nodes. The requested resources of a
+// pod are used if its usage is not monitored.
+func (s ClusterState) Vector() []float64 {
+	nodes := make([]*NodeState, 0, len(s.Nodes))
+	var allocatableMilliCPU, allocatableMemory int64
+	for i := range s.Nodes {
+		nodes = append(nodes, &s.Nodes[i])
+		allocatableMilliCPU += s.Nodes[i].AllocatableMilliCPU
+		allocatableMemory += s.Nodes[i].AllocatableMemory
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	inferences := make([]*InferenceState, 0, len(s.Inferences))
+	for i := range s.Inferences {
+		inferences = append(inferences, &s.Inferences[i])
+	}
+	sort.Slice(inferences, func(i, j int) bool {
+		if inferences[i].Namespace != inferences[j].Namespace {
+			return inferences[i].Namespace < inferences[j].Namespace
+		}
+		return inferences[i].Name < inferences[j].Name
+	})
+
+	vector := make([]float64, 0, len(nodes)*len(NodeFeatures)+len(inferences)*len(InferenceFeatures))
+	for _, node := range nodes {
+		vector = append(vector, clamp(node.CPU), clamp(node.Memory), clamp(node.Network))
+	}
+	for _, inference := range inferences {
+		var milliCPU, memory int64
+		for i := range inference.Pods {
+			milliCPU += inference.Pods[i].UsedMilliCPU()
+			memory += inference.Pods[i].UsedMemory()
+		}
+		vector = append(vector, ratio(milliCPU, allocatableMilliCPU), ratio(memory, allocatableMemory))
+	}
+	return vector
+}
+
+// UsedMilliCPU returns the cpu used by the pod, or the requested cpu if the usage is not monitored.
+func (p *PodState) UsedMilliCPU() int64 {
+	if p.UsageMilliCPU > 0 {
+		return p.UsageMilliCPU
+	}
+	return p.MilliCPU
+}
+
+// UsedMemory returns the memory used by the pod, or the requested memory if the usage is not monitored.
+func (p *PodState) UsedMemory() int64 {
+	if p.UsageMemory > 0 {
+		return p.UsageMemory
+	}
+	return p.Memory
+}
+
+func ratio(used, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return clamp(float64(used) / float64(total))
+}
+
+func clamp(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	if x > 1 {
+		return 1
+	}
+	return x
+}
@@ -0,0 +1,85 @@
+package dqn
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+// Loader loads the model from a file, i.e. mounted from a ConfigMap or a PVC, and reloads it once the file changes.
+// The last valid model is kept if the file is missing or invalid.
+type Loader struct {
+	// Path is the file of the model.
+	Path string
+	// Interval is the interval to check the file for changes.
+	Interval time.Duration
+	Log      logr.Logger
+
+	mu    sync.RWMutex
+	data  []byte
+	model *Model
+}
+
+// NewLoader returns a loader of the model file, which checks the file for changes every interval.
+func NewLoader(path string, interval time.Duration, log logr.Logger) *Loader {
+	return &Loader{Path: path, Interval: interval, Log: log}
+}
+
+// Model returns the loaded model, or nil if no model is loaded yet.
+func (l *Loader) Model() *Model {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.model
+}
+
+// Load loads the model if the file is changed. It returns true if a new model is loaded.
+func (l *Loader) Load() (bool, error) {
+	data, err := ioutil.ReadFile(l.Path)
+	if err != nil {
+		return false, err
+	}
+	l.mu.RLock()
+	unchanged := bytes.Equal(data, l.data)
+	l.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	model, err := Parse(data)
+	if err != nil {
+		return false, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.data, l.model = data, model
+	return true, nil
+}
+
+// Start reloads the model until the context is done, which makes the loader a Runnable of the manager.
+func (l *Loader) Start(ctx context.Context) error {
+	ticker := time.NewTicker(l.Interval)
+	defer ticker.Stop()
+	for {
+		l.reload()
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (l *Loader) reload() {
+	loaded, err := l.Load()
+	if err != nil {
+		l.Log.Error(err, "Load DQN model error, the last model is kept", "path", l.Path)
+		return
+	}
+	if loaded {
+		l.Log.Info("DQN model is loaded", "path", l.Path, "maxNodes", l.Model().MaxNodes)
+	}
+}
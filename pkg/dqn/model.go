@@ -0,0 +1,117 @@
+// Package dqn evaluates exported DQN policies in process. A policy is an MLP network, whose weights are exported as
+// JSON by the training loop, see docs/dqn.md for the format.
+package dqn
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// ModelVersion is the version of the weight format.
+const ModelVersion = "v1"
+
+// FeaturesPerNode is the number of input features of each node slot: the cpu and memory utilizations of the node,
+// and the share of the serving pods of the Inference on the node.
+const FeaturesPerNode = 3
+
+// Activation is the activation function of a layer.
+type Activation string
+
+const (
+	Linear  Activation = "linear"
+	ReLU    Activation = "relu"
+	Tanh    Activation = "tanh"
+	Sigmoid Activation = "sigmoid"
+)
+
+// Layer is a fully connected layer.
+type Layer struct {
+	// Weights are the weights of the layer by output and input, i.e. Weights[o][i].
+	Weights [][]float64 `json:"weights"`
+	// Bias is the bias of each output.
+	Bias []float64 `json:"bias"`
+	// Activation is the activation function of the outputs, linear if empty.
+	Activation Activation `json:"activation,omitempty"`
+}
+
+// Model is an MLP network, which maps the features of MaxNodes node slots to the Q-values of moving the serving pod
+// onto each slot, followed by the Q-value of keeping it.
+type Model struct {
+	Version string `json:"version"`
+	// MaxNodes is the number of node slots of the network.
+	MaxNodes int     `json:"maxNodes"`
+	Layers   []Layer `json:"layers"`
+}
+
+// Parse parses the model in the weight format, and validates the shapes of its layers.
+func Parse(data []byte) (*Model, error) {
+	model := &Model{}
+	if err := json.Unmarshal(data, model); err != nil {
+		return nil, err
+	}
+	if model.Version != ModelVersion {
+		return nil, fmt.Errorf("unsupported model version %q, expected %q", model.Version, ModelVersion)
+	}
+	if model.MaxNodes <= 0 {
+		return nil, fmt.Errorf("maxNodes must be positive, got %d", model.MaxNodes)
+	}
+	if len(model.Layers) == 0 {
+		return nil, fmt.Errorf("model has no layer")
+	}
+
+	inputs := model.MaxNodes * FeaturesPerNode
+	for i, layer := range model.Layers {
+		if len(layer.Weights) == 0 || len(layer.Weights) != len(layer.Bias) {
+			return nil, fmt.Errorf("layer %d has %d outputs but %d biases", i, len(layer.Weights), len(layer.Bias))
+		}
+		for _, row := range layer.Weights {
+			if len(row) != inputs {
+				return nil, fmt.Errorf("layer %d expects %d inputs, got %d weights", i, inputs, len(row))
+			}
+		}
+		switch layer.Activation {
+		case "", Linear, ReLU, Tanh, Sigmoid:
+		default:
+			return nil, fmt.Errorf("layer %d has unsupported activation %q", i, layer.Activation)
+		}
+		inputs = len(layer.Weights)
+	}
+	if inputs != model.MaxNodes+1 {
+		return nil, fmt.Errorf("model outputs %d Q-values, expected %d", inputs, model.MaxNodes+1)
+	}
+	return model, nil
+}
+
+// Forward runs the forward pass of the network, and returns the Q-values of the actions.
+func (m *Model) Forward(input []float64) ([]float64, error) {
+	if len(input) != m.MaxNodes*FeaturesPerNode {
+		return nil, fmt.Errorf("model expects %d inputs, got %d", m.MaxNodes*FeaturesPerNode, len(input))
+	}
+	values := input
+	for _, layer := range m.Layers {
+		outputs := make([]float64, len(layer.Weights))
+		for o, row := range layer.Weights {
+			sum := layer.Bias[o]
+			for i, w := range row {
+				sum += w * values[i]
+			}
+			outputs[o] = activate(layer.Activation, sum)
+		}
+		values = outputs
+	}
+	return values, nil
+}
+
+func activate(activation Activation, x float64) float64 {
+	switch activation {
+	case ReLU:
+		return math.Max(0, x)
+	case Tanh:
+		return math.Tanh(x)
+	case Sigmoid:
+		return 1 / (1 + math.Exp(-x))
+	default:
+		return x
+	}
+}
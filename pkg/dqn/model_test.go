@@ -0,0 +1,91 @@
+package dqn
+
+import (
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-logr/logr"
+)
+
+const testModel = `{
+  "version": "v1",
+  "maxNodes": 2,
+  "layers": [
+    {"weights": [[1, 0, 0, 0, 0, 0], [0, 0, 0, 1, 0, 0]], "bias": [0, -1], "activation": "relu"},
+    {"weights": [[1, 0], [0, 1], [0.5, 0.5]], "bias": [0, 0, 0.1]}
+  ]
+}`
+
+func TestForward(t *testing.T) {
+	model, err := Parse([]byte(testModel))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := model.Forward([]float64{0.8, 0, 0, 0.5, 0, 0})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// relu(0.8) = 0.8 and relu(0.5 - 1) = 0
+	expected := []float64{0.8, 0, 0.5}
+	for i := range expected {
+		if math.Abs(q[i]-expected[i]) > 1e-9 {
+			t.Errorf("expected Q-values %v, got %v", expected, q)
+			break
+		}
+	}
+
+	if _, err := model.Forward([]float64{1}); err == nil {
+		t.Error("expected error for the wrong input size")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"version":    `{"version": "v0", "maxNodes": 1, "layers": [{"weights": [[1, 1, 1], [1, 1, 1]], "bias": [0, 0]}]}`,
+		"inputs":     `{"version": "v1", "maxNodes": 1, "layers": [{"weights": [[1, 1], [1, 1]], "bias": [0, 0]}]}`,
+		"outputs":    `{"version": "v1", "maxNodes": 1, "layers": [{"weights": [[1, 1, 1]], "bias": [0]}]}`,
+		"bias":       `{"version": "v1", "maxNodes": 1, "layers": [{"weights": [[1, 1, 1], [1, 1, 1]], "bias": [0]}]}`,
+		"activation": `{"version": "v1", "maxNodes": 1, "layers": [{"weights": [[1, 1, 1], [1, 1, 1]], "bias": [0, 0], "activation": "gelu"}]}`,
+		"layers":     `{"version": "v1", "maxNodes": 1, "layers": []}`,
+		"json":       `{`,
+	} {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestLoaderReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.json")
+	loader := NewLoader(path, 0, logr.Discard())
+	if _, err := loader.Load(); err == nil {
+		t.Fatal("expected error for the missing file")
+	}
+	if loader.Model() != nil {
+		t.Fatal("expected no model")
+	}
+
+	if err := ioutil.WriteFile(path, []byte(testModel), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err := loader.Load(); err != nil || !loaded {
+		t.Fatalf("expected the model to be loaded, got %v, %v", loaded, err)
+	}
+	if loaded, err := loader.Load(); err != nil || loaded {
+		t.Fatalf("expected the unchanged model not to be reloaded, got %v, %v", loaded, err)
+	}
+	first := loader.Model()
+
+	// An invalid model keeps the last model
+	if err := ioutil.WriteFile(path, []byte(`{"version": "v1"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loader.Load(); err == nil {
+		t.Fatal("expected error for the invalid model")
+	}
+	if loader.Model() != first {
+		t.Fatal("expected the last model to be kept")
+	}
+}
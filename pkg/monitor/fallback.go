@@ -0,0 +1,112 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"melody/pkg/algorithm"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// SourcePrometheus collects the used resources from Prometheus.
+	SourcePrometheus = "prometheus"
+	// SourceMetricsServer collects the used resources from the resource metrics API of metrics-server.
+	SourceMetricsServer = "metrics-server"
+	// SourceRequests is the requested resources, which are used when no source can collect the used resources.
+	SourceRequests = "requests"
+)
+
+// sourceGauge reports the source in use, which is 1 for the source in use and 0 for the other sources.
+var sourceGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "melody_monitor_source",
+	Help: "The source of the resources the scheduling algorithms decide on, 1 for the source in use.",
+}, []string{"source"})
+
+func init() {
+	metrics.Registry.MustRegister(sourceGauge)
+}
+
+// Source is a named source of the used resources.
+type Source struct {
+	Name      string
+	Collector Collector
+}
+
+// FallbackCollector collects the used resources from the first source which succeeds, in the order of preference, and
+// falls back to the requested resources if all the sources fail. The source in use is logged when it changes, and
+// reported by the melody_monitor_source metric.
+type FallbackCollector struct {
+	sources []Source
+	log     logr.Logger
+
+	mu     sync.Mutex
+	source string
+}
+
+// NewFallbackCollector returns a collector of the sources in the order of preference.
+func NewFallbackCollector(log logr.Logger, sources ...Source) *FallbackCollector {
+	for _, source := range sources {
+		sourceGauge.WithLabelValues(source.Name).Set(0)
+	}
+	sourceGauge.WithLabelValues(SourceRequests).Set(0)
+	return &FallbackCollector{sources: sources, log: log}
+}
+
+func (c *FallbackCollector) Collect(ctx context.Context, state *algorithm.ClusterState) error {
+	var errs []error
+	for _, source := range c.sources {
+		// A failed source may have observed a part of the state
+		observed := copyState(state)
+		if err := source.Collector.Collect(ctx, &observed); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", source.Name, err))
+			continue
+		}
+		*state = observed
+		c.use(source.Name, errs)
+		return nil
+	}
+	c.use(SourceRequests, errs)
+	return nil
+}
+
+// Source returns the source in use, which is empty before the first collection.
+func (c *FallbackCollector) Source() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.source
+}
+
+// use records the source in use, with the errors of the preferred sources which failed.
+func (c *FallbackCollector) use(source string, errs []error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if source == c.source {
+		return
+	}
+	if c.source != "" {
+		sourceGauge.WithLabelValues(c.source).Set(0)
+	}
+	sourceGauge.WithLabelValues(source).Set(1)
+	c.source = source
+	if len(errs) > 0 {
+		c.log.Info("Monitoring source changed", "source", source, "errors", fmt.Sprint(errs))
+	} else {
+		c.log.Info("Monitoring source changed", "source", source)
+	}
+}
+
+// copyState returns a copy of the state which can be observed without changing the state.
+func copyState(state *algorithm.ClusterState) algorithm.ClusterState {
+	copied := algorithm.ClusterState{
+		Nodes:      append([]algorithm.NodeState(nil), state.Nodes...),
+		Inferences: append([]algorithm.InferenceState(nil), state.Inferences...),
+	}
+	for i := range copied.Inferences {
+		copied.Inferences[i].Pods = append([]algorithm.PodState(nil), copied.Inferences[i].Pods...)
+	}
+	return copied
+}
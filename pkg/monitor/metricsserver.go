@@ -0,0 +1,119 @@
+package monitor
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"melody/pkg/algorithm"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// MetricsGroupVersion is the resource metrics API served by metrics-server.
+var MetricsGroupVersion = schema.GroupVersion{Group: "metrics.k8s.io", Version: "v1beta1"}
+
+// MetricsServerCollector collects the used resources of the nodes and the pods from the NodeMetrics and PodMetrics of
+// the resource metrics API, for the clusters which run metrics-server without Prometheus. The network of the nodes is
+// not observed.
+type MetricsServerCollector struct {
+	// Reader reads the metrics from the API server, which must not be a cached reader as the metrics can not be watched.
+	Reader client.Reader
+}
+
+// NewMetricsServerCollector returns a collector of the resource metrics API with the reader.
+func NewMetricsServerCollector(reader client.Reader) *MetricsServerCollector {
+	return &MetricsServerCollector{Reader: reader}
+}
+
+func (c *MetricsServerCollector) Collect(ctx context.Context, state *algorithm.ClusterState) error {
+	if len(state.Nodes) > 0 {
+		nodeMetrics, err := c.list(ctx, "NodeMetricsList")
+		if err != nil {
+			return err
+		}
+		usage := make(map[string]map[string]interface{}, len(nodeMetrics.Items))
+		for i := range nodeMetrics.Items {
+			item := &nodeMetrics.Items[i]
+			usage[item.GetName()], _, _ = unstructured.NestedMap(item.Object, "usage")
+		}
+		for i := range state.Nodes {
+			node := &state.Nodes[i]
+			nodeUsage, ok := usage[node.Name]
+			if !ok {
+				continue
+			}
+			if milliCPU, ok := quantity(nodeUsage, "cpu", true); ok && node.AllocatableMilliCPU > 0 {
+				node.CPU = clamp(float64(milliCPU) / float64(node.AllocatableMilliCPU))
+			}
+			if memory, ok := quantity(nodeUsage, "memory", false); ok && node.AllocatableMemory > 0 {
+				node.Memory = clamp(float64(memory) / float64(node.AllocatableMemory))
+			}
+		}
+	}
+
+	namespaces := make(map[string]bool)
+	for i := range state.Inferences {
+		for j := range state.Inferences[i].Pods {
+			namespaces[state.Inferences[i].Pods[j].Namespace] = true
+		}
+	}
+	usage := make(map[string][2]int64)
+	for namespace := range namespaces {
+		podMetrics, err := c.list(ctx, "PodMetricsList", client.InNamespace(namespace))
+		if err != nil {
+			return err
+		}
+		for i := range podMetrics.Items {
+			item := &podMetrics.Items[i]
+			containers, _, _ := unstructured.NestedSlice(item.Object, "containers")
+			var milliCPU, memory int64
+			for _, container := range containers {
+				fields, ok := container.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				containerUsage, _, _ := unstructured.NestedMap(fields, "usage")
+				cpu, _ := quantity(containerUsage, "cpu", true)
+				mem, _ := quantity(containerUsage, "memory", false)
+				milliCPU, memory = milliCPU+cpu, memory+mem
+			}
+			usage[item.GetNamespace()+"/"+item.GetName()] = [2]int64{milliCPU, memory}
+		}
+	}
+	for i := range state.Inferences {
+		for j := range state.Inferences[i].Pods {
+			pod := &state.Inferences[i].Pods[j]
+			if podUsage, ok := usage[pod.Namespace+"/"+pod.Name]; ok {
+				pod.UsageMilliCPU, pod.UsageMemory = podUsage[0], podUsage[1]
+			}
+		}
+	}
+	return nil
+}
+
+// list lists the metrics of the kind of the resource metrics API.
+func (c *MetricsServerCollector) list(ctx context.Context, kind string, opts ...client.ListOption) (*unstructured.UnstructuredList, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(MetricsGroupVersion.WithKind(kind))
+	if err := c.Reader.List(ctx, list, opts...); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// quantity parses the quantity of the resource in the usage, in millis or in units.
+func quantity(usage map[string]interface{}, name string, milli bool) (int64, bool) {
+	s, ok := usage[name].(string)
+	if !ok {
+		return 0, false
+	}
+	q, err := resource.ParseQuantity(s)
+	if err != nil {
+		return 0, false
+	}
+	if milli {
+		return q.MilliValue(), true
+	}
+	return q.Value(), true
+}
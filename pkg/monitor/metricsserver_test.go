@@ -0,0 +1,119 @@
+package monitor_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"melody/pkg/algorithm"
+	"melody/pkg/monitor"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakeMetricsReader lists the metrics of the resource metrics API by kind, and fails if the API is not installed.
+type fakeMetricsReader struct {
+	client.Reader
+	metrics map[string][]map[string]interface{}
+}
+
+func (r *fakeMetricsReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if r.metrics == nil {
+		return errors.New("no matches for kind in version metrics.k8s.io/v1beta1")
+	}
+	listOpts := &client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	u := list.(*unstructured.UnstructuredList)
+	for _, object := range r.metrics[u.GetKind()] {
+		item := unstructured.Unstructured{Object: object}
+		if listOpts.Namespace == "" || item.GetNamespace() == listOpts.Namespace {
+			u.Items = append(u.Items, item)
+		}
+	}
+	return nil
+}
+
+func newMetricsReader() *fakeMetricsReader {
+	return &fakeMetricsReader{metrics: map[string][]map[string]interface{}{
+		"NodeMetricsList": {
+			{"metadata": map[string]interface{}{"name": "edge-1"}, "usage": map[string]interface{}{"cpu": "3600m", "memory": "4Gi"}},
+		},
+		"PodMetricsList": {
+			{"metadata": map[string]interface{}{"name": "resnet-0", "namespace": "default"}, "containers": []interface{}{
+				map[string]interface{}{"name": "serving", "usage": map[string]interface{}{"cpu": "2", "memory": "384Mi"}},
+				map[string]interface{}{"name": "sidecar", "usage": map[string]interface{}{"cpu": "500m", "memory": "128Mi"}},
+			}},
+			{"metadata": map[string]interface{}{"name": "resnet-1", "namespace": "other"}, "containers": []interface{}{
+				map[string]interface{}{"name": "serving", "usage": map[string]interface{}{"cpu": "1", "memory": "1Gi"}},
+			}},
+		},
+	}}
+}
+
+func TestMetricsServerCollect(t *testing.T) {
+	state := newState()
+	if err := monitor.NewMetricsServerCollector(newMetricsReader()).Collect(context.Background(), &state); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+
+	expectedNodes := []algorithm.NodeState{
+		{Name: "edge-1", AllocatableMilliCPU: 4000, AllocatableMemory: 8 << 30, CPU: 0.9, Memory: 0.5},
+		{Name: "edge-2", AllocatableMilliCPU: 4000, AllocatableMemory: 8 << 30, CPU: 0.25, Memory: 0.25},
+	}
+	for i, expected := range expectedNodes {
+		if state.Nodes[i] != expected {
+			t.Errorf("expected node %+v, got %+v", expected, state.Nodes[i])
+		}
+	}
+	pods := state.Inferences[0].Pods
+	if pods[0].UsageMilliCPU != 2500 || pods[0].UsageMemory != 512<<20 {
+		t.Errorf("expected resnet-0 to use 2500m and 512Mi, got %dm and %d", pods[0].UsageMilliCPU, pods[0].UsageMemory)
+	}
+	if pods[1].UsageMilliCPU != 0 || pods[1].UsageMemory != 0 {
+		t.Errorf("expected resnet-1 not to be observed, got %dm and %d", pods[1].UsageMilliCPU, pods[1].UsageMemory)
+	}
+}
+
+func TestFallbackCollector(t *testing.T) {
+	server := httptest.NewServer(&fakePrometheus{})
+	defer server.Close()
+	config := monitor.NewPrometheusConfig()
+	config.Endpoint = server.URL
+	prometheus, err := monitor.NewPrometheusCollector(config)
+	if err != nil {
+		t.Fatalf("NewPrometheusCollector: %v", err)
+	}
+	reader := newMetricsReader()
+	collector := monitor.NewFallbackCollector(logr.Discard(),
+		monitor.Source{Name: monitor.SourcePrometheus, Collector: prometheus},
+		monitor.Source{Name: monitor.SourceMetricsServer, Collector: monitor.NewMetricsServerCollector(reader)},
+	)
+
+	// Prometheus fails, the resources are collected from metrics-server
+	state := newState()
+	if err := collector.Collect(context.Background(), &state); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if source := collector.Source(); source != monitor.SourceMetricsServer {
+		t.Errorf("expected source %s, got %s", monitor.SourceMetricsServer, source)
+	}
+	if state.Nodes[0].CPU != 0.9 || state.Inferences[0].Pods[0].UsageMilliCPU != 2500 {
+		t.Errorf("expected the resources of metrics-server, got %+v", state)
+	}
+
+	// Without metrics-server, the requested resources are kept
+	reader.metrics = nil
+	state = newState()
+	if err := collector.Collect(context.Background(), &state); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if source := collector.Source(); source != monitor.SourceRequests {
+		t.Errorf("expected source %s, got %s", monitor.SourceRequests, source)
+	}
+	expected := newState()
+	if state.Nodes[0] != expected.Nodes[0] || state.Inferences[0].Pods[0] != expected.Inferences[0].Pods[0] {
+		t.Errorf("expected the requested resources, got %+v", state)
+	}
+}
@@ -0,0 +1,27 @@
+// Package monitor observes the resources used by the nodes and the serving pods, and the metrics of the serving
+// runtimes, which refine the cluster state the scheduling algorithms decide on.
+package monitor
+
+import (
+	"context"
+
+	"melody/pkg/algorithm"
+)
+
+// Collector observes the used resources of the nodes and the pods of the cluster state. The nodes and the pods which
+// are not observed keep their requested resources.
+type Collector interface {
+	// Collect sets the observed utilizations of the nodes and the observed usage of the pods of the state.
+	Collect(ctx context.Context, state *algorithm.ClusterState) error
+}
+
+// clamp bounds a utilization between 0 and 1.
+func clamp(x float64) float64 {
+	if x < 0 {
+		return 0
+	}
+	if x > 1 {
+		return 1
+	}
+	return x
+}
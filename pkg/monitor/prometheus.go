@@ -0,0 +1,368 @@
+package monitor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"melody/pkg/algorithm"
+	"sigs.k8s.io/yaml"
+)
+
+// QueryPath is the endpoint of the Prometheus HTTP API to evaluate an instant query.
+const QueryPath = "/api/v1/query"
+
+// Queries are the PromQL templates of the metrics. The templates are rendered with QueryData, and evaluate to instant
+// vectors labeled by the node with NodeLabel, or by the pod with the namespace and pod labels. A metric is not
+// observed if its query is empty.
+type Queries struct {
+	// NodeLabel is the label of the node name in the results of the node queries.
+	NodeLabel string `json:"nodeLabel,omitempty"`
+	// NodeCPU and NodeMemory are the utilizations of the nodes, as ratios between 0 and 1.
+	NodeCPU    string `json:"nodeCPU,omitempty"`
+	NodeMemory string `json:"nodeMemory,omitempty"`
+	// NodeNetwork is the network throughput of the nodes, in bytes per second.
+	NodeNetwork string `json:"nodeNetwork,omitempty"`
+	// PodCPU and PodMemory are the usage of the serving pods, in cores and bytes.
+	PodCPU    string `json:"podCPU,omitempty"`
+	PodMemory string `json:"podMemory,omitempty"`
+}
+
+// DefaultQueries are the queries of the node exporter and cAdvisor metrics, of a Prometheus which labels the node
+// exporter targets by the node name in instance, i.e. kube-prometheus.
+func DefaultQueries() Queries {
+	return Queries{
+		NodeLabel:  "instance",
+		NodeCPU:    `1 - avg by (instance) (rate(node_cpu_seconds_total{mode="idle",instance=~"{{.Nodes}}"}[1m]))`,
+		NodeMemory: `1 - node_memory_MemAvailable_bytes{instance=~"{{.Nodes}}"} / node_memory_MemTotal_bytes{instance=~"{{.Nodes}}"}`,
+		NodeNetwork: `sum by (instance) (rate(node_network_receive_bytes_total{device!="lo",instance=~"{{.Nodes}}"}[1m]) + ` +
+			`rate(node_network_transmit_bytes_total{device!="lo",instance=~"{{.Nodes}}"}[1m]))`,
+		PodCPU:    `sum by (namespace, pod) (rate(container_cpu_usage_seconds_total{container!="",namespace=~"{{.Namespaces}}",pod=~"{{.Pods}}"}[1m]))`,
+		PodMemory: `sum by (namespace, pod) (container_memory_working_set_bytes{container!="",namespace=~"{{.Namespaces}}",pod=~"{{.Pods}}"})`,
+	}
+}
+
+// QueryData is the data the query templates are rendered with. The fields are regular expressions which match the
+// names of the nodes, the namespaces and the pods of the cluster state.
+type QueryData struct {
+	Nodes      string
+	Namespaces string
+	Pods       string
+}
+
+// LoadQueries loads the queries from the YAML file, the queries not set in the file are the default queries.
+func LoadQueries(path string) (Queries, error) {
+	queries := DefaultQueries()
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return queries, err
+	}
+	loaded := Queries{}
+	if err := yaml.UnmarshalStrict(data, &loaded); err != nil {
+		return queries, fmt.Errorf("invalid Prometheus queries %s: %v", path, err)
+	}
+	for _, field := range []struct{ from, to *string }{
+		{&loaded.NodeLabel, &queries.NodeLabel},
+		{&loaded.NodeCPU, &queries.NodeCPU},
+		{&loaded.NodeMemory, &queries.NodeMemory},
+		{&loaded.NodeNetwork, &queries.NodeNetwork},
+		{&loaded.PodCPU, &queries.PodCPU},
+		{&loaded.PodMemory, &queries.PodMemory},
+	} {
+		if *field.from != "" {
+			*field.to = *field.from
+		}
+	}
+	return queries, nil
+}
+
+// PrometheusConfig configures the collector of the Prometheus metrics.
+type PrometheusConfig struct {
+	// Endpoint is the base URL of Prometheus, i.e. http://prometheus-k8s.monitoring:9090. The metrics are not
+	// collected from Prometheus if empty.
+	Endpoint string
+	// Timeout is the timeout of each query.
+	Timeout time.Duration
+	// QueriesFile is the YAML file of the queries, the default queries are used if empty.
+	QueriesFile string
+	// NetworkCapacity is the network throughput of a node at full utilization, in bytes per second.
+	NetworkCapacity float64
+}
+
+// NewPrometheusConfig returns the default config, which does not collect the metrics.
+func NewPrometheusConfig() *PrometheusConfig {
+	return &PrometheusConfig{
+		Timeout:         5 * time.Second,
+		NetworkCapacity: 125e6,
+	}
+}
+
+// BindFlags binds the config to the command line flags.
+func (c *PrometheusConfig) BindFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Endpoint, "prometheus-endpoint", c.Endpoint,
+		"The base URL of Prometheus to observe the used resources, the requested resources are used if empty.")
+	fs.DurationVar(&c.Timeout, "prometheus-timeout", c.Timeout, "The timeout of each Prometheus query.")
+	fs.StringVar(&c.QueriesFile, "prometheus-queries", c.QueriesFile,
+		"The YAML file of the PromQL templates of the node and pod metrics, the default queries are used if empty.")
+	fs.Float64Var(&c.NetworkCapacity, "prometheus-network-capacity", c.NetworkCapacity,
+		"The network throughput of a node at full utilization, in bytes per second.")
+}
+
+// PrometheusCollector collects the used resources of the nodes and the pods from Prometheus.
+type PrometheusCollector struct {
+	config    *PrometheusConfig
+	nodeLabel string
+	templates map[string]*template.Template
+	http      *http.Client
+}
+
+// NewPrometheusCollector returns a collector of the Prometheus metrics with the config.
+func NewPrometheusCollector(config *PrometheusConfig) (*PrometheusCollector, error) {
+	if config.Endpoint == "" {
+		return nil, errors.New("Prometheus endpoint is not specified")
+	}
+	if config.NetworkCapacity <= 0 {
+		return nil, errors.New("network capacity must be positive")
+	}
+	queries := DefaultQueries()
+	if config.QueriesFile != "" {
+		var err error
+		if queries, err = LoadQueries(config.QueriesFile); err != nil {
+			return nil, err
+		}
+	}
+	c := &PrometheusCollector{
+		config:    config,
+		nodeLabel: queries.NodeLabel,
+		templates: make(map[string]*template.Template),
+		http:      &http.Client{Timeout: config.Timeout},
+	}
+	for name, query := range map[string]string{
+		"nodeCPU":     queries.NodeCPU,
+		"nodeMemory":  queries.NodeMemory,
+		"nodeNetwork": queries.NodeNetwork,
+		"podCPU":      queries.PodCPU,
+		"podMemory":   queries.PodMemory,
+	} {
+		if query == "" {
+			continue
+		}
+		tmpl, err := template.New(name).Option("missingkey=error").Parse(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s query: %v", name, err)
+		}
+		c.templates[name] = tmpl
+	}
+	return c, nil
+}
+
+// Collect fails if a query matches none of the nodes or the serving pods of the state, which is an unreachable or
+// misconfigured exporter rather than an idle cluster.
+func (c *PrometheusCollector) Collect(ctx context.Context, state *algorithm.ClusterState) error {
+	data := queryData(state)
+
+	nodeMetrics := make(map[string]map[string]float64)
+	for _, name := range []string{"nodeCPU", "nodeMemory", "nodeNetwork"} {
+		if len(state.Nodes) == 0 {
+			break
+		}
+		if _, ok := c.templates[name]; !ok {
+			continue
+		}
+		samples, err := c.query(ctx, name, data)
+		if err != nil {
+			return err
+		}
+		nodeMetrics[name] = make(map[string]float64, len(samples))
+		for _, sample := range samples {
+			nodeMetrics[name][sample.Metric[c.nodeLabel]] = sample.Value
+		}
+		if !observesAny(nodeMetrics[name], len(state.Nodes), func(i int) string { return state.Nodes[i].Name }) {
+			return fmt.Errorf("%s query matched none of the nodes by the label %s", name, c.nodeLabel)
+		}
+	}
+	for i := range state.Nodes {
+		node := &state.Nodes[i]
+		if v, ok := nodeMetrics["nodeCPU"][node.Name]; ok {
+			node.CPU = clamp(v)
+		}
+		if v, ok := nodeMetrics["nodeMemory"][node.Name]; ok {
+			node.Memory = clamp(v)
+		}
+		if v, ok := nodeMetrics["nodeNetwork"][node.Name]; ok {
+			node.Network = clamp(v / c.config.NetworkCapacity)
+		}
+	}
+
+	var podKeys []string
+	for i := range state.Inferences {
+		for _, pod := range state.Inferences[i].Pods {
+			podKeys = append(podKeys, pod.Namespace+"/"+pod.Name)
+		}
+	}
+	podMetrics := make(map[string]map[string]float64)
+	for _, name := range []string{"podCPU", "podMemory"} {
+		if data.Pods == "" {
+			break
+		}
+		if _, ok := c.templates[name]; !ok {
+			continue
+		}
+		samples, err := c.query(ctx, name, data)
+		if err != nil {
+			return err
+		}
+		podMetrics[name] = make(map[string]float64, len(samples))
+		for _, sample := range samples {
+			podMetrics[name][sample.Metric["namespace"]+"/"+sample.Metric["pod"]] = sample.Value
+		}
+		if !observesAny(podMetrics[name], len(podKeys), func(i int) string { return podKeys[i] }) {
+			return fmt.Errorf("%s query matched none of the serving pods", name)
+		}
+	}
+	for i := range state.Inferences {
+		for j := range state.Inferences[i].Pods {
+			pod := &state.Inferences[i].Pods[j]
+			key := pod.Namespace + "/" + pod.Name
+			if v, ok := podMetrics["podCPU"][key]; ok {
+				pod.UsageMilliCPU = int64(math.Round(v * 1000))
+			}
+			if v, ok := podMetrics["podMemory"][key]; ok {
+				pod.UsageMemory = int64(math.Round(v))
+			}
+		}
+	}
+	return nil
+}
+
+// observesAny returns true if the metrics observe any of the n keys. A query which matches no series fails the
+// collection, so the sources after Prometheus are used.
+func observesAny(metrics map[string]float64, n int, key func(int) string) bool {
+	for i := 0; i < n; i++ {
+		if _, ok := metrics[key(i)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteRegexp quotes the name as a regular expression inside a double-quoted PromQL string, which unescapes the
+// backslashes of the regular expression once.
+func quoteRegexp(name string) string {
+	return strings.ReplaceAll(regexp.QuoteMeta(name), `\`, `\\`)
+}
+
+// queryData returns the regular expressions of the nodes, the namespaces and the pods of the state.
+func queryData(state *algorithm.ClusterState) QueryData {
+	var nodes, pods []string
+	namespaces := make(map[string]bool)
+	var namespaceNames []string
+	for i := range state.Nodes {
+		nodes = append(nodes, quoteRegexp(state.Nodes[i].Name))
+	}
+	for i := range state.Inferences {
+		for j := range state.Inferences[i].Pods {
+			pod := &state.Inferences[i].Pods[j]
+			pods = append(pods, quoteRegexp(pod.Name))
+			if !namespaces[pod.Namespace] {
+				namespaces[pod.Namespace] = true
+				namespaceNames = append(namespaceNames, quoteRegexp(pod.Namespace))
+			}
+		}
+	}
+	return QueryData{
+		Nodes:      strings.Join(nodes, "|"),
+		Namespaces: strings.Join(namespaceNames, "|"),
+		Pods:       strings.Join(pods, "|"),
+	}
+}
+
+// sample is a sample of an instant vector.
+type sample struct {
+	Metric map[string]string
+	Value  float64
+}
+
+// queryResponse is the response of the query endpoint of the Prometheus HTTP API.
+type queryResponse struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Data      struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Metric map[string]string `json:"metric"`
+			// Value is the timestamp and the value of the sample, i.e. [1633072800.123, "0.42"].
+			Value [2]interface{} `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// query evaluates the query template at the current time, which is skipped if the query is not configured.
+func (c *PrometheusCollector) query(ctx context.Context, name string, data QueryData) ([]sample, error) {
+	tmpl, ok := c.templates[name]
+	if !ok {
+		return nil, nil
+	}
+	query := &bytes.Buffer{}
+	if err := tmpl.Execute(query, data); err != nil {
+		return nil, fmt.Errorf("render %s query: %v", name, err)
+	}
+
+	form := url.Values{"query": []string{query.String()}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(c.config.Endpoint, "/")+QueryPath,
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &queryResponse{}
+	if err := json.Unmarshal(body, result); err != nil {
+		return nil, fmt.Errorf("%s query responded %d: %s", name, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if result.Status != "success" {
+		return nil, fmt.Errorf("%s query failed with %s: %s", name, result.ErrorType, result.Error)
+	}
+	if result.Data.ResultType != "vector" {
+		return nil, fmt.Errorf("%s query returned %s, expected vector", name, result.Data.ResultType)
+	}
+	samples := make([]sample, 0, len(result.Data.Result))
+	for _, r := range result.Data.Result {
+		s, ok := r.Value[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("%s query returned an invalid value %v", name, r.Value[1])
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s query returned an invalid value %q", name, s)
+		}
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		samples = append(samples, sample{Metric: r.Metric, Value: v})
+	}
+	return samples, nil
+}
@@ -0,0 +1,259 @@
+package monitor_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"melody/pkg/algorithm"
+	"melody/pkg/monitor"
+)
+
+// fakePrometheus serves the query endpoint, and responds to each query with the vector of the first metric name the
+// query contains.
+type fakePrometheus struct {
+	vectors map[string][]map[string]string
+	queries []string
+}
+
+func (p *fakePrometheus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != monitor.QueryPath || r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+	query := r.FormValue("query")
+	p.queries = append(p.queries, query)
+	for metric, samples := range p.vectors {
+		if !strings.Contains(query, metric) {
+			continue
+		}
+		var result []interface{}
+		for _, s := range samples {
+			labels := make(map[string]string)
+			for k, v := range s {
+				if k != "value" {
+					labels[k] = v
+				}
+			}
+			result = append(result, map[string]interface{}{"metric": labels, "value": []interface{}{1633072800.0, s["value"]}})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"data":   map[string]interface{}{"resultType": "vector", "result": result},
+		})
+		return
+	}
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "error", "errorType": "bad_data", "error": "unknown metric"})
+}
+
+func newState() algorithm.ClusterState {
+	return algorithm.ClusterState{
+		Nodes: []algorithm.NodeState{
+			{Name: "edge-1", AllocatableMilliCPU: 4000, AllocatableMemory: 8 << 30, CPU: 0.5, Memory: 0.5},
+			{Name: "edge-2", AllocatableMilliCPU: 4000, AllocatableMemory: 8 << 30, CPU: 0.25, Memory: 0.25},
+		},
+		Inferences: []algorithm.InferenceState{{
+			Name:      "resnet",
+			Namespace: "default",
+			Replicas:  2,
+			Pods: []algorithm.PodState{
+				{Name: "resnet-0", Namespace: "default", Node: "edge-1", MilliCPU: 1000, Memory: 1 << 30},
+				{Name: "resnet-1", Namespace: "default", Node: "edge-2", MilliCPU: 1000, Memory: 1 << 30},
+			},
+		}},
+	}
+}
+
+func TestPrometheusCollect(t *testing.T) {
+	prometheus := &fakePrometheus{vectors: map[string][]map[string]string{
+		"node_cpu_seconds_total": {
+			{"instance": "edge-1", "value": "0.9"},
+			{"instance": "edge-3", "value": "0.1"},
+		},
+		"node_memory_MemAvailable_bytes": {
+			{"instance": "edge-1", "value": "0.6"},
+			{"instance": "edge-2", "value": "1.2"},
+		},
+		"node_network_receive_bytes_total": {
+			{"instance": "edge-2", "value": "62500000"},
+		},
+		"container_cpu_usage_seconds_total": {
+			{"namespace": "default", "pod": "resnet-0", "value": "2.5"},
+		},
+		"container_memory_working_set_bytes": {
+			{"namespace": "default", "pod": "resnet-0", "value": "536870912"},
+			{"namespace": "other", "pod": "resnet-1", "value": "1"},
+		},
+	}}
+	server := httptest.NewServer(prometheus)
+	defer server.Close()
+
+	config := monitor.NewPrometheusConfig()
+	config.Endpoint = server.URL
+	collector, err := monitor.NewPrometheusCollector(config)
+	if err != nil {
+		t.Fatalf("NewPrometheusCollector: %v", err)
+	}
+	state := newState()
+	if err := collector.Collect(context.Background(), &state); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+
+	if len(prometheus.queries) != 5 {
+		t.Fatalf("expected 5 queries, got %d", len(prometheus.queries))
+	}
+	if !strings.Contains(prometheus.queries[0], `instance=~"edge-1|edge-2"`) {
+		t.Errorf("expected the node query to select the nodes, got %s", prometheus.queries[0])
+	}
+	if !strings.Contains(prometheus.queries[3], `namespace=~"default",pod=~"resnet-0|resnet-1"`) {
+		t.Errorf("expected the pod query to select the pods, got %s", prometheus.queries[3])
+	}
+
+	// The nodes and the pods without samples keep their requested resources
+	expectedNodes := []algorithm.NodeState{
+		{Name: "edge-1", AllocatableMilliCPU: 4000, AllocatableMemory: 8 << 30, CPU: 0.9, Memory: 0.6},
+		{Name: "edge-2", AllocatableMilliCPU: 4000, AllocatableMemory: 8 << 30, CPU: 0.25, Memory: 1, Network: 0.5},
+	}
+	for i, expected := range expectedNodes {
+		if state.Nodes[i] != expected {
+			t.Errorf("expected node %+v, got %+v", expected, state.Nodes[i])
+		}
+	}
+	pods := state.Inferences[0].Pods
+	if pods[0].UsageMilliCPU != 2500 || pods[0].UsageMemory != 512<<20 {
+		t.Errorf("expected resnet-0 to use 2500m and 512Mi, got %dm and %d", pods[0].UsageMilliCPU, pods[0].UsageMemory)
+	}
+	if pods[1].UsageMilliCPU != 0 || pods[1].UsageMemory != 0 {
+		t.Errorf("expected resnet-1 not to be observed, got %dm and %d", pods[1].UsageMilliCPU, pods[1].UsageMemory)
+	}
+
+	// The pods of resnet use 2500m + 1000m of 8 cores, and 512Mi + 1Gi of 16Gi
+	expectedVector := []float64{0.9, 0.6, 0, 0.25, 1, 0.5, 3500.0 / 8000, 1.5 / 16}
+	vector := state.Vector()
+	if fmt.Sprint(vector) != fmt.Sprint(expectedVector) {
+		t.Errorf("expected vector %v, got %v", expectedVector, vector)
+	}
+}
+
+func TestPrometheusQueryError(t *testing.T) {
+	server := httptest.NewServer(&fakePrometheus{})
+	defer server.Close()
+
+	config := monitor.NewPrometheusConfig()
+	config.Endpoint = server.URL
+	collector, err := monitor.NewPrometheusCollector(config)
+	if err != nil {
+		t.Fatalf("NewPrometheusCollector: %v", err)
+	}
+	state := newState()
+	err = collector.Collect(context.Background(), &state)
+	if err == nil || !strings.Contains(err.Error(), "unknown metric") {
+		t.Fatalf("expected the error of Prometheus, got %v", err)
+	}
+}
+
+func TestLoadQueries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queries.yaml")
+	data := "nodeLabel: node\nnodeCPU: 'node:cpu:ratio{node=~\"{{.Nodes}}\"}'\npodMemory: ''\n"
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	queries, err := monitor.LoadQueries(path)
+	if err != nil {
+		t.Fatalf("LoadQueries: %v", err)
+	}
+	defaults := monitor.DefaultQueries()
+	if queries.NodeLabel != "node" || queries.NodeCPU != `node:cpu:ratio{node=~"{{.Nodes}}"}` {
+		t.Errorf("expected the queries of the file, got %+v", queries)
+	}
+	if queries.NodeMemory != defaults.NodeMemory || queries.PodMemory != defaults.PodMemory {
+		t.Errorf("expected the default queries which are not set, got %+v", queries)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("nodeDisk: x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := monitor.LoadQueries(path); err == nil {
+		t.Error("expected an unknown query to be rejected")
+	}
+}
+
+func TestPrometheusCollectDottedNames(t *testing.T) {
+	prometheus := &fakePrometheus{vectors: map[string][]map[string]string{
+		"node_cpu_seconds_total":           {{"instance": "edge-1.example.com", "value": "0.9"}},
+		"node_memory_MemAvailable_bytes":   {{"instance": "10.0.0.2", "value": "0.6"}},
+		"node_network_receive_bytes_total": {{"instance": "10.0.0.2", "value": "0"}},
+	}}
+	server := httptest.NewServer(prometheus)
+	defer server.Close()
+
+	config := monitor.NewPrometheusConfig()
+	config.Endpoint = server.URL
+	collector, err := monitor.NewPrometheusCollector(config)
+	if err != nil {
+		t.Fatalf("NewPrometheusCollector: %v", err)
+	}
+	state := algorithm.ClusterState{Nodes: []algorithm.NodeState{{Name: "edge-1.example.com"}, {Name: "10.0.0.2"}}}
+	if err := collector.Collect(context.Background(), &state); err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if state.Nodes[0].CPU != 0.9 || state.Nodes[1].Memory != 0.6 {
+		t.Errorf("expected the dotted nodes to be observed, got %+v", state.Nodes)
+	}
+
+	// The selector is a valid PromQL string, i.e. a Go string literal, whose regular expression matches the names
+	selector := regexp.MustCompile(`instance=~("[^"]*")`).FindStringSubmatch(prometheus.queries[0])
+	if selector == nil {
+		t.Fatalf("expected the node query to select the nodes, got %s", prometheus.queries[0])
+	}
+	unquoted, err := strconv.Unquote(selector[1])
+	if err != nil {
+		t.Fatalf("expected a valid string literal, got %s: %v", selector[1], err)
+	}
+	nodes := regexp.MustCompile("^(?:" + unquoted + ")$")
+	for _, name := range []string{"edge-1.example.com", "10.0.0.2"} {
+		if !nodes.MatchString(name) {
+			t.Errorf("expected %s to match the selector %s", name, unquoted)
+		}
+	}
+	if nodes.MatchString("edge-1xexample.com") {
+		t.Errorf("expected the dots of the selector %s to be literal", unquoted)
+	}
+}
+
+func TestPrometheusCollectNoSeries(t *testing.T) {
+	for name, vectors := range map[string]map[string][]map[string]string{
+		"empty result": {
+			"node_cpu_seconds_total": {},
+		},
+		"unknown nodes": {
+			"node_cpu_seconds_total": {{"instance": "10.0.0.9:9100", "value": "0.9"}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(&fakePrometheus{vectors: vectors})
+			defer server.Close()
+
+			config := monitor.NewPrometheusConfig()
+			config.Endpoint = server.URL
+			collector, err := monitor.NewPrometheusCollector(config)
+			if err != nil {
+				t.Fatalf("NewPrometheusCollector: %v", err)
+			}
+			state := newState()
+			err = collector.Collect(context.Background(), &state)
+			if err == nil || !strings.Contains(err.Error(), "nodeCPU query matched none of the nodes") {
+				t.Fatalf("expected the query matching no series to fail, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// ServingFormat is the format of the metrics of a serving runtime.
+type ServingFormat string
+
+const (
+	// TFServingFormat is the Prometheus metrics of TF-Serving, served with a monitoring config which enables them.
+	TFServingFormat ServingFormat = "TFServing"
+	// TritonFormat is the Prometheus metrics of Triton, with the latency summaries enabled for the latency.
+	TritonFormat ServingFormat = "Triton"
+)
+
+const (
+	tfServingRequests  = ":tensorflow:serving:request_count"
+	tfServingLatency   = ":tensorflow:serving:request_latency"
+	tfServingBatchSize = ":tensorflow:serving:batching_session:processed_batch_size"
+	tritonRequests     = "nv_inference_request_success"
+	tritonLatency      = "nv_inference_request_summary_us"
+	tritonInferences   = "nv_inference_count"
+	tritonExecutions   = "nv_inference_exec_count"
+)
+
+// staleScrape is the age after which the last scrape of a target is forgotten.
+const staleScrape = time.Hour
+
+// ServingTarget is the metrics endpoint of a serving container.
+type ServingTarget struct {
+	// Key identifies the container across the scrapes, i.e. the UID of the pod and the name of the container.
+	Key string
+	URL string
+}
+
+// ServingMetrics are the metrics of the serving containers of a serving over the interval since the last scrape. The
+// metrics are 0 if they are not observed, i.e. on the first scrape.
+type ServingMetrics struct {
+	// LatencyP50Milliseconds and LatencyP99Milliseconds are the median and the 99th percentile of the request latency.
+	LatencyP50Milliseconds float64
+	LatencyP99Milliseconds float64
+	// RequestsPerSecond is the request rate of all the containers.
+	RequestsPerSecond float64
+	// BatchUtilization is the mean size of the processed batches as a ratio of the batch size, between 0 and 1.
+	BatchUtilization float64
+	// Scraped is the number of the containers scraped, and Observed is the number of the containers scraped over an
+	// interval, which are the containers the metrics are observed from.
+	Scraped  int
+	Observed int
+}
+
+// servingCounters are the cumulative counters of a serving container.
+type servingCounters struct {
+	requests float64
+	// latency is the cumulative histogram of the latency, by the upper bounds in milliseconds.
+	latency map[float64]float64
+	// quantiles are the latency quantiles in milliseconds, which are not cumulative.
+	quantiles map[float64]float64
+	// batches is the number of the processed batches, and batched is the number of the requests in them.
+	batches float64
+	batched float64
+}
+
+type servingScrape struct {
+	counters *servingCounters
+	time     time.Time
+}
+
+// ServingScraper scrapes the Prometheus metrics of the serving containers, and keeps the last scrape of each container
+// to compute the metrics over the interval.
+type ServingScraper struct {
+	http *http.Client
+
+	mu   sync.Mutex
+	last map[string]servingScrape
+}
+
+// NewServingScraper returns a scraper with the timeout of each scrape.
+func NewServingScraper(timeout time.Duration) *ServingScraper {
+	return &ServingScraper{
+		http: &http.Client{Timeout: timeout},
+		last: make(map[string]servingScrape),
+	}
+}
+
+// Scrape scrapes the metrics of the serving containers in the format, whose batches hold up to batchSize requests. It
+// fails only if none of the containers is scraped.
+func (s *ServingScraper) Scrape(ctx context.Context, format ServingFormat, batchSize int32, targets []ServingTarget) (*ServingMetrics, error) {
+	if format != TFServingFormat && format != TritonFormat {
+		return nil, fmt.Errorf("unsupported serving metrics format %q", format)
+	}
+	metrics := &ServingMetrics{}
+	var errs []string
+	var requests, batches, batched, weightedP50, weightedP99, quantileRequests float64
+	latency := make(map[float64]float64)
+	for _, target := range targets {
+		counters, err := s.scrape(ctx, format, target.URL)
+		now := time.Now()
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", target.URL, err))
+			continue
+		}
+		metrics.Scraped++
+
+		s.mu.Lock()
+		last, ok := s.last[target.Key]
+		s.last[target.Key] = servingScrape{counters: counters, time: now}
+		s.mu.Unlock()
+		if !ok {
+			continue
+		}
+		metrics.Observed++
+		elapsed := now.Sub(last.time).Seconds()
+		delta := counters.delta(last.counters)
+		if elapsed > 0 {
+			metrics.RequestsPerSecond += delta.requests / elapsed
+		}
+		requests += delta.requests
+		batches, batched = batches+delta.batches, batched+delta.batched
+		for bound, count := range delta.latency {
+			latency[bound] += count
+		}
+		// The quantiles of the summaries can not be merged, they are weighted by the requests of the containers
+		if p50, ok := counters.quantiles[0.5]; ok && delta.requests > 0 {
+			weightedP50 += p50 * delta.requests
+			weightedP99 += counters.quantiles[0.99] * delta.requests
+			quantileRequests += delta.requests
+		}
+	}
+	if metrics.Scraped == 0 && len(targets) > 0 {
+		return nil, errors.New(strings.Join(errs, "; "))
+	}
+	s.forgetStale()
+
+	if len(latency) > 0 {
+		metrics.LatencyP50Milliseconds = histogramQuantile(0.5, latency)
+		metrics.LatencyP99Milliseconds = histogramQuantile(0.99, latency)
+	} else if quantileRequests > 0 {
+		metrics.LatencyP50Milliseconds = weightedP50 / quantileRequests
+		metrics.LatencyP99Milliseconds = weightedP99 / quantileRequests
+	}
+	if batches > 0 && batchSize > 0 {
+		metrics.BatchUtilization = clamp(batched / batches / float64(batchSize))
+	}
+	return metrics, nil
+}
+
+// forgetStale forgets the last scrapes of the containers which are not scraped anymore.
+func (s *ServingScraper) forgetStale() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, last := range s.last {
+		if time.Since(last.time) > staleScrape {
+			delete(s.last, key)
+		}
+	}
+}
+
+// scrape scrapes the counters of a serving container.
+func (s *ServingScraper) scrape(ctx context.Context, format ServingFormat, url string) (*servingCounters, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", string(expfmt.FmtText))
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("responded %d", resp.StatusCode)
+	}
+	parser := expfmt.TextParser{}
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	counters := &servingCounters{}
+	switch format {
+	case TFServingFormat:
+		counters.requests = sum(families[tfServingRequests])
+		counters.latency = histogram(families[tfServingLatency], 1e-3)
+		for _, m := range metricsOf(families[tfServingBatchSize]) {
+			if h := m.GetHistogram(); h != nil {
+				counters.batches += float64(h.GetSampleCount())
+				counters.batched += h.GetSampleSum()
+			}
+		}
+	case TritonFormat:
+		counters.requests = sum(families[tritonRequests])
+		counters.quantiles = quantiles(families[tritonLatency], 1e-3)
+		counters.batches = sum(families[tritonExecutions])
+		counters.batched = sum(families[tritonInferences])
+	}
+	return counters, nil
+}
+
+// delta returns the counters since the last counters, which are reset if the container restarts.
+func (c *servingCounters) delta(last *servingCounters) *servingCounters {
+	if c.requests < last.requests || c.batches < last.batches {
+		return c
+	}
+	delta := &servingCounters{
+		requests: c.requests - last.requests,
+		batches:  c.batches - last.batches,
+		batched:  c.batched - last.batched,
+		latency:  make(map[float64]float64, len(c.latency)),
+	}
+	for bound, count := range c.latency {
+		delta.latency[bound] = count - last.latency[bound]
+	}
+	return delta
+}
+
+// metricsOf returns the metrics of the family, which is nil if the family is not exposed.
+func metricsOf(family *dto.MetricFamily) []*dto.Metric {
+	if family == nil {
+		return nil
+	}
+	return family.GetMetric()
+}
+
+// sum returns the sum of the counters and the gauges of the family.
+func sum(family *dto.MetricFamily) float64 {
+	var total float64
+	for _, m := range metricsOf(family) {
+		switch {
+		case m.GetCounter() != nil:
+			total += m.GetCounter().GetValue()
+		case m.GetGauge() != nil:
+			total += m.GetGauge().GetValue()
+		case m.GetUntyped() != nil:
+			total += m.GetUntyped().GetValue()
+		}
+	}
+	return total
+}
+
+// histogram returns the cumulative histogram of the histograms of the family, with the bounds scaled by unit.
+func histogram(family *dto.MetricFamily, unit float64) map[float64]float64 {
+	buckets := make(map[float64]float64)
+	for _, m := range metricsOf(family) {
+		h := m.GetHistogram()
+		if h == nil {
+			continue
+		}
+		inf := false
+		for _, b := range h.GetBucket() {
+			inf = inf || math.IsInf(b.GetUpperBound(), 1)
+			buckets[b.GetUpperBound()*unit] += float64(b.GetCumulativeCount())
+		}
+		if !inf {
+			buckets[math.Inf(1)] += float64(h.GetSampleCount())
+		}
+	}
+	return buckets
+}
+
+// quantiles returns the quantiles of the first summary of the family, scaled by unit.
+func quantiles(family *dto.MetricFamily, unit float64) map[float64]float64 {
+	for _, m := range metricsOf(family) {
+		s := m.GetSummary()
+		if s == nil || len(s.GetQuantile()) == 0 {
+			continue
+		}
+		result := make(map[float64]float64)
+		for _, q := range s.GetQuantile() {
+			if !math.IsNaN(q.GetValue()) {
+				result[q.GetQuantile()] = q.GetValue() * unit
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// histogramQuantile estimates the quantile of the cumulative histogram by linear interpolation in the bucket of the
+// quantile, the same as histogram_quantile of PromQL.
+func histogramQuantile(q float64, buckets map[float64]float64) float64 {
+	bounds := make([]float64, 0, len(buckets))
+	for bound := range buckets {
+		bounds = append(bounds, bound)
+	}
+	sort.Float64s(bounds)
+	total := buckets[bounds[len(bounds)-1]]
+	if total <= 0 {
+		return 0
+	}
+	rank := q * total
+	var lower, lowerCount float64
+	for _, bound := range bounds {
+		count := buckets[bound]
+		if count >= rank {
+			if math.IsInf(bound, 1) || count == lowerCount {
+				return lower
+			}
+			return lower + (bound-lower)*(rank-lowerCount)/(count-lowerCount)
+		}
+		lower, lowerCount = bound, count
+	}
+	return lower
+}
@@ -0,0 +1,135 @@
+package monitor_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"melody/pkg/monitor"
+)
+
+// tfServingMetrics renders the metrics of TF-Serving after n intervals, each of which serves 100 requests in 10
+// batches of 6 requests.
+func tfServingMetrics(n int) string {
+	return fmt.Sprintf(`# TYPE :tensorflow:serving:request_count counter
+:tensorflow:serving:request_count{model_name="resnet",status="OK"} %d
+# TYPE :tensorflow:serving:request_latency histogram
+:tensorflow:serving:request_latency_bucket{API="predict",entrypoint="REST",le="1000"} %d
+:tensorflow:serving:request_latency_bucket{API="predict",entrypoint="REST",le="5000"} %d
+:tensorflow:serving:request_latency_bucket{API="predict",entrypoint="REST",le="10000"} %d
+:tensorflow:serving:request_latency_bucket{API="predict",entrypoint="REST",le="+Inf"} %d
+:tensorflow:serving:request_latency_sum{API="predict",entrypoint="REST"} %d
+:tensorflow:serving:request_latency_count{API="predict",entrypoint="REST"} %d
+# TYPE :tensorflow:serving:batching_session:processed_batch_size histogram
+:tensorflow:serving:batching_session:processed_batch_size_bucket{le="+Inf"} %d
+:tensorflow:serving:batching_session:processed_batch_size_sum %d
+:tensorflow:serving:batching_session:processed_batch_size_count %d
+`, 100*n, 50*n, 90*n, 100*n, 100*n, 300000*n, 100*n, 10*n, 60*n, 10*n)
+}
+
+// tritonMetrics renders the metrics of Triton after n intervals, each of which serves 40 requests in 10 executions.
+func tritonMetrics(n int) string {
+	return fmt.Sprintf(`# TYPE nv_inference_request_success counter
+nv_inference_request_success{model="resnet",version="1"} %d
+# TYPE nv_inference_count counter
+nv_inference_count{model="resnet",version="1"} %d
+# TYPE nv_inference_exec_count counter
+nv_inference_exec_count{model="resnet",version="1"} %d
+# TYPE nv_inference_request_summary_us summary
+nv_inference_request_summary_us{model="resnet",version="1",quantile="0.5"} 2000
+nv_inference_request_summary_us{model="resnet",version="1",quantile="0.99"} 12000
+nv_inference_request_summary_us_sum{model="resnet",version="1"} %d
+nv_inference_request_summary_us_count{model="resnet",version="1"} %d
+`, 40*n, 40*n, 10*n, 100000*n, 40*n)
+}
+
+// fakeServing serves the metrics of the current interval.
+type fakeServing struct {
+	render   func(int) string
+	interval int
+}
+
+func (s *fakeServing) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, s.render(s.interval))
+}
+
+func TestScrapeTFServing(t *testing.T) {
+	var targets []monitor.ServingTarget
+	var servings []*fakeServing
+	for i := 0; i < 2; i++ {
+		serving := &fakeServing{render: tfServingMetrics, interval: 1}
+		server := httptest.NewServer(serving)
+		defer server.Close()
+		servings = append(servings, serving)
+		targets = append(targets, monitor.ServingTarget{Key: fmt.Sprintf("pod-%d/resnet", i), URL: server.URL})
+	}
+	scraper := monitor.NewServingScraper(0)
+
+	// The first scrape observes no interval
+	metrics, err := scraper.Scrape(context.Background(), monitor.TFServingFormat, 8, targets)
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if *metrics != (monitor.ServingMetrics{Scraped: 2}) {
+		t.Errorf("expected no metrics on the first scrape, got %+v", metrics)
+	}
+
+	for _, serving := range servings {
+		serving.interval++
+	}
+	metrics, err = scraper.Scrape(context.Background(), monitor.TFServingFormat, 8, targets)
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	// Half of the requests take up to 1ms, and 99% up to 5ms + 5ms * 18 / 20
+	if metrics.LatencyP50Milliseconds != 1 || metrics.LatencyP99Milliseconds != 9.5 {
+		t.Errorf("expected p50 1ms and p99 9.5ms, got %vms and %vms", metrics.LatencyP50Milliseconds, metrics.LatencyP99Milliseconds)
+	}
+	if metrics.BatchUtilization != 0.75 {
+		t.Errorf("expected batch utilization 0.75, got %v", metrics.BatchUtilization)
+	}
+	if metrics.RequestsPerSecond <= 0 || metrics.Scraped != 2 || metrics.Observed != 2 {
+		t.Errorf("expected the requests of 2 containers, got %+v", metrics)
+	}
+
+	// A restarted container counts from 0
+	servings[0].interval = 1
+	metrics, err = scraper.Scrape(context.Background(), monitor.TFServingFormat, 8, targets)
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if metrics.LatencyP50Milliseconds != 1 || metrics.BatchUtilization != 0.75 {
+		t.Errorf("expected the metrics of the restarted container, got %+v", metrics)
+	}
+}
+
+func TestScrapeTriton(t *testing.T) {
+	serving := &fakeServing{render: tritonMetrics, interval: 1}
+	server := httptest.NewServer(serving)
+	defer server.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	defer down.Close()
+	targets := []monitor.ServingTarget{{Key: "pod-0/resnet", URL: server.URL}, {Key: "pod-1/resnet", URL: down.URL}}
+	scraper := monitor.NewServingScraper(0)
+
+	if _, err := scraper.Scrape(context.Background(), monitor.TritonFormat, 8, targets); err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	serving.interval++
+	metrics, err := scraper.Scrape(context.Background(), monitor.TritonFormat, 8, targets)
+	if err != nil {
+		t.Fatalf("Scrape: %v", err)
+	}
+	if metrics.LatencyP50Milliseconds != 2 || metrics.LatencyP99Milliseconds != 12 {
+		t.Errorf("expected p50 2ms and p99 12ms, got %vms and %vms", metrics.LatencyP50Milliseconds, metrics.LatencyP99Milliseconds)
+	}
+	if metrics.BatchUtilization != 0.5 || metrics.Observed != 1 {
+		t.Errorf("expected batch utilization 0.5 of 1 container, got %+v", metrics)
+	}
+
+	if _, err := scraper.Scrape(context.Background(), monitor.TritonFormat, 8, targets[1:]); err == nil {
+		t.Error("expected an error if no container is scraped")
+	}
+}
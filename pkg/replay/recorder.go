@@ -0,0 +1,337 @@
+// Package replay records the experience of the scheduling decisions as transitions for offline training. The
+// transitions are appended to JSONL files, which are rotated by size, and exported over HTTP.
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"melody/pkg/algorithm"
+)
+
+const (
+	// currentFile is the file the transitions are appended to.
+	currentFile = "transitions.jsonl"
+	// rotatedPrefix is the prefix of the rotated files, which are suffixed by the rotation time.
+	rotatedPrefix = "transitions-"
+	// pendingDir holds the transitions waiting for their rewards.
+	pendingDir = "pending"
+)
+
+// Transition is the experience of a scheduling step, the action taken in the state, and the reward and the next state
+// observed once the cluster settles.
+type Transition struct {
+	// Decision is the namespaced name of the SchedulingDecesion of the action.
+	Decision string `json:"decision"`
+	// Algorithm is the algorithm which produced the action.
+	Algorithm string                 `json:"algorithm,omitempty"`
+	Time      time.Time              `json:"time"`
+	State     algorithm.ClusterState `json:"state"`
+	Action    algorithm.Action       `json:"action"`
+	// Reward and NextState are observed once the cluster settles after the action.
+	Reward    float64                `json:"reward"`
+	NextState algorithm.ClusterState `json:"nextState"`
+	// CompletionTime is the time the reward is observed.
+	CompletionTime time.Time `json:"completionTime"`
+}
+
+// Recorder records the transitions into a directory, i.e. on a PVC.
+type Recorder struct {
+	// Dir is the directory of the files.
+	Dir string
+	// MaxFileBytes is the size to rotate the current file.
+	MaxFileBytes int64
+	// MaxFiles is the number of rotated files retained. Zero retains all.
+	MaxFiles int
+	// Log logs the exports which fail once the transitions are partially sent.
+	Log logr.Logger
+
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// NewRecorder returns a recorder into the directory, which is created if it does not exist.
+func NewRecorder(dir string, maxFileBytes int64, maxFiles int) (*Recorder, error) {
+	if err := os.MkdirAll(filepath.Join(dir, pendingDir), 0755); err != nil {
+		return nil, err
+	}
+	return &Recorder{Dir: dir, MaxFileBytes: maxFileBytes, MaxFiles: maxFiles, Log: logr.Discard(), now: time.Now}, nil
+}
+
+// Begin records the state and the action of the decision, which waits for its reward. The pending transition is kept
+// on disk, so that it survives restarts.
+func (r *Recorder) Begin(decision, algorithmName string, state algorithm.ClusterState, action algorithm.Action) error {
+	transition := &Transition{Decision: decision, Algorithm: algorithmName, Time: r.now(), State: state, Action: action}
+	data, err := json.Marshal(transition)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(r.pendingPath(decision), data)
+}
+
+// Complete records the reward and the next state of the pending transition of the decision, and appends the
+// transition. It does nothing if the decision has no pending transition.
+func (r *Recorder) Complete(decision string, reward float64, nextState algorithm.ClusterState) error {
+	path := r.pendingPath(decision)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	transition := &Transition{}
+	if err := json.Unmarshal(data, transition); err != nil {
+		return err
+	}
+	transition.Reward = reward
+	transition.NextState = nextState
+	transition.CompletionTime = r.now()
+	if err := r.append(transition); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// Discard drops the pending transition of the decision, which is never rewarded.
+func (r *Recorder) Discard(decision string) error {
+	if err := os.Remove(r.pendingPath(decision)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Pending returns the decisions whose transitions wait for their rewards.
+func (r *Recorder) Pending() ([]string, error) {
+	entries, err := ioutil.ReadDir(filepath.Join(r.Dir, pendingDir))
+	if err != nil {
+		return nil, err
+	}
+	var decisions []string
+	for _, entry := range entries {
+		if name := entry.Name(); !entry.IsDir() && strings.HasSuffix(name, ".json") {
+			decisions = append(decisions, strings.ReplaceAll(strings.TrimSuffix(name, ".json"), "_", "/"))
+		}
+	}
+	return decisions, nil
+}
+
+// pendingPath returns the file of the pending transition of the decision. The namespaced names of the decisions never
+// contain "_", so the decision is restored from the file name.
+func (r *Recorder) pendingPath(decision string) string {
+	return filepath.Join(r.Dir, pendingDir, strings.ReplaceAll(decision, "/", "_")+".json")
+}
+
+// append appends the transition to the current file, which is rotated once it exceeds the max size.
+func (r *Recorder) append(transition *Transition) error {
+	data, err := json.Marshal(transition)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	path := filepath.Join(r.Dir, currentFile)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if r.MaxFileBytes > 0 && info.Size() >= r.MaxFileBytes {
+		return r.rotate()
+	}
+	return nil
+}
+
+// rotate renames the current file by the rotation time, and deletes the oldest rotated files beyond the max files.
+func (r *Recorder) rotate() error {
+	rotated := fmt.Sprintf("%s%s.jsonl", rotatedPrefix, r.now().UTC().Format("20060102T150405.000000000"))
+	if err := os.Rename(filepath.Join(r.Dir, currentFile), filepath.Join(r.Dir, rotated)); err != nil {
+		return err
+	}
+	if r.MaxFiles <= 0 {
+		return nil
+	}
+	files, err := r.rotatedFiles()
+	if err != nil {
+		return err
+	}
+	for len(files) > r.MaxFiles {
+		if err := os.Remove(filepath.Join(r.Dir, files[0])); err != nil {
+			return err
+		}
+		files = files[1:]
+	}
+	return nil
+}
+
+// rotatedFiles returns the rotated files from the oldest.
+func (r *Recorder) rotatedFiles() ([]string, error) {
+	entries, err := ioutil.ReadDir(r.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		if name := entry.Name(); !entry.IsDir() && strings.HasPrefix(name, rotatedPrefix) && strings.HasSuffix(name, ".jsonl") {
+			files = append(files, name)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Export writes the recorded transitions from the oldest as JSONL. Only the transitions completed after since are
+// written, unless since is zero. The transitions recorded while exporting are not written.
+func (r *Recorder) Export(w io.Writer, since time.Time) error {
+	files, err := r.openFiles()
+	if err != nil {
+		return err
+	}
+	defer closeFiles(files)
+	for _, file := range files {
+		if err := exportFile(w, file, since); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// snapshotFile is a recorded file opened for export, which is read up to its size when it is opened.
+type snapshotFile struct {
+	*os.File
+	size int64
+}
+
+// openFiles opens the recorded files from the oldest and snapshots their sizes. The files are exported from the open
+// files without the lock, as they survive the rotation and the deletion of the files, and the transitions appended
+// after the snapshot are beyond the sizes.
+func (r *Recorder) openFiles() ([]snapshotFile, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	names, err := r.rotatedFiles()
+	if err != nil {
+		return nil, err
+	}
+	var files []snapshotFile
+	for _, name := range append(names, currentFile) {
+		file, err := openSnapshot(filepath.Join(r.Dir, name))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			closeFiles(files)
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+func openSnapshot(path string) (snapshotFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return snapshotFile{}, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return snapshotFile{}, err
+	}
+	return snapshotFile{File: f, size: info.Size()}, nil
+}
+
+func closeFiles(files []snapshotFile) {
+	for _, file := range files {
+		file.Close()
+	}
+}
+
+func exportFile(w io.Writer, file snapshotFile, since time.Time) error {
+	scanner := bufio.NewScanner(io.LimitReader(file, file.size))
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !since.IsZero() {
+			transition := &Transition{}
+			if err := json.Unmarshal(line, transition); err != nil || !transition.CompletionTime.After(since) {
+				continue
+			}
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// ServeHTTP exports the transitions as JSONL, i.e. `curl <manager>/replay?since=2022-01-01T00:00:00Z`.
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		http.Error(w, "method is not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	var since time.Time
+	if value := req.URL.Query().Get("since"); value != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, value); err != nil {
+			http.Error(w, fmt.Sprintf("invalid since %q: %v", value, err), http.StatusBadRequest)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	body := &responseBody{Writer: w}
+	if err := r.Export(body, since); err != nil {
+		if !body.written {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// The status is already sent, abort the response so that the client does not take it as complete
+		r.Log.Error(err, "Export transitions error")
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// responseBody records whether the body of the response is written, which sends the status.
+type responseBody struct {
+	io.Writer
+	written bool
+}
+
+func (b *responseBody) Write(p []byte) (int, error) {
+	b.written = true
+	return b.Writer.Write(p)
+}
+
+// writeFileAtomic writes the file through a temporary file, so that a crash never leaves a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,239 @@
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"melody/pkg/algorithm"
+)
+
+func newTestRecorder(t *testing.T, maxFileBytes int64, maxFiles int) (*Recorder, *time.Time) {
+	r, err := NewRecorder(t.TempDir(), maxFileBytes, maxFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return now }
+	return r, &now
+}
+
+func record(t *testing.T, r *Recorder, decision string, reward float64) {
+	state := algorithm.ClusterState{Nodes: []algorithm.NodeState{{Name: "edge-1"}}}
+	action := algorithm.Action{Type: algorithm.ActionTransition, Inference: "resnet", Namespace: "default", TargetNode: "edge-2"}
+	if err := r.Begin(decision, "DQN", state, action); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Complete(decision, reward, state); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTransitions(t *testing.T, data []byte) []Transition {
+	var transitions []Transition
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		transition := Transition{}
+		if err := json.Unmarshal(scanner.Bytes(), &transition); err != nil {
+			t.Fatal(err)
+		}
+		transitions = append(transitions, transition)
+	}
+	return transitions
+}
+
+func TestRecordTransition(t *testing.T) {
+	r, _ := newTestRecorder(t, 0, 0)
+	record(t, r, "default/resnet-1", 0.5)
+
+	// Complete without a pending transition, and discard, are no-ops
+	if err := r.Complete("default/resnet-2", 1, algorithm.ClusterState{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Begin("default/resnet-3", "DQN", algorithm.ClusterState{}, algorithm.Action{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Discard("default/resnet-3"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Discard("default/resnet-3"); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := r.Export(buf, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	transitions := readTransitions(t, buf.Bytes())
+	if len(transitions) != 1 {
+		t.Fatalf("expected 1 transition, got %d", len(transitions))
+	}
+	transition := transitions[0]
+	if transition.Decision != "default/resnet-1" || transition.Algorithm != "DQN" || transition.Reward != 0.5 ||
+		transition.Action.TargetNode != "edge-2" || len(transition.NextState.Nodes) != 1 {
+		t.Errorf("unexpected transition %+v", transition)
+	}
+	pending, err := ioutil.ReadDir(filepath.Join(r.Dir, pendingDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Errorf("expected no pending transitions, got %d", len(pending))
+	}
+}
+
+func TestRotation(t *testing.T) {
+	r, now := newTestRecorder(t, 1, 2)
+	for i, decision := range []string{"default/a", "default/b", "default/c", "default/d"} {
+		*now = now.Add(time.Duration(i) * time.Second)
+		record(t, r, decision, float64(i))
+	}
+
+	files, err := r.rotatedFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("expected 2 rotated files, got %v", files)
+	}
+	if _, err := os.Stat(filepath.Join(r.Dir, currentFile)); !os.IsNotExist(err) {
+		t.Errorf("expected the current file to be rotated, got %v", err)
+	}
+
+	buf := &bytes.Buffer{}
+	if err := r.Export(buf, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	transitions := readTransitions(t, buf.Bytes())
+	if len(transitions) != 2 || transitions[0].Decision != "default/c" || transitions[1].Decision != "default/d" {
+		t.Errorf("expected the latest transitions from the oldest, got %+v", transitions)
+	}
+}
+
+// recordingWriter records a transition on every write, as a decision completed while the transitions are exported.
+type recordingWriter struct {
+	bytes.Buffer
+	t      *testing.T
+	r      *Recorder
+	writes int
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	record(w.t, w.r, fmt.Sprintf("default/during-%d", w.writes), 0)
+	return w.Buffer.Write(p)
+}
+
+func TestExportWhileRecording(t *testing.T) {
+	// Every transition rotates the current file, and the oldest rotated files are deleted while exporting
+	r, now := newTestRecorder(t, 1, 2)
+	for i, decision := range []string{"default/a", "default/b"} {
+		*now = now.Add(time.Duration(i) * time.Second)
+		record(t, r, decision, float64(i))
+	}
+
+	w := &recordingWriter{t: t, r: r}
+	if err := r.Export(w, time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	transitions := readTransitions(t, w.Bytes())
+	if len(transitions) != 2 || transitions[0].Decision != "default/a" || transitions[1].Decision != "default/b" {
+		t.Errorf("expected only the transitions recorded before the export, got %+v", transitions)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	r, now := newTestRecorder(t, 0, 0)
+	record(t, r, "default/a", 1)
+	since := *now
+	*now = now.Add(time.Minute)
+	record(t, r, "default/b", 2)
+
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "?since=" + since.Format(time.RFC3339))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+	transitions := readTransitions(t, data)
+	if len(transitions) != 1 || transitions[0].Decision != "default/b" {
+		t.Errorf("expected the transitions after since, got %+v", transitions)
+	}
+
+	resp, err = http.Get(server.URL + "?since=yesterday")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400, got %d", resp.StatusCode)
+	}
+}
+
+func TestServeHTTPExportError(t *testing.T) {
+	r, _ := newTestRecorder(t, 0, 0)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	// The current file can not be read, which fails before anything is written
+	if err := os.Mkdir(filepath.Join(r.Dir, currentFile), 0755); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("expected status 500, got %d", resp.StatusCode)
+	}
+
+	// The rotated file is written before the current file fails, which aborts the response
+	rotated := filepath.Join(r.Dir, rotatedPrefix+"20220101T000000.000000000.jsonl")
+	if err := ioutil.WriteFile(rotated, []byte(`{"decision":"default/a"}`+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	resp, err = http.Get(server.URL)
+	if err == nil {
+		_, err = ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+	}
+	if err == nil {
+		t.Errorf("expected the partial response to be aborted, got status %d", resp.StatusCode)
+	}
+}
+
+func TestPending(t *testing.T) {
+	r, _ := newTestRecorder(t, 0, 0)
+	for _, decision := range []string{"default/a", "kube-system/b"} {
+		if err := r.Begin(decision, "DQN", algorithm.ClusterState{}, algorithm.Action{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	record(t, r, "default/c", 1)
+
+	pending, err := r.Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 || pending[0] != "default/a" || pending[1] != "kube-system/b" {
+		t.Errorf("expected the decisions waiting for their rewards, got %v", pending)
+	}
+}
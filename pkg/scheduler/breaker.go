@@ -0,0 +1,128 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	melodyv1alpha2 "melody/api/v1alpha2"
+	"melody/pkg/algorithm"
+)
+
+// CircuitBreaker calls the primary scheduler, and falls back to the fallback scheduler when the primary fails or is
+// too slow. Once the primary fails FailureThreshold times in a row, the circuit opens and the primary is not called
+// for OpenDuration. Then the primary is tried again, which closes the circuit if it succeeds. A primary which is a
+// HealthChecker is checked before it is tried again, and the circuit stays open while it is not healthy.
+type CircuitBreaker struct {
+	Primary          Scheduler
+	PrimaryAlgorithm melodyv1alpha2.SchedulingAlgorithm
+	Fallback         Scheduler
+	// FallbackAlgorithm is recorded on the decisions of the fallback.
+	FallbackAlgorithm melodyv1alpha2.SchedulingAlgorithm
+	// FailureThreshold is the number of failures in a row to open the circuit.
+	FailureThreshold int
+	// OpenDuration is how long the circuit stays open before the primary is tried again.
+	OpenDuration time.Duration
+	// Timeout is the timeout of a call to the primary, no timeout if zero.
+	Timeout time.Duration
+
+	mu       sync.Mutex
+	failures int
+	openedAt time.Time
+	now      func() time.Time
+}
+
+// NewCircuitBreaker returns a circuit breaker of the primary with the fallback.
+func NewCircuitBreaker(primary Scheduler, primaryAlgorithm melodyv1alpha2.SchedulingAlgorithm, fallback Scheduler,
+	fallbackAlgorithm melodyv1alpha2.SchedulingAlgorithm, failureThreshold int, openDuration, timeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		Primary:           primary,
+		PrimaryAlgorithm:  primaryAlgorithm,
+		Fallback:          fallback,
+		FallbackAlgorithm: fallbackAlgorithm,
+		FailureThreshold:  failureThreshold,
+		OpenDuration:      openDuration,
+		Timeout:           timeout,
+		now:               time.Now,
+	}
+}
+
+// Schedule returns the decisions of the primary, or of the fallback if the circuit is open or the primary fails. The
+// algorithm which produces each decision is recorded on the decision.
+func (b *CircuitBreaker) Schedule(ctx context.Context, state algorithm.ClusterState, weights algorithm.ObjectiveWeights) ([]Decision, error) {
+	decisions, _, err := b.ScheduleWithFallback(ctx, state, weights)
+	return decisions, err
+}
+
+// ScheduleWithFallback is Schedule, which also returns why the call fell back, or empty if the primary produced the
+// decisions. The lock is only held to read and update the circuit, not across the calls to the schedulers, so that a
+// slow primary does not block the concurrent calls.
+func (b *CircuitBreaker) ScheduleWithFallback(ctx context.Context, state algorithm.ClusterState, weights algorithm.ObjectiveWeights) ([]Decision, string, error) {
+	b.mu.Lock()
+	open, halfOpen, failures := b.isOpen(), !b.openedAt.IsZero(), b.failures
+	b.mu.Unlock()
+	if open {
+		reason := fmt.Sprintf("Circuit of algorithm %s is open after %d failures", b.PrimaryAlgorithm, failures)
+		decisions, err := b.fallback(ctx, state, weights, reason)
+		return decisions, reason, err
+	}
+
+	primaryCtx := ctx
+	if b.Timeout > 0 {
+		var cancel context.CancelFunc
+		primaryCtx, cancel = context.WithTimeout(ctx, b.Timeout)
+		defer cancel()
+	}
+	if checker, ok := b.Primary.(HealthChecker); ok && halfOpen {
+		if err := checker.Healthz(primaryCtx); err != nil {
+			b.mu.Lock()
+			b.openedAt = b.now()
+			b.mu.Unlock()
+			reason := fmt.Sprintf("Algorithm %s is not healthy: %v", b.PrimaryAlgorithm, err)
+			decisions, err := b.fallback(ctx, state, weights, reason)
+			return decisions, reason, err
+		}
+	}
+	decisions, err := b.Primary.Schedule(primaryCtx, state, weights)
+
+	b.mu.Lock()
+	if err == nil {
+		b.failures = 0
+		b.openedAt = time.Time{}
+		b.mu.Unlock()
+		return withAlgorithm(decisions, b.PrimaryAlgorithm), "", nil
+	}
+	b.failures++
+	if b.failures >= b.FailureThreshold {
+		b.openedAt = b.now()
+	}
+	b.mu.Unlock()
+
+	reason := fmt.Sprintf("Algorithm %s failed: %v", b.PrimaryAlgorithm, err)
+	decisions, err = b.fallback(ctx, state, weights, reason)
+	return decisions, reason, err
+}
+
+// isOpen returns true if the primary is not called, the circuit is half open once the open duration elapses.
+func (b *CircuitBreaker) isOpen() bool {
+	return !b.openedAt.IsZero() && b.now().Sub(b.openedAt) < b.OpenDuration
+}
+
+func (b *CircuitBreaker) fallback(ctx context.Context, state algorithm.ClusterState, weights algorithm.ObjectiveWeights, reason string) ([]Decision, error) {
+	decisions, err := b.Fallback.Schedule(ctx, state, weights)
+	if err != nil {
+		return nil, fmt.Errorf("%s, and fallback algorithm %s failed: %v", reason, b.FallbackAlgorithm, err)
+	}
+	return withAlgorithm(decisions, b.FallbackAlgorithm), nil
+}
+
+// withAlgorithm records the algorithm on the decisions without an algorithm.
+func withAlgorithm(decisions []Decision, algorithm melodyv1alpha2.SchedulingAlgorithm) []Decision {
+	for i := range decisions {
+		if decisions[i].Algorithm == "" {
+			decisions[i].Algorithm = algorithm
+		}
+	}
+	return decisions
+}
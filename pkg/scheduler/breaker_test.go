@@ -0,0 +1,185 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	melodyv1alpha2 "melody/api/v1alpha2"
+	"melody/pkg/algorithm"
+)
+
+// fakeScheduler returns the decision for the pod, or the error.
+type fakeScheduler struct {
+	pod   string
+	err   error
+	calls int
+}
+
+func (s *fakeScheduler) Schedule(ctx context.Context, state algorithm.ClusterState, weights algorithm.ObjectiveWeights) ([]Decision, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return []Decision{{Objective: melodyv1alpha2.SchedulingObjective{TargetPod: melodyv1alpha2.ObjectReference{Name: s.pod}}}}, nil
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	primary := &fakeScheduler{pod: "primary", err: errors.New("unreachable")}
+	fallback := &fakeScheduler{pod: "fallback"}
+	now := time.Now()
+	breaker := NewCircuitBreaker(primary, melodyv1alpha2.DQNScheduling, fallback, melodyv1alpha2.DefaultScheduling, 2, time.Minute, 0)
+	breaker.now = func() time.Time { return now }
+
+	schedule := func(expected melodyv1alpha2.SchedulingAlgorithm) string {
+		t.Helper()
+		decisions, reason, err := breaker.ScheduleWithFallback(context.Background(), algorithm.ClusterState{}, algorithm.DefaultObjectiveWeights())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(decisions) != 1 || decisions[0].Algorithm != expected {
+			t.Fatalf("expected decision of %s, got %+v", expected, decisions)
+		}
+		return reason
+	}
+
+	// The failures fall back, and open the circuit once they reach the threshold
+	schedule(melodyv1alpha2.DefaultScheduling)
+	if reason := schedule(melodyv1alpha2.DefaultScheduling); primary.calls != 2 || reason == "" {
+		t.Fatalf("expected 2 calls with fallback, got %d, %q", primary.calls, reason)
+	}
+
+	// The primary is not called while the circuit is open
+	if reason := schedule(melodyv1alpha2.DefaultScheduling); primary.calls != 2 || reason == "" {
+		t.Fatalf("expected the open circuit to skip the primary, got %d calls, %q", primary.calls, reason)
+	}
+
+	// The primary is tried again once the circuit is half open, and closes the circuit once it succeeds
+	now = now.Add(2 * time.Minute)
+	primary.err = nil
+	if reason := schedule(melodyv1alpha2.DQNScheduling); primary.calls != 3 || reason != "" {
+		t.Fatalf("expected the circuit to be closed, got %d calls, %q", primary.calls, reason)
+	}
+}
+
+// healthCheckedScheduler is a fakeScheduler whose backend is checked by Healthz.
+type healthCheckedScheduler struct {
+	fakeScheduler
+	healthErr error
+	checks    int
+}
+
+func (s *healthCheckedScheduler) Healthz(ctx context.Context) error {
+	s.checks++
+	return s.healthErr
+}
+
+func TestCircuitBreakerChecksHealthWhenHalfOpen(t *testing.T) {
+	primary := &healthCheckedScheduler{fakeScheduler: fakeScheduler{pod: "primary", err: errors.New("unreachable")}}
+	fallback := &fakeScheduler{pod: "fallback"}
+	now := time.Now()
+	breaker := NewCircuitBreaker(primary, melodyv1alpha2.DQNScheduling, fallback, melodyv1alpha2.DefaultScheduling, 1, time.Minute, 0)
+	breaker.now = func() time.Time { return now }
+	schedule := func() {
+		t.Helper()
+		if _, _, err := breaker.ScheduleWithFallback(context.Background(), algorithm.ClusterState{}, algorithm.DefaultObjectiveWeights()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The health is not checked while the circuit is closed
+	schedule()
+	if primary.calls != 1 || primary.checks != 0 {
+		t.Fatalf("expected 1 call without checks, got %d calls, %d checks", primary.calls, primary.checks)
+	}
+
+	// An unhealthy primary is not called once the circuit is half open, and the circuit is opened again
+	now = now.Add(2 * time.Minute)
+	primary.healthErr = errors.New("not serving")
+	schedule()
+	if primary.calls != 1 || primary.checks != 1 {
+		t.Fatalf("expected the unhealthy primary to be skipped, got %d calls, %d checks", primary.calls, primary.checks)
+	}
+	schedule()
+	if primary.checks != 1 {
+		t.Fatalf("expected the circuit to be opened again, got %d checks", primary.checks)
+	}
+
+	// A healthy primary is called once the circuit is half open again
+	now = now.Add(2 * time.Minute)
+	primary.healthErr, primary.err = nil, nil
+	schedule()
+	if primary.calls != 2 || primary.checks != 2 {
+		t.Fatalf("expected the healthy primary to be called, got %d calls, %d checks", primary.calls, primary.checks)
+	}
+}
+
+func TestCircuitBreakerTimeout(t *testing.T) {
+	slow := SchedulerFunc(func(ctx context.Context, state algorithm.ClusterState, weights algorithm.ObjectiveWeights) ([]Decision, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	fallback := &fakeScheduler{pod: "fallback"}
+	breaker := NewCircuitBreaker(slow, melodyv1alpha2.DQNScheduling, fallback, melodyv1alpha2.DefaultScheduling, 3, time.Minute, 10*time.Millisecond)
+
+	decisions, err := breaker.Schedule(context.Background(), algorithm.ClusterState{}, algorithm.DefaultObjectiveWeights())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decisions) != 1 || decisions[0].Algorithm != melodyv1alpha2.DefaultScheduling {
+		t.Fatalf("expected fallback decision, got %+v", decisions)
+	}
+}
+
+func TestCircuitBreakerDoesNotBlockWhileCalling(t *testing.T) {
+	// The first call blocks in the primary until the second call, which falls back, completes
+	release := make(chan struct{})
+	calls := make(chan struct{}, 2)
+	primary := SchedulerFunc(func(ctx context.Context, state algorithm.ClusterState, weights algorithm.ObjectiveWeights) ([]Decision, error) {
+		calls <- struct{}{}
+		if len(calls) == 1 {
+			<-release
+			return nil, nil
+		}
+		return nil, errors.New("unreachable")
+	})
+	fallback := &fakeScheduler{pod: "fallback"}
+	breaker := NewCircuitBreaker(primary, melodyv1alpha2.DQNScheduling, fallback, melodyv1alpha2.DefaultScheduling, 3, time.Minute, 0)
+
+	done := make(chan error)
+	go func() {
+		_, _, err := breaker.ScheduleWithFallback(context.Background(), algorithm.ClusterState{}, algorithm.DefaultObjectiveWeights())
+		done <- err
+	}()
+	for len(calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	result := make(chan string)
+	go func() {
+		_, reason, _ := breaker.ScheduleWithFallback(context.Background(), algorithm.ClusterState{}, algorithm.DefaultObjectiveWeights())
+		result <- reason
+	}()
+	select {
+	case reason := <-result:
+		if reason == "" {
+			t.Error("expected the second call to fall back")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the second call not to wait for the first one")
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCircuitBreakerFallbackFails(t *testing.T) {
+	primary := &fakeScheduler{err: errors.New("unreachable")}
+	fallback := &fakeScheduler{err: errors.New("broken")}
+	breaker := NewCircuitBreaker(primary, melodyv1alpha2.DQNScheduling, fallback, melodyv1alpha2.DefaultScheduling, 3, time.Minute, 0)
+
+	if _, err := breaker.Schedule(context.Background(), algorithm.ClusterState{}, algorithm.DefaultObjectiveWeights()); err == nil {
+		t.Fatal("expected error")
+	}
+}
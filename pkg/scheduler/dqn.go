@@ -0,0 +1,142 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"melody/pkg/algorithm"
+	"melody/pkg/dqn"
+)
+
+// ModelSource provides the DQN model, which may be reloaded between the rounds.
+type ModelSource interface {
+	// Model returns the model, or nil if no model is loaded.
+	Model() *dqn.Model
+}
+
+// DQN evaluates a DQN policy in process. For each Inference, the serving pod on its most loaded node by the balance
+// weights is moved onto the node with the highest Q-value, unless it does not beat keeping the pod by the migration
+// cost.
+type DQN struct {
+	Models ModelSource
+}
+
+// NewDQN returns a DQN scheduler with the models.
+func NewDQN(models ModelSource) *DQN {
+	return &DQN{Models: models}
+}
+
+func (s *DQN) Schedule(ctx context.Context, state algorithm.ClusterState, weights algorithm.ObjectiveWeights) ([]Decision, error) {
+	model := s.Models.Model()
+	if model == nil {
+		return nil, errors.New("DQN model is not loaded")
+	}
+
+	// The nodes are assigned to the slots by name, the nodes beyond the slots of the model are not scheduled onto
+	nodes := make([]*algorithm.NodeState, 0, len(state.Nodes))
+	for i := range state.Nodes {
+		nodes = append(nodes, &state.Nodes[i])
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	if len(nodes) > model.MaxNodes {
+		nodes = nodes[:model.MaxNodes]
+	}
+	slots := make(map[string]int, len(nodes))
+	for i, node := range nodes {
+		slots[node.Name] = i
+	}
+
+	var decisions []Decision
+	for i := range state.Inferences {
+		inference := &state.Inferences[i]
+		pod := podOnMostLoadedNode(inference, nodes, slots, weights)
+		if pod == nil {
+			continue
+		}
+
+		input := make([]float64, model.MaxNodes*dqn.FeaturesPerNode)
+		for slot, node := range nodes {
+			input[slot*dqn.FeaturesPerNode] = node.CPU
+			input[slot*dqn.FeaturesPerNode+1] = node.Memory
+		}
+		for j := range inference.Pods {
+			if slot, ok := slots[inference.Pods[j].Node]; ok {
+				input[slot*dqn.FeaturesPerNode+2] += 1 / float64(len(inference.Pods))
+			}
+		}
+		q, err := model.Forward(input)
+		if err != nil {
+			return nil, err
+		}
+
+		// Keeping the pod is the last action, the empty slots and the current node of the pod are masked. The Q-values
+		// estimate the reward, so a move is charged the migration cost the reward penalizes it with
+		best, bestScore := model.MaxNodes, q[model.MaxNodes]
+		for slot := range nodes {
+			if score := q[slot] - weights.MigrationCost; nodes[slot].Name != pod.Node && score > bestScore {
+				best, bestScore = slot, score
+			}
+		}
+		if best < len(nodes) {
+			decision := transition(inference, pod, nodes[best].Name)
+			explanation, err := explainDQN(model, input, q, best, nodes, pod)
+			if err != nil {
+				return nil, err
+			}
+			decision.Explanation = explanation
+			decisions = append(decisions, decision)
+		}
+	}
+	return decisions, nil
+}
+
+// featureNames are the names of the features of a node slot.
+var featureNames = [dqn.FeaturesPerNode]string{"cpu", "memory", "share"}
+
+// explainDQN explains the action by the Q-values of the candidate nodes, where keeping the pod is the candidate of its
+// current node. The importance of a feature is how much the Q-value of the action drops without it.
+func explainDQN(model *dqn.Model, input, q []float64, action int, nodes []*algorithm.NodeState, pod *algorithm.PodState) (*algorithm.Explanation, error) {
+	candidates := []algorithm.CandidateScore{{Node: pod.Node, Score: q[model.MaxNodes]}}
+	for slot, node := range nodes {
+		if node.Name != pod.Node {
+			candidates = append(candidates, algorithm.CandidateScore{Node: node.Name, Score: q[slot]})
+		}
+	}
+
+	var features []algorithm.FeatureImportance
+	occluded := make([]float64, len(input))
+	for i, value := range input {
+		if value == 0 {
+			continue
+		}
+		copy(occluded, input)
+		occluded[i] = 0
+		without, err := model.Forward(occluded)
+		if err != nil {
+			return nil, err
+		}
+		features = append(features, algorithm.FeatureImportance{
+			Name:       nodes[i/dqn.FeaturesPerNode].Name + "/" + featureNames[i%dqn.FeaturesPerNode],
+			Value:      value,
+			Importance: q[action] - without[action],
+		})
+	}
+	return newExplanation(candidates, features), nil
+}
+
+// podOnMostLoadedNode returns the serving pod of the Inference on its most loaded node within the slots.
+func podOnMostLoadedNode(inference *algorithm.InferenceState, nodes []*algorithm.NodeState, slots map[string]int, weights algorithm.ObjectiveWeights) *algorithm.PodState {
+	var pod *algorithm.PodState
+	var load float64
+	for i := range inference.Pods {
+		slot, ok := slots[inference.Pods[i].Node]
+		if !ok {
+			continue
+		}
+		if l := weights.Load(nodes[slot]); pod == nil || l > load {
+			pod, load = &inference.Pods[i], l
+		}
+	}
+	return pod
+}
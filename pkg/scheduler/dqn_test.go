@@ -0,0 +1,136 @@
+package scheduler
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"melody/pkg/algorithm"
+	"melody/pkg/dqn"
+)
+
+type staticModel struct {
+	model *dqn.Model
+}
+
+func (s staticModel) Model() *dqn.Model {
+	return s.model
+}
+
+// qModel returns a linear model of 3 node slots, whose Q-values are the biases.
+func qModel(t *testing.T, q ...float64) *dqn.Model {
+	t.Helper()
+	layer := dqn.Layer{Bias: q}
+	for range q {
+		layer.Weights = append(layer.Weights, make([]float64, 3*dqn.FeaturesPerNode))
+	}
+	return &dqn.Model{Version: dqn.ModelVersion, MaxNodes: 3, Layers: []dqn.Layer{layer}}
+}
+
+func TestDQN(t *testing.T) {
+	decisions, err := NewDQN(staticModel{qModel(t, 5, 1, 3, 2)}).Schedule(context.Background(), newState(), algorithm.DefaultObjectiveWeights())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The current node of each pod is masked, so resnet on edge-1 moves onto edge-3, and inception on edge-3 moves
+	// onto edge-1
+	if len(decisions) != 2 {
+		t.Fatalf("expected 2 decisions, got %+v", decisions)
+	}
+	assertTransition(t, decisions[:1], "resnet-0", "edge-3")
+	assertTransition(t, decisions[1:], "inception-0", "edge-1")
+}
+
+func TestDQNKeeps(t *testing.T) {
+	decisions, err := NewDQN(staticModel{qModel(t, 1, 1, 1, 2)}).Schedule(context.Background(), newState(), algorithm.DefaultObjectiveWeights())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decisions) != 0 {
+		t.Errorf("expected no decision, got %+v", decisions)
+	}
+}
+
+func TestDQNMigrationCost(t *testing.T) {
+	weights := algorithm.DefaultObjectiveWeights()
+	weights.MigrationCost = 2
+	decisions, err := NewDQN(staticModel{qModel(t, 5, 1, 3, 2)}).Schedule(context.Background(), newState(), weights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Moving resnet onto edge-3 does not beat keeping it by the migration cost, moving inception onto edge-1 does
+	assertTransition(t, decisions, "inception-0", "edge-1")
+}
+
+func TestDQNBalanceWeights(t *testing.T) {
+	state := algorithm.ClusterState{
+		Nodes: []algorithm.NodeState{
+			{Name: "edge-1", CPU: 0.9, Memory: 0.1},
+			{Name: "edge-2", CPU: 0.1, Memory: 0.8},
+			{Name: "edge-3"},
+		},
+		Inferences: []algorithm.InferenceState{{
+			Name: "resnet", Namespace: "default", Replicas: 2,
+			Pods: []algorithm.PodState{
+				{Name: "resnet-0", Namespace: "default", Node: "edge-1"},
+				{Name: "resnet-1", Namespace: "default", Node: "edge-2"},
+			},
+		}},
+	}
+	for _, tc := range []struct {
+		name    string
+		weights algorithm.ObjectiveWeights
+		pod     string
+	}{
+		{name: "cpu", weights: algorithm.ObjectiveWeights{CPUBalance: 1}, pod: "resnet-0"},
+		{name: "memory", weights: algorithm.ObjectiveWeights{MemoryBalance: 1}, pod: "resnet-1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			decisions, err := NewDQN(staticModel{qModel(t, 0, 0, 5, 1)}).Schedule(context.Background(), state, tc.weights)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The pod on the most loaded node by the weights is moved
+			assertTransition(t, decisions, tc.pod, "edge-3")
+		})
+	}
+}
+
+func TestDQNWithoutModel(t *testing.T) {
+	if _, err := NewDQN(staticModel{}).Schedule(context.Background(), newState(), algorithm.DefaultObjectiveWeights()); err == nil {
+		t.Error("expected error without model")
+	}
+}
+
+func TestDQNExplanation(t *testing.T) {
+	model := qModel(t, 1, 1, 3, 2)
+	// The Q-value of edge-3 grows with the cpu utilization of edge-1
+	model.Layers[0].Weights[2][0] = 1
+	decisions, err := NewDQN(staticModel{model}).Schedule(context.Background(), newState(), algorithm.DefaultObjectiveWeights())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertTransition(t, decisions[:1], "resnet-0", "edge-3")
+
+	explanation := decisions[0].Explanation
+	if explanation == nil {
+		t.Fatal("expected an explanation")
+	}
+	// Keeping resnet-0 on edge-1 is scored by the keep Q-value
+	expected := []algorithm.CandidateScore{{Node: "edge-3", Score: 3.9}, {Node: "edge-1", Score: 2}, {Node: "edge-2", Score: 1}}
+	if len(explanation.Candidates) != len(expected) {
+		t.Fatalf("expected candidates %+v, got %+v", expected, explanation.Candidates)
+	}
+	for i := range expected {
+		if explanation.Candidates[i].Node != expected[i].Node || math.Abs(explanation.Candidates[i].Score-expected[i].Score) > 1e-9 {
+			t.Errorf("expected candidates %+v, got %+v", expected, explanation.Candidates)
+			break
+		}
+	}
+	if len(explanation.Features) != maxExplainedFeatures {
+		t.Fatalf("expected %d features, got %+v", maxExplainedFeatures, explanation.Features)
+	}
+	if feature := explanation.Features[0]; feature.Name != "edge-1/cpu" || math.Abs(feature.Importance-0.9) > 1e-9 {
+		t.Errorf("expected edge-1/cpu to matter the most, got %+v", feature)
+	}
+}
@@ -0,0 +1,54 @@
+package scheduler
+
+import (
+	"math"
+	"sort"
+
+	"melody/pkg/algorithm"
+)
+
+// maxExplainedFeatures is the number of the most important features explained for a decision.
+const maxExplainedFeatures = 3
+
+// newExplanation returns the explanation of the candidates sorted from the best, and the features sorted from the most
+// important, of which only the most important are kept.
+func newExplanation(candidates []algorithm.CandidateScore, features []algorithm.FeatureImportance) *algorithm.Explanation {
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+	sort.SliceStable(features, func(i, j int) bool {
+		return math.Abs(features[i].Importance) > math.Abs(features[j].Importance)
+	})
+	if len(features) > maxExplainedFeatures {
+		features = features[:maxExplainedFeatures]
+	}
+	return &algorithm.Explanation{Candidates: candidates, Features: features}
+}
+
+// explainLoads explains a move between the nodes by their loads, where the candidates are scored by the score of
+// their loads.
+func explainLoads(nodes []*nodeLoad, source, target *nodeLoad, weights algorithm.ObjectiveWeights, score func(*nodeLoad) float64) *algorithm.Explanation {
+	candidates := make([]algorithm.CandidateScore, 0, len(nodes))
+	for _, node := range nodes {
+		candidates = append(candidates, algorithm.CandidateScore{Node: node.Name, Score: score(node)})
+	}
+	return newExplanation(candidates, loadFeatures(nodes, source, target, weights))
+}
+
+// loadFeatures returns the cpu and memory utilizations of the source and target nodes of a move as the features,
+// whose importance is their deviation from the mean utilization weighted by the balance weight.
+func loadFeatures(nodes []*nodeLoad, source, target *nodeLoad, weights algorithm.ObjectiveWeights) []algorithm.FeatureImportance {
+	var cpu, memory float64
+	for _, node := range nodes {
+		cpu += node.CPU
+		memory += node.Memory
+	}
+	cpu, memory = cpu/float64(len(nodes)), memory/float64(len(nodes))
+
+	var features []algorithm.FeatureImportance
+	for _, node := range []*nodeLoad{source, target} {
+		features = append(features,
+			algorithm.FeatureImportance{Name: node.Name + "/cpu", Value: node.CPU, Importance: weights.CPUBalance * (node.CPU - cpu)},
+			algorithm.FeatureImportance{Name: node.Name + "/memory", Value: node.Memory, Importance: weights.MemoryBalance * (node.Memory - memory)},
+		)
+	}
+	return features
+}
@@ -0,0 +1,242 @@
+package scheduler
+
+import (
+	"context"
+	"sort"
+
+	"melody/pkg/algorithm"
+)
+
+// nodeLoad is a node with its load, the mean of its cpu and memory utilizations weighted by the balance weights.
+type nodeLoad struct {
+	*algorithm.NodeState
+	load float64
+	// milliCPU and memory are the resources requested by the pods moved onto the node in this round.
+	milliCPU, memory int64
+}
+
+// fits returns true if the pod fits into the free resources of the node.
+func (n *nodeLoad) fits(pod *algorithm.PodState) bool {
+	freeCPU := int64(float64(n.AllocatableMilliCPU)*(1-n.CPU)) - n.milliCPU
+	freeMemory := int64(float64(n.AllocatableMemory)*(1-n.Memory)) - n.memory
+	return pod.MilliCPU <= freeCPU && pod.Memory <= freeMemory
+}
+
+// sortedNodes returns the nodes sorted by load from the least loaded, ties are sorted by name.
+func sortedNodes(state *algorithm.ClusterState, weights algorithm.ObjectiveWeights) []*nodeLoad {
+	nodes := make([]*nodeLoad, 0, len(state.Nodes))
+	for i := range state.Nodes {
+		node := &state.Nodes[i]
+		nodes = append(nodes, &nodeLoad{NodeState: node, load: weights.Load(node)})
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].load != nodes[j].load {
+			return nodes[i].load < nodes[j].load
+		}
+		return nodes[i].Name < nodes[j].Name
+	})
+	return nodes
+}
+
+// podsOnNode returns the serving pods on the node, sorted by Inference and name.
+func podsOnNode(state *algorithm.ClusterState, node string) ([]*algorithm.InferenceState, []*algorithm.PodState) {
+	var inferences []*algorithm.InferenceState
+	var pods []*algorithm.PodState
+	for i := range state.Inferences {
+		inference := &state.Inferences[i]
+		for j := range inference.Pods {
+			if pod := &inference.Pods[j]; pod.Node == node {
+				inferences, pods = append(inferences, inference), append(pods, pod)
+			}
+		}
+	}
+	return inferences, pods
+}
+
+// LeastLoaded moves a serving pod from the most loaded node onto the least loaded node, if the gap between their
+// loads exceeds the threshold. It balances the load of the nodes.
+type LeastLoaded struct {
+	// Threshold is the minimum gap between the loads of the nodes to move a pod, between 0 and 1.
+	Threshold float64
+}
+
+// NewLeastLoaded returns a least-loaded scheduler with the default threshold.
+func NewLeastLoaded() *LeastLoaded {
+	return &LeastLoaded{Threshold: 0.2}
+}
+
+func (s *LeastLoaded) Schedule(ctx context.Context, state algorithm.ClusterState, weights algorithm.ObjectiveWeights) ([]Decision, error) {
+	nodes := sortedNodes(&state, weights)
+	if len(nodes) < 2 {
+		return nil, nil
+	}
+	target := nodes[0]
+	for i := len(nodes) - 1; i > 0; i-- {
+		source := nodes[i]
+		if source.load-target.load <= s.Threshold {
+			break
+		}
+		inferences, pods := podsOnNode(&state, source.Name)
+		for j, pod := range pods {
+			if target.fits(pod) {
+				decision := transition(inferences[j], pod, target.Name)
+				decision.Explanation = explainLoads(nodes, source, target, weights, func(n *nodeLoad) float64 { return 1 - n.load })
+				return []Decision{decision}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// BinPacking moves a serving pod from the least loaded node hosting serving pods onto the most loaded node it fits
+// into. It packs the serving pods onto fewer nodes, so that idle edge nodes can be powered down.
+type BinPacking struct{}
+
+// NewBinPacking returns a bin-packing scheduler.
+func NewBinPacking() *BinPacking {
+	return &BinPacking{}
+}
+
+func (s *BinPacking) Schedule(ctx context.Context, state algorithm.ClusterState, weights algorithm.ObjectiveWeights) ([]Decision, error) {
+	nodes := sortedNodes(&state, weights)
+	for i, source := range nodes {
+		inferences, pods := podsOnNode(&state, source.Name)
+		if len(pods) == 0 {
+			continue
+		}
+		for j := len(nodes) - 1; j > i; j-- {
+			target := nodes[j]
+			if target.load <= source.load {
+				break
+			}
+			for k, pod := range pods {
+				if target.fits(pod) {
+					decision := transition(inferences[k], pod, target.Name)
+					decision.Explanation = explainLoads(nodes, source, target, weights, func(n *nodeLoad) float64 { return n.load })
+					return []Decision{decision}, nil
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// Spread moves the serving pods of an Inference sharing a node onto the least loaded nodes without its pods, so that
+// an Inference survives the failure of a node.
+type Spread struct{}
+
+// NewSpread returns a spread scheduler.
+func NewSpread() *Spread {
+	return &Spread{}
+}
+
+func (s *Spread) Schedule(ctx context.Context, state algorithm.ClusterState, weights algorithm.ObjectiveWeights) ([]Decision, error) {
+	nodes := sortedNodes(&state, weights)
+	var decisions []Decision
+	for i := range state.Inferences {
+		inference := &state.Inferences[i]
+		hosted := make(map[string]int)
+		for j := range inference.Pods {
+			if node := inference.Pods[j].Node; node != "" {
+				hosted[node]++
+			}
+		}
+		for j := range inference.Pods {
+			pod := &inference.Pods[j]
+			if pod.Node == "" || hosted[pod.Node] < 2 {
+				continue
+			}
+			for _, target := range nodes {
+				if hosted[target.Name] > 0 || !target.fits(pod) {
+					continue
+				}
+				decisions = append(decisions, transition(inference, pod, target.Name))
+				hosted[pod.Node]--
+				hosted[target.Name]++
+				target.milliCPU += pod.MilliCPU
+				target.memory += pod.Memory
+				break
+			}
+		}
+	}
+	return decisions, nil
+}
+
+// Greedy moves the serving pod whose move improves the balance of the cpu and memory utilizations of the nodes the
+// most, weighted by the balance weights, net of the migration cost. It maximizes the reward of a single step.
+type Greedy struct {
+	// Threshold is the minimum net improvement to move a pod.
+	Threshold float64
+}
+
+// NewGreedy returns a greedy scheduler with the default threshold.
+func NewGreedy() *Greedy {
+	return &Greedy{Threshold: 0.01}
+}
+
+func (s *Greedy) Schedule(ctx context.Context, state algorithm.ClusterState, weights algorithm.ObjectiveWeights) ([]Decision, error) {
+	nodes := sortedNodes(&state, weights)
+	index := make(map[string]int, len(nodes))
+	cpus, memories := make([]float64, len(nodes)), make([]float64, len(nodes))
+	for i, node := range nodes {
+		index[node.Name] = i
+		cpus[i], memories[i] = node.CPU, node.Memory
+	}
+	imbalance := func() float64 {
+		return weights.CPUBalance*algorithm.Imbalance(cpus) + weights.MemoryBalance*algorithm.Imbalance(memories)
+	}
+	before := imbalance()
+
+	var best *Decision
+	bestGain := s.Threshold
+	for i := range state.Inferences {
+		inference := &state.Inferences[i]
+		for j := range inference.Pods {
+			pod := &inference.Pods[j]
+			source, ok := index[pod.Node]
+			if !ok {
+				continue
+			}
+			// Keeping the pod gains nothing
+			candidates := []algorithm.CandidateScore{{Node: pod.Node}}
+			podBest, podGain := -1, bestGain
+			for target, node := range nodes {
+				if target == source || !node.fits(pod) {
+					continue
+				}
+				// Move the pod, and move it back once the gain is computed
+				from, to := podUtilization(pod, nodes[source]), podUtilization(pod, node)
+				cpus[source], memories[source] = cpus[source]-from[0], memories[source]-from[1]
+				cpus[target], memories[target] = cpus[target]+to[0], memories[target]+to[1]
+				gain := before - imbalance() - weights.MigrationCost
+				cpus[source], memories[source] = cpus[source]+from[0], memories[source]+from[1]
+				cpus[target], memories[target] = cpus[target]-to[0], memories[target]-to[1]
+				candidates = append(candidates, algorithm.CandidateScore{Node: node.Name, Score: gain})
+				if gain > podGain {
+					podBest, podGain = target, gain
+				}
+			}
+			if podBest >= 0 {
+				decision := transition(inference, pod, nodes[podBest].Name)
+				decision.Explanation = newExplanation(candidates, loadFeatures(nodes, nodes[source], nodes[podBest], weights))
+				best, bestGain = &decision, podGain
+			}
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	return []Decision{*best}, nil
+}
+
+// podUtilization returns the cpu and memory utilizations of the pod on the node.
+func podUtilization(pod *algorithm.PodState, node *nodeLoad) [2]float64 {
+	var utilization [2]float64
+	if node.AllocatableMilliCPU > 0 {
+		utilization[0] = float64(pod.MilliCPU) / float64(node.AllocatableMilliCPU)
+	}
+	if node.AllocatableMemory > 0 {
+		utilization[1] = float64(pod.Memory) / float64(node.AllocatableMemory)
+	}
+	return utilization
+}
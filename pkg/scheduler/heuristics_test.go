@@ -0,0 +1,158 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	melodyv1alpha2 "melody/api/v1alpha2"
+	"melody/pkg/algorithm"
+)
+
+func newState() algorithm.ClusterState {
+	return algorithm.ClusterState{
+		Nodes: []algorithm.NodeState{
+			{Name: "edge-1", AllocatableMilliCPU: 4000, AllocatableMemory: 8 << 30, CPU: 0.9, Memory: 0.8},
+			{Name: "edge-2", AllocatableMilliCPU: 4000, AllocatableMemory: 8 << 30, CPU: 0.5, Memory: 0.5},
+			{Name: "edge-3", AllocatableMilliCPU: 4000, AllocatableMemory: 8 << 30, CPU: 0.1, Memory: 0.2},
+		},
+		Inferences: []algorithm.InferenceState{
+			{
+				Name: "resnet", Namespace: "default", Replicas: 2,
+				Pods: []algorithm.PodState{
+					{Name: "resnet-0", Namespace: "default", Node: "edge-1", MilliCPU: 500, Memory: 1 << 30},
+					{Name: "resnet-1", Namespace: "default", Node: "edge-1", MilliCPU: 500, Memory: 1 << 30},
+				},
+			},
+			{
+				Name: "inception", Namespace: "default", Replicas: 1,
+				Pods: []algorithm.PodState{
+					{Name: "inception-0", Namespace: "default", Node: "edge-3", MilliCPU: 500, Memory: 1 << 30},
+				},
+			},
+		},
+	}
+}
+
+func assertTransition(t *testing.T, decisions []Decision, pod, node string) {
+	t.Helper()
+	if len(decisions) != 1 {
+		t.Fatalf("expected 1 decision, got %+v", decisions)
+	}
+	objective := decisions[0].Objective
+	if objective.Type != melodyv1alpha2.Transition || objective.TargetPod.Name != pod || objective.TargetNode.Name != node {
+		t.Errorf("expected moving %s onto %s, got %+v", pod, node, objective)
+	}
+}
+
+func TestLeastLoaded(t *testing.T) {
+	decisions, err := NewLeastLoaded().Schedule(context.Background(), newState(), algorithm.DefaultObjectiveWeights())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertTransition(t, decisions, "resnet-0", "edge-3")
+	if decisions[0].Inference.Name != "resnet" || decisions[0].Inference.Namespace != "default" {
+		t.Errorf("unexpected inference %v", decisions[0].Inference)
+	}
+}
+
+func TestLeastLoadedBalanced(t *testing.T) {
+	state := newState()
+	for i := range state.Nodes {
+		state.Nodes[i].CPU, state.Nodes[i].Memory = 0.5, 0.5
+	}
+	decisions, err := NewLeastLoaded().Schedule(context.Background(), state, algorithm.DefaultObjectiveWeights())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decisions) != 0 {
+		t.Errorf("expected no decision for balanced nodes, got %+v", decisions)
+	}
+}
+
+func TestLeastLoadedSkipsPodsNotFitting(t *testing.T) {
+	state := newState()
+	state.Nodes[2].AllocatableMilliCPU = 400
+	decisions, err := NewLeastLoaded().Schedule(context.Background(), state, algorithm.DefaultObjectiveWeights())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decisions) != 0 {
+		t.Errorf("expected no decision as the pods do not fit, got %+v", decisions)
+	}
+}
+
+func TestLeastLoadedWeights(t *testing.T) {
+	state := newState()
+	state.Nodes[0].Memory, state.Nodes[2].Memory = 0.3, 0.5
+	decisions, err := NewLeastLoaded().Schedule(context.Background(), state, algorithm.DefaultObjectiveWeights())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertTransition(t, decisions, "resnet-0", "edge-3")
+
+	// Balancing memory only, the nodes are balanced enough
+	decisions, err = NewLeastLoaded().Schedule(context.Background(), state, algorithm.ObjectiveWeights{MemoryBalance: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decisions) != 0 {
+		t.Errorf("expected no decision balancing memory only, got %+v", decisions)
+	}
+}
+
+func TestBinPacking(t *testing.T) {
+	decisions, err := NewBinPacking().Schedule(context.Background(), newState(), algorithm.DefaultObjectiveWeights())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// edge-1 is too full for the pod, so it is packed onto edge-2
+	assertTransition(t, decisions, "inception-0", "edge-2")
+}
+
+func TestSpread(t *testing.T) {
+	decisions, err := NewSpread().Schedule(context.Background(), newState(), algorithm.DefaultObjectiveWeights())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertTransition(t, decisions, "resnet-0", "edge-3")
+}
+
+func TestBuiltin(t *testing.T) {
+	schedulers := Builtin()
+	for _, algorithm := range []melodyv1alpha2.SchedulingAlgorithm{
+		melodyv1alpha2.DefaultScheduling, melodyv1alpha2.LeastLoadedScheduling,
+		melodyv1alpha2.BinPackingScheduling, melodyv1alpha2.SpreadScheduling,
+	} {
+		if schedulers[algorithm] == nil {
+			t.Errorf("algorithm %s is not built in", algorithm)
+		}
+	}
+	if schedulers[melodyv1alpha2.DQNScheduling] != nil {
+		t.Error("DQN is not built in")
+	}
+}
+
+func TestGreedy(t *testing.T) {
+	decisions, err := NewGreedy().Schedule(context.Background(), newState(), algorithm.DefaultObjectiveWeights())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertTransition(t, decisions, "resnet-0", "edge-3")
+	explanation := decisions[0].Explanation
+	if explanation == nil || len(explanation.Candidates) != 3 || explanation.Candidates[0].Node != "edge-3" {
+		t.Errorf("expected edge-3 to be the best candidate, got %+v", explanation)
+	} else if last := explanation.Candidates[2]; last.Node != "edge-1" || last.Score != 0 {
+		t.Errorf("expected keeping the pod to gain nothing, got %+v", last)
+	}
+
+	// The migration costs more than the balance it gains
+	weights := algorithm.DefaultObjectiveWeights()
+	weights.MigrationCost = 1
+	decisions, err = NewGreedy().Schedule(context.Background(), newState(), weights)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decisions) != 0 {
+		t.Errorf("expected no decision for a costly migration, got %+v", decisions)
+	}
+}
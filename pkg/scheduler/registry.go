@@ -0,0 +1,139 @@
+package scheduler
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	melodyv1alpha2 "melody/api/v1alpha2"
+	"melody/pkg/algorithm"
+	"sigs.k8s.io/yaml"
+)
+
+// Registry is the schedulers by algorithm, which the Inferences and the policies select by name. The built-in
+// algorithms are registered by Builtin, the others are registered at startup.
+type Registry map[melodyv1alpha2.SchedulingAlgorithm]Scheduler
+
+// Register registers the scheduler of the algorithm, which must not be registered yet.
+func (r Registry) Register(name melodyv1alpha2.SchedulingAlgorithm, s Scheduler) error {
+	if name == "" {
+		return fmt.Errorf("scheduling algorithm name is empty")
+	}
+	if _, ok := r[name]; ok {
+		return fmt.Errorf("scheduling algorithm %q is already registered", name)
+	}
+	r[name] = s
+	return nil
+}
+
+// RegistryConfig registers the scheduling algorithms at startup, i.e. mounted from a ConfigMap.
+type RegistryConfig struct {
+	Algorithms []AlgorithmSpec `json:"algorithms"`
+}
+
+// AlgorithmSpec is a scheduling algorithm registered by name. It is either a built-in algorithm with its parameters,
+// or an algorithm of an algorithm server, on the endpoint of its own or the default one.
+type AlgorithmSpec struct {
+	// Name is the name the algorithm is selected by.
+	Name melodyv1alpha2.SchedulingAlgorithm `json:"name"`
+	// Builtin is the built-in algorithm, i.e. least-loaded.
+	Builtin melodyv1alpha2.SchedulingAlgorithm `json:"builtin,omitempty"`
+	// Endpoint is the base URL of the algorithm server of the algorithm, the default algorithm server if empty.
+	Endpoint string `json:"endpoint,omitempty"`
+	// Algorithm is the algorithm requested from the algorithm server, the name if empty.
+	Algorithm string `json:"algorithm,omitempty"`
+	// Parameters configure the built-in algorithm, or are passed to the algorithm server.
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
+// LoadRegistryConfig reads the registry config from the YAML or JSON file.
+func LoadRegistryConfig(path string) (*RegistryConfig, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &RegistryConfig{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("invalid algorithm registry %s: %v", path, err)
+	}
+	return config, nil
+}
+
+// RegisterAll registers the algorithms of the config. An algorithm on an endpoint of its own is called with the client
+// config of the default algorithm server, and the algorithms on the default algorithm server are called with the
+// default client, which is nil without an algorithm server.
+func (r Registry) RegisterAll(config *RegistryConfig, base *algorithm.Config, defaultClient AlgorithmClient) error {
+	for i := range config.Algorithms {
+		spec := &config.Algorithms[i]
+		s, err := newScheduler(spec, base, defaultClient)
+		if err != nil {
+			return fmt.Errorf("scheduling algorithm %q: %v", spec.Name, err)
+		}
+		if err := r.Register(spec.Name, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newScheduler(spec *AlgorithmSpec, base *algorithm.Config, defaultClient AlgorithmClient) (Scheduler, error) {
+	if spec.Builtin != "" {
+		if spec.Endpoint != "" || spec.Algorithm != "" {
+			return nil, fmt.Errorf("builtin is exclusive with endpoint and algorithm")
+		}
+		return newBuiltin(spec.Builtin, spec.Parameters)
+	}
+
+	client := defaultClient
+	if spec.Endpoint != "" {
+		config := *base
+		config.Endpoint = spec.Endpoint
+		c, err := algorithm.NewClient(&config)
+		if err != nil {
+			return nil, err
+		}
+		client = c
+	}
+	if client == nil {
+		return nil, fmt.Errorf("no endpoint without an algorithm server")
+	}
+	name := spec.Name
+	if spec.Algorithm != "" {
+		name = melodyv1alpha2.SchedulingAlgorithm(spec.Algorithm)
+	}
+	remote := NewRemote(client, name)
+	remote.Parameters = spec.Parameters
+	return remote, nil
+}
+
+// newBuiltin returns the built-in scheduler configured by the parameters.
+func newBuiltin(builtin melodyv1alpha2.SchedulingAlgorithm, parameters map[string]string) (Scheduler, error) {
+	var s Scheduler
+	var threshold *float64
+	switch builtin {
+	case melodyv1alpha2.DefaultScheduling, melodyv1alpha2.LeastLoadedScheduling:
+		leastLoaded := NewLeastLoaded()
+		s, threshold = leastLoaded, &leastLoaded.Threshold
+	case melodyv1alpha2.GreedyScheduling:
+		greedy := NewGreedy()
+		s, threshold = greedy, &greedy.Threshold
+	case melodyv1alpha2.BinPackingScheduling:
+		s = NewBinPacking()
+	case melodyv1alpha2.SpreadScheduling:
+		s = NewSpread()
+	default:
+		return nil, fmt.Errorf("unknown builtin algorithm %q", builtin)
+	}
+
+	for key, value := range parameters {
+		if key != "threshold" || threshold == nil {
+			return nil, fmt.Errorf("unknown parameter %q of builtin algorithm %s", key, builtin)
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("invalid threshold %q", value)
+		}
+		*threshold = v
+	}
+	return s, nil
+}
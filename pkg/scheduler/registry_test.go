@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	melodyv1alpha2 "melody/api/v1alpha2"
+	"melody/pkg/algorithm"
+	"melody/pkg/algorithm/stub"
+)
+
+func TestRegistry(t *testing.T) {
+	server := stub.NewServer(nil)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "registry.yaml")
+	config := `algorithms:
+- name: greedy-cpu
+  builtin: greedy
+  parameters:
+    threshold: "0.5"
+- name: PPO
+  endpoint: ` + server.URL + `
+  algorithm: ppo-v2
+  parameters:
+    gamma: "0.99"
+`
+	if err := ioutil.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	registryConfig, err := LoadRegistryConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := Builtin()
+	if err := registry.RegisterAll(registryConfig, algorithm.NewConfig(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	greedy, ok := registry["greedy-cpu"].(*Greedy)
+	if !ok || greedy.Threshold != 0.5 {
+		t.Errorf("expected greedy with threshold 0.5, got %+v", registry["greedy-cpu"])
+	}
+	if _, err := registry[melodyv1alpha2.PPOScheduling].Schedule(context.Background(), newState(), algorithm.DefaultObjectiveWeights()); err != nil {
+		t.Fatal(err)
+	}
+	requests := server.Requests()
+	if len(requests) != 1 || requests[0].Algorithm != "ppo-v2" || requests[0].Parameters["gamma"] != "0.99" {
+		t.Errorf("unexpected requests %+v", requests)
+	}
+
+	if err := registry.Register(melodyv1alpha2.PPOScheduling, NewSpread()); err == nil {
+		t.Error("expected error for a duplicated algorithm")
+	}
+}
+
+func TestRegistryInvalid(t *testing.T) {
+	for name, spec := range map[string]AlgorithmSpec{
+		"unknown builtin":   {Name: "a", Builtin: "random"},
+		"unknown parameter": {Name: "a", Builtin: melodyv1alpha2.SpreadScheduling, Parameters: map[string]string{"threshold": "1"}},
+		"invalid threshold": {Name: "a", Builtin: melodyv1alpha2.LeastLoadedScheduling, Parameters: map[string]string{"threshold": "x"}},
+		"builtin endpoint":  {Name: "a", Builtin: melodyv1alpha2.SpreadScheduling, Endpoint: "http://a"},
+		"no server":         {Name: "a"},
+	} {
+		config := &RegistryConfig{Algorithms: []AlgorithmSpec{spec}}
+		if err := Builtin().RegisterAll(config, algorithm.NewConfig(), nil); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	melodyv1alpha2 "melody/api/v1alpha2"
+	"melody/pkg/algorithm"
+)
+
+// AlgorithmClient requests the scheduling actions for the cluster state from the algorithm server.
+type AlgorithmClient interface {
+	Schedule(ctx context.Context, req *algorithm.ScheduleRequest) (*algorithm.ScheduleResponse, error)
+}
+
+// HealthChecker checks the backend of a scheduler is serving without scheduling, i.e. the algorithm server of Remote.
+type HealthChecker interface {
+	Healthz(ctx context.Context) error
+}
+
+// Remote is a scheduler which calls the algorithm server, i.e. for DQN.
+type Remote struct {
+	Client    AlgorithmClient
+	Algorithm melodyv1alpha2.SchedulingAlgorithm
+	// Parameters are passed to the algorithm in each request.
+	Parameters map[string]string
+}
+
+// NewRemote returns a scheduler calling the algorithm of the algorithm server.
+func NewRemote(client AlgorithmClient, algorithm melodyv1alpha2.SchedulingAlgorithm) *Remote {
+	return &Remote{Client: client, Algorithm: algorithm}
+}
+
+// Schedule turns the actions of the algorithm server into the decisions.
+func (r *Remote) Schedule(ctx context.Context, state algorithm.ClusterState, weights algorithm.ObjectiveWeights) ([]Decision, error) {
+	resp, err := r.Client.Schedule(ctx, &algorithm.ScheduleRequest{
+		Algorithm:  string(r.Algorithm),
+		State:      state,
+		Vector:     state.Vector(),
+		Weights:    weights,
+		Parameters: r.Parameters,
+	})
+	if err != nil {
+		return nil, err
+	}
+	decisions := make([]Decision, 0, len(resp.Actions))
+	for _, action := range resp.Actions {
+		decisions = append(decisions, Decision{
+			Inference: types.NamespacedName{Name: action.Inference, Namespace: action.Namespace},
+			Objective: melodyv1alpha2.SchedulingObjective{
+				Type: melodyv1alpha2.SchedulingType(action.Type),
+				TargetPod: melodyv1alpha2.ObjectReference{
+					Name:      action.TargetPod.Name,
+					Namespace: action.TargetPod.Namespace,
+					UID:       types.UID(action.TargetPod.UID),
+				},
+				TargetNode:     melodyv1alpha2.ObjectReference{Name: action.TargetNode},
+				ScalingReplica: action.ScalingReplica,
+			},
+			Explanation: action.Explanation,
+		})
+	}
+	return decisions, nil
+}
+
+// Healthz checks the algorithm server is serving, which always succeeds if the client can not check it.
+func (r *Remote) Healthz(ctx context.Context) error {
+	if checker, ok := r.Client.(HealthChecker); ok {
+		return checker.Healthz(ctx)
+	}
+	return nil
+}
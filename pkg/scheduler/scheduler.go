@@ -0,0 +1,60 @@
+// Package scheduler defines the scheduling algorithms, which decide the scheduling objectives of the Inferences from
+// the state of the cluster.
+package scheduler
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+	melodyv1alpha2 "melody/api/v1alpha2"
+	"melody/pkg/algorithm"
+)
+
+// Decision is the scheduling objective decided for an Inference.
+type Decision struct {
+	// Inference is the Inference the objective is applied to.
+	Inference types.NamespacedName
+	Objective melodyv1alpha2.SchedulingObjective
+	// Algorithm is the algorithm which produces the decision, the requested algorithm if empty.
+	Algorithm melodyv1alpha2.SchedulingAlgorithm
+	// Explanation explains why the algorithm decided the objective, nil if the algorithm does not explain.
+	Explanation *algorithm.Explanation
+}
+
+// Scheduler decides the scheduling objectives from the state of the cluster. No decision is returned if nothing
+// should be changed.
+type Scheduler interface {
+	Schedule(ctx context.Context, state algorithm.ClusterState, weights algorithm.ObjectiveWeights) ([]Decision, error)
+}
+
+// SchedulerFunc is a function which implements Scheduler.
+type SchedulerFunc func(ctx context.Context, state algorithm.ClusterState, weights algorithm.ObjectiveWeights) ([]Decision, error)
+
+func (f SchedulerFunc) Schedule(ctx context.Context, state algorithm.ClusterState, weights algorithm.ObjectiveWeights) ([]Decision, error) {
+	return f(ctx, state, weights)
+}
+
+// Builtin returns the registry of the built-in schedulers, which run without an algorithm server. The default
+// algorithm is the least-loaded scheduler.
+func Builtin() Registry {
+	leastLoaded := NewLeastLoaded()
+	return Registry{
+		melodyv1alpha2.DefaultScheduling:     leastLoaded,
+		melodyv1alpha2.LeastLoadedScheduling: leastLoaded,
+		melodyv1alpha2.BinPackingScheduling:  NewBinPacking(),
+		melodyv1alpha2.SpreadScheduling:      NewSpread(),
+		melodyv1alpha2.GreedyScheduling:      NewGreedy(),
+	}
+}
+
+// transition returns the decision moving the serving pod of the Inference onto the node.
+func transition(inference *algorithm.InferenceState, pod *algorithm.PodState, node string) Decision {
+	return Decision{
+		Inference: types.NamespacedName{Name: inference.Name, Namespace: inference.Namespace},
+		Objective: melodyv1alpha2.SchedulingObjective{
+			Type:       melodyv1alpha2.Transition,
+			TargetPod:  melodyv1alpha2.ObjectReference{Name: pod.Name, Namespace: pod.Namespace, UID: types.UID(pod.UID)},
+			TargetNode: melodyv1alpha2.ObjectReference{Name: node},
+		},
+	}
+}
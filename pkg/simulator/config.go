@@ -0,0 +1,121 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"melody/pkg/algorithm"
+)
+
+// Config describes the simulated edge cluster, and the request load over time.
+type Config struct {
+	Nodes      []NodeConfig      `json:"nodes"`
+	Inferences []InferenceConfig `json:"inferences"`
+	// Trace is the request load of the Inferences at each step, i.e. recorded from the serving metrics.
+	Trace Trace `json:"trace,omitempty"`
+	// MaxSteps is the number of steps of an episode without a trace.
+	MaxSteps int `json:"maxSteps,omitempty"`
+	// Weights weigh the objectives the reward is computed for, the default weights if nil.
+	Weights *algorithm.ObjectiveWeights `json:"weights,omitempty"`
+}
+
+// NodeConfig is an edge node.
+type NodeConfig struct {
+	Name string `json:"name"`
+	// MilliCPU and Memory are the allocatable resources of the node, in millicores and bytes.
+	MilliCPU int64 `json:"milliCPU"`
+	Memory   int64 `json:"memory"`
+	// BaseMilliCPU and BaseMemory are used by the workloads other than the Inferences.
+	BaseMilliCPU int64 `json:"baseMilliCPU,omitempty"`
+	BaseMemory   int64 `json:"baseMemory,omitempty"`
+}
+
+// InferenceConfig is an Inference and the resource footprint of its serving pods.
+type InferenceConfig struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Replicas  int32  `json:"replicas"`
+	// MinReplicas and MaxReplicas bound the scaling, MaxReplicas is unbounded if zero.
+	MinReplicas int32 `json:"minReplicas,omitempty"`
+	MaxReplicas int32 `json:"maxReplicas,omitempty"`
+	// MilliCPU and Memory are the resource requests of a serving pod, in millicores and bytes.
+	MilliCPU int64 `json:"milliCPU"`
+	Memory   int64 `json:"memory"`
+	// MilliCPUPerRequest is the cpu used by a serving pod for each request per second it serves.
+	MilliCPUPerRequest float64 `json:"milliCPUPerRequest,omitempty"`
+	// LatencyMilliseconds is the serving latency of a pod on an idle node.
+	LatencyMilliseconds float64 `json:"latencyMilliseconds,omitempty"`
+	// Nodes are the nodes of the serving pods at the start of an episode. The pods are placed onto the least
+	// requested nodes if empty.
+	Nodes []string `json:"nodes,omitempty"`
+}
+
+// Key returns the key of the Inference in the trace.
+func (c *InferenceConfig) Key() string {
+	return c.Namespace + "/" + c.Name
+}
+
+// Trace is the request load over time.
+type Trace struct {
+	Steps []TraceStep `json:"steps"`
+}
+
+// TraceStep is the request load at a step.
+type TraceStep struct {
+	// Load is the requests per second of the Inferences by namespace/name.
+	Load map[string]float64 `json:"load"`
+}
+
+// LoadConfig reads the config from the JSON file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, fmt.Errorf("invalid simulator config %s: %v", path, err)
+	}
+	return config, nil
+}
+
+// validate checks the config is consistent.
+func (c *Config) validate() error {
+	if len(c.Nodes) == 0 {
+		return fmt.Errorf("no nodes")
+	}
+	if len(c.Trace.Steps) == 0 && c.MaxSteps <= 0 {
+		return fmt.Errorf("either trace or maxSteps is required")
+	}
+	nodes := make(map[string]bool, len(c.Nodes))
+	for _, node := range c.Nodes {
+		if node.Name == "" || nodes[node.Name] {
+			return fmt.Errorf("node name %q is empty or duplicated", node.Name)
+		}
+		if node.MilliCPU <= 0 || node.Memory <= 0 {
+			return fmt.Errorf("node %s has no allocatable resources", node.Name)
+		}
+		nodes[node.Name] = true
+	}
+	inferences := make(map[string]bool, len(c.Inferences))
+	for _, inference := range c.Inferences {
+		key := inference.Key()
+		if inference.Name == "" || inferences[key] {
+			return fmt.Errorf("inference %q is empty or duplicated", key)
+		}
+		inferences[key] = true
+		if inference.Replicas < inference.MinReplicas || (inference.MaxReplicas > 0 && inference.Replicas > inference.MaxReplicas) {
+			return fmt.Errorf("inference %s has replicas %d out of [%d, %d]", key, inference.Replicas, inference.MinReplicas, inference.MaxReplicas)
+		}
+		if len(inference.Nodes) > 0 && len(inference.Nodes) != int(inference.Replicas) {
+			return fmt.Errorf("inference %s has %d nodes for %d replicas", key, len(inference.Nodes), inference.Replicas)
+		}
+		for _, node := range inference.Nodes {
+			if !nodes[node] {
+				return fmt.Errorf("inference %s is placed onto unknown node %s", key, node)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package simulator
+
+import (
+	"context"
+
+	"melody/pkg/algorithm"
+	"melody/pkg/scheduler"
+)
+
+// Result summarizes an episode.
+type Result struct {
+	Steps int `json:"steps"`
+	// Actions and Rejected are the numbers of the actions decided and rejected in the episode.
+	Actions  int `json:"actions"`
+	Rejected int `json:"rejected"`
+	// TotalReward is the sum of the rewards of the steps.
+	TotalReward float64 `json:"totalReward"`
+	// MeanCPUImbalance and MeanMemoryImbalance are the mean standard deviations of the node utilizations observed
+	// at the steps.
+	MeanCPUImbalance    float64 `json:"meanCPUImbalance"`
+	MeanMemoryImbalance float64 `json:"meanMemoryImbalance"`
+	// MeanLatencyMilliseconds is the mean serving latency of the Inferences observed at the steps.
+	MeanLatencyMilliseconds float64 `json:"meanLatencyMilliseconds"`
+}
+
+// Evaluate runs an episode of the simulator from the reset, where the scheduler decides the actions at each step.
+func Evaluate(ctx context.Context, sim *Simulator, sched scheduler.Scheduler) (*Result, error) {
+	result := &Result{}
+	state := sim.Reset()
+	var latencies float64
+	var observed int
+	for !sim.Done() {
+		cpuImbalance, memoryImbalance := state.Imbalances()
+		result.MeanCPUImbalance += cpuImbalance
+		result.MeanMemoryImbalance += memoryImbalance
+		for _, inf := range state.Inferences {
+			if inf.LatencyMilliseconds > 0 {
+				latencies += inf.LatencyMilliseconds
+				observed++
+			}
+		}
+
+		decisions, err := sched.Schedule(ctx, state, sim.Weights())
+		if err != nil {
+			return nil, err
+		}
+		step, err := sim.Step(Actions(decisions))
+		if err != nil {
+			return nil, err
+		}
+		result.Steps++
+		result.Actions += len(decisions)
+		result.Rejected += len(step.Errors)
+		result.TotalReward += step.Reward
+		state = step.State
+	}
+	if result.Steps > 0 {
+		result.MeanCPUImbalance /= float64(result.Steps)
+		result.MeanMemoryImbalance /= float64(result.Steps)
+	}
+	if observed > 0 {
+		result.MeanLatencyMilliseconds = latencies / float64(observed)
+	}
+	return result, nil
+}
+
+// Actions returns the actions of the decisions in the protocol of the algorithm server.
+func Actions(decisions []scheduler.Decision) []algorithm.Action {
+	actions := make([]algorithm.Action, 0, len(decisions))
+	for _, decision := range decisions {
+		objective := &decision.Objective
+		actions = append(actions, algorithm.Action{
+			Type:      algorithm.ActionType(objective.Type),
+			Inference: decision.Inference.Name,
+			Namespace: decision.Inference.Namespace,
+			TargetPod: algorithm.ObjectReference{
+				Name:      objective.TargetPod.Name,
+				Namespace: objective.TargetPod.Namespace,
+				UID:       string(objective.TargetPod.UID),
+			},
+			TargetNode:     objective.TargetNode.Name,
+			ScalingReplica: objective.ScalingReplica,
+			Explanation:    decision.Explanation,
+		})
+	}
+	return actions
+}
@@ -0,0 +1,84 @@
+package simulator
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"melody/pkg/algorithm"
+)
+
+const (
+	// ResetPath resets the simulator, and returns the initial state.
+	ResetPath = "/" + algorithm.APIVersion + "/reset"
+	// StepPath applies the actions, and returns the outcome of the step.
+	StepPath = "/" + algorithm.APIVersion + "/step"
+)
+
+// ResetResponse returns the initial state of an episode.
+type ResetResponse struct {
+	APIVersion string                 `json:"apiVersion"`
+	State      algorithm.ClusterState `json:"state"`
+}
+
+// StepRequest requests a step with the actions returned by the agent, the same as a schedule response.
+type StepRequest struct {
+	APIVersion string             `json:"apiVersion"`
+	Actions    []algorithm.Action `json:"actions"`
+}
+
+// StepResponse returns the outcome of a step.
+type StepResponse struct {
+	APIVersion string `json:"apiVersion"`
+	StepResult
+}
+
+// Handler serves the simulator over HTTP with JSON, so that an external RL agent drives it with the state and the
+// actions of the algorithm server protocol.
+type Handler struct {
+	mu  sync.Mutex
+	sim *Simulator
+}
+
+// NewHandler returns a handler serving the simulator.
+func NewHandler(sim *Simulator) *Handler {
+	return &Handler{sim: sim}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, &algorithm.ErrorResponse{Error: "method is not allowed"})
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	switch r.URL.Path {
+	case ResetPath:
+		writeJSON(w, http.StatusOK, &ResetResponse{APIVersion: algorithm.APIVersion, State: h.sim.Reset()})
+	case StepPath:
+		req := &StepRequest{}
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			writeJSON(w, http.StatusBadRequest, &algorithm.ErrorResponse{Error: err.Error()})
+			return
+		}
+		if req.APIVersion != algorithm.APIVersion {
+			writeJSON(w, http.StatusBadRequest, &algorithm.ErrorResponse{Error: "unsupported api version " + req.APIVersion})
+			return
+		}
+		result, err := h.sim.Step(req.Actions)
+		if err == ErrDone {
+			writeJSON(w, http.StatusConflict, &algorithm.ErrorResponse{Error: err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, &StepResponse{APIVersion: algorithm.APIVersion, StepResult: *result})
+	default:
+		writeJSON(w, http.StatusNotFound, &algorithm.ErrorResponse{Error: "not found"})
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
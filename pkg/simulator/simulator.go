@@ -0,0 +1,399 @@
+// Package simulator simulates an edge cluster serving Inferences under a request load over time, so that the
+// scheduling algorithms are trained and evaluated offline before they are applied to a real cluster. The simulator is
+// driven by step and reset like a gym environment, with the state and the actions of the algorithm server protocol.
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"melody/pkg/algorithm"
+)
+
+// maxUtilization bounds the utilization in the latency model, so that an overloaded node has a finite latency.
+const maxUtilization = 0.95
+
+// ErrDone is returned by Step once the episode is done, until the simulator is reset.
+var ErrDone = errors.New("episode is done")
+
+// StepResult is the outcome of the actions of a step.
+type StepResult struct {
+	// State is the state observed at the next step.
+	State algorithm.ClusterState `json:"state"`
+	// Reward is the reward of the actions, the same as the feedback reward of the decisions in the cluster.
+	Reward float64 `json:"reward"`
+	// Done is true once the episode is done.
+	Done bool `json:"done"`
+	// Applied is the number of applied actions.
+	Applied int `json:"applied"`
+	// Errors are the reasons the other actions are rejected.
+	Errors []string `json:"errors,omitempty"`
+}
+
+type node struct {
+	NodeConfig
+}
+
+type pod struct {
+	name string
+	uid  string
+	node string
+}
+
+type inference struct {
+	InferenceConfig
+	pods []*pod
+}
+
+// Simulator simulates an edge cluster. It is not safe for concurrent use.
+type Simulator struct {
+	config     Config
+	nodes      []*node
+	inferences []*inference
+	step       int
+	// created is the number of pods created in the episode, which names the pods.
+	created int
+}
+
+// New returns a simulator of the config, which is reset to the start of an episode.
+func New(config Config) (*Simulator, error) {
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	s := &Simulator{config: config}
+	s.Reset()
+	return s, nil
+}
+
+// Reset resets the simulator to the start of an episode, and returns the initial state.
+func (s *Simulator) Reset() algorithm.ClusterState {
+	s.step, s.created = 0, 0
+	s.nodes = make([]*node, 0, len(s.config.Nodes))
+	for _, config := range s.config.Nodes {
+		s.nodes = append(s.nodes, &node{NodeConfig: config})
+	}
+	s.inferences = make([]*inference, 0, len(s.config.Inferences))
+	for _, config := range s.config.Inferences {
+		inf := &inference{InferenceConfig: config}
+		s.inferences = append(s.inferences, inf)
+		for i := 0; i < int(config.Replicas); i++ {
+			target := ""
+			if len(config.Nodes) > 0 {
+				target = config.Nodes[i]
+			} else if n := s.leastRequested(inf); n != nil {
+				target = n.Name
+			}
+			s.addPod(inf, target)
+		}
+	}
+	return s.State()
+}
+
+// Steps returns the number of steps of an episode.
+func (s *Simulator) Steps() int {
+	if len(s.config.Trace.Steps) > 0 {
+		return len(s.config.Trace.Steps)
+	}
+	return s.config.MaxSteps
+}
+
+// Weights returns the weights of the objectives the reward is computed for.
+func (s *Simulator) Weights() algorithm.ObjectiveWeights {
+	if s.config.Weights != nil {
+		return *s.config.Weights
+	}
+	return algorithm.DefaultObjectiveWeights()
+}
+
+// Done returns true once the episode is done.
+func (s *Simulator) Done() bool {
+	return s.step >= s.Steps()
+}
+
+// Step applies the actions at the current step, and moves on to the next step. The actions which are invalid or do
+// not fit into the nodes are rejected, as they would be by the controller.
+func (s *Simulator) Step(actions []algorithm.Action) (*StepResult, error) {
+	if s.Done() {
+		return nil, ErrDone
+	}
+	result := &StepResult{}
+	before := s.State()
+	var migrations int
+	for i := range actions {
+		if err := s.apply(&actions[i]); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			continue
+		}
+		result.Applied++
+		if actions[i].Type != algorithm.ActionScaling {
+			migrations++
+		}
+	}
+	result.Reward = Reward(before, s.State(), s.Weights(), migrations)
+	s.step++
+	result.Done = s.Done()
+	result.State = s.State()
+	return result, nil
+}
+
+// State observes the state of the cluster at the current step. The utilizations include the cpu used by the load.
+func (s *Simulator) State() algorithm.ClusterState {
+	state := algorithm.ClusterState{}
+	for _, n := range s.nodes {
+		cpu, memory := s.used(n)
+		state.Nodes = append(state.Nodes, algorithm.NodeState{
+			Name:                n.Name,
+			AllocatableMilliCPU: n.MilliCPU,
+			AllocatableMemory:   n.Memory,
+			CPU:                 math.Min(cpu/float64(n.MilliCPU), 1),
+			Memory:              math.Min(float64(memory)/float64(n.Memory), 1),
+		})
+	}
+	for _, inf := range s.inferences {
+		infState := algorithm.InferenceState{
+			Name:                inf.Name,
+			Namespace:           inf.Namespace,
+			Replicas:            int32(len(inf.pods)),
+			LatencyMilliseconds: s.latency(inf),
+		}
+		for _, p := range inf.pods {
+			infState.Pods = append(infState.Pods, algorithm.PodState{
+				Name:      p.name,
+				Namespace: inf.Namespace,
+				UID:       p.uid,
+				Node:      p.node,
+				MilliCPU:  inf.MilliCPU,
+				Memory:    inf.Memory,
+			})
+		}
+		state.Inferences = append(state.Inferences, infState)
+	}
+	return state
+}
+
+// load returns the requests per second of the Inference at the current step.
+func (s *Simulator) load(inf *inference) float64 {
+	if s.step >= len(s.config.Trace.Steps) {
+		return 0
+	}
+	return s.config.Trace.Steps[s.step].Load[inf.Key()]
+}
+
+// podMilliCPU returns the cpu used by a serving pod of the Inference, the load is shared by its pods evenly.
+func (s *Simulator) podMilliCPU(inf *inference) float64 {
+	cpu := float64(inf.MilliCPU)
+	if len(inf.pods) > 0 {
+		cpu += inf.MilliCPUPerRequest * s.load(inf) / float64(len(inf.pods))
+	}
+	return cpu
+}
+
+// used returns the cpu and memory used on the node.
+func (s *Simulator) used(n *node) (float64, int64) {
+	cpu, memory := float64(n.BaseMilliCPU), n.BaseMemory
+	for _, inf := range s.inferences {
+		for _, p := range inf.pods {
+			if p.node == n.Name {
+				cpu += s.podMilliCPU(inf)
+				memory += inf.Memory
+			}
+		}
+	}
+	return cpu, memory
+}
+
+// requested returns the cpu and memory requested on the node, which the pods are admitted by.
+func (s *Simulator) requested(n *node) (int64, int64) {
+	cpu, memory := n.BaseMilliCPU, n.BaseMemory
+	for _, inf := range s.inferences {
+		for _, p := range inf.pods {
+			if p.node == n.Name {
+				cpu += inf.MilliCPU
+				memory += inf.Memory
+			}
+		}
+	}
+	return cpu, memory
+}
+
+// fits returns true if a serving pod of the Inference fits into the requests of the node.
+func (s *Simulator) fits(n *node, inf *inference) bool {
+	cpu, memory := s.requested(n)
+	return cpu+inf.MilliCPU <= n.MilliCPU && memory+inf.Memory <= n.Memory
+}
+
+// leastRequested returns the node with the least requested cpu a serving pod of the Inference fits into, like the
+// default scheduler spreads the pods. Nil is returned if the pod fits nowhere.
+func (s *Simulator) leastRequested(inf *inference) *node {
+	var best *node
+	var bestRatio float64
+	for _, n := range s.nodes {
+		if !s.fits(n, inf) {
+			continue
+		}
+		cpu, _ := s.requested(n)
+		if ratio := float64(cpu) / float64(n.MilliCPU); best == nil || ratio < bestRatio {
+			best, bestRatio = n, ratio
+		}
+	}
+	return best
+}
+
+// latency returns the mean serving latency of the pods of the Inference, which grows with the cpu utilization of
+// their nodes. Zero is returned if the latency is not modeled or no pod is scheduled.
+func (s *Simulator) latency(inf *inference) float64 {
+	if inf.LatencyMilliseconds <= 0 {
+		return 0
+	}
+	var sum float64
+	var scheduled int
+	for _, p := range inf.pods {
+		n := s.node(p.node)
+		if n == nil {
+			continue
+		}
+		cpu, _ := s.used(n)
+		sum += inf.LatencyMilliseconds / (1 - math.Min(cpu/float64(n.MilliCPU), maxUtilization))
+		scheduled++
+	}
+	if scheduled == 0 {
+		return 0
+	}
+	return sum / float64(scheduled)
+}
+
+func (s *Simulator) node(name string) *node {
+	for _, n := range s.nodes {
+		if n.Name == name {
+			return n
+		}
+	}
+	return nil
+}
+
+func (s *Simulator) inference(namespace, name string) *inference {
+	for _, inf := range s.inferences {
+		if inf.Namespace == namespace && inf.Name == name {
+			return inf
+		}
+	}
+	return nil
+}
+
+// addPod creates a serving pod of the Inference on the node, which is pending if the node is empty.
+func (s *Simulator) addPod(inf *inference, nodeName string) {
+	s.created++
+	inf.pods = append(inf.pods, &pod{
+		name: fmt.Sprintf("%s-%d", inf.Name, s.created),
+		uid:  fmt.Sprintf("sim-%d", s.created),
+		node: nodeName,
+	})
+}
+
+// apply applies the action to the cluster.
+func (s *Simulator) apply(action *algorithm.Action) error {
+	inf := s.inference(action.Namespace, action.Inference)
+	if inf == nil {
+		return fmt.Errorf("inference %s/%s is not found", action.Namespace, action.Inference)
+	}
+	switch action.Type {
+	case algorithm.ActionTransition:
+		return s.transition(inf, action)
+	case algorithm.ActionScaling:
+		return s.scale(inf, action.ScalingReplica, nil)
+	case algorithm.ActionTransitionScaling:
+		target := s.node(action.TargetNode)
+		if target == nil {
+			return fmt.Errorf("target node %s is not found", action.TargetNode)
+		}
+		if err := s.transition(inf, action); err != nil {
+			return err
+		}
+		return s.scale(inf, action.ScalingReplica, target)
+	default:
+		return fmt.Errorf("unsupported action type %q", action.Type)
+	}
+}
+
+// transition moves the target pod of the action onto the target node.
+func (s *Simulator) transition(inf *inference, action *algorithm.Action) error {
+	target := s.node(action.TargetNode)
+	if target == nil {
+		return fmt.Errorf("target node %s is not found", action.TargetNode)
+	}
+	var moved *pod
+	for _, p := range inf.pods {
+		if p.name == action.TargetPod.Name {
+			moved = p
+		}
+	}
+	if moved == nil {
+		return fmt.Errorf("target pod %s is not found", action.TargetPod.Name)
+	}
+	if moved.node == target.Name {
+		return fmt.Errorf("pod %s is already on node %s", moved.name, target.Name)
+	}
+	if !s.fits(target, inf) {
+		return fmt.Errorf("pod %s does not fit into node %s", moved.name, target.Name)
+	}
+	moved.node = target.Name
+	return nil
+}
+
+// scale scales the Inference to the replicas bounded by its min and max replicas. The new pods are placed onto the
+// target node if set, or the least requested nodes, and the pods on the most requested nodes are removed first.
+func (s *Simulator) scale(inf *inference, replicas int32, target *node) error {
+	if replicas < inf.MinReplicas {
+		replicas = inf.MinReplicas
+	}
+	if inf.MaxReplicas > 0 && replicas > inf.MaxReplicas {
+		replicas = inf.MaxReplicas
+	}
+	for int32(len(inf.pods)) < replicas {
+		n := target
+		if n == nil {
+			n = s.leastRequested(inf)
+		}
+		if n == nil || !s.fits(n, inf) {
+			return fmt.Errorf("pod of inference %s does not fit into the nodes", inf.Key())
+		}
+		s.addPod(inf, n.Name)
+	}
+	if int32(len(inf.pods)) > replicas {
+		requested := make(map[string]int64, len(s.nodes))
+		for _, n := range s.nodes {
+			requested[n.Name], _ = s.requested(n)
+		}
+		sort.SliceStable(inf.pods, func(i, j int) bool {
+			return requested[inf.pods[i].node] < requested[inf.pods[j].node]
+		})
+		inf.pods = inf.pods[:replicas]
+	}
+	return nil
+}
+
+// Reward rewards the actions for reducing the imbalance of the cpu and memory utilizations of the nodes, and
+// penalizes them for increasing the latency of the Inferences relative to the latency before the actions, and for the
+// pods they moved, weighted by the weights.
+func Reward(before, after algorithm.ClusterState, weights algorithm.ObjectiveWeights, migrations int) float64 {
+	latencies := make(map[string]float64, len(before.Inferences))
+	for _, inf := range before.Inferences {
+		latencies[inf.Namespace+"/"+inf.Name] = inf.LatencyMilliseconds
+	}
+	var increase float64
+	var observed int
+	for _, inf := range after.Inferences {
+		if previous := latencies[inf.Namespace+"/"+inf.Name]; previous > 0 && inf.LatencyMilliseconds > 0 {
+			increase += algorithm.LatencyIncrease(previous, inf.LatencyMilliseconds)
+			observed++
+		}
+	}
+	if observed > 0 {
+		increase /= float64(observed)
+	}
+	cpuBefore, memoryBefore := before.Imbalances()
+	cpuAfter, memoryAfter := after.Imbalances()
+	return weights.Reward(cpuBefore-cpuAfter, memoryBefore-memoryAfter, increase, migrations)
+}
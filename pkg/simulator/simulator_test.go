@@ -0,0 +1,197 @@
+package simulator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"melody/pkg/algorithm"
+	"melody/pkg/scheduler"
+)
+
+func testConfig() Config {
+	return Config{
+		Nodes: []NodeConfig{
+			{Name: "edge-1", MilliCPU: 4000, Memory: 4 << 30},
+			{Name: "edge-2", MilliCPU: 4000, Memory: 4 << 30},
+		},
+		Inferences: []InferenceConfig{{
+			Name: "resnet", Namespace: "default", Replicas: 2, MinReplicas: 1, MaxReplicas: 3,
+			MilliCPU: 1000, Memory: 1 << 30, MilliCPUPerRequest: 10, LatencyMilliseconds: 20,
+			Nodes: []string{"edge-1", "edge-1"},
+		}},
+		Trace: Trace{Steps: []TraceStep{
+			{Load: map[string]float64{"default/resnet": 100}},
+			{Load: map[string]float64{"default/resnet": 200}},
+		}},
+	}
+}
+
+func TestStep(t *testing.T) {
+	sim, err := New(testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := sim.Reset()
+	// 2 pods of 1000m, and 100 rps of 10m shared by them
+	if cpu := state.Nodes[0].CPU; cpu != 0.75 {
+		t.Errorf("expected cpu 0.75 of edge-1, got %v", cpu)
+	}
+	before := state.Inferences[0].LatencyMilliseconds
+
+	pod := state.Inferences[0].Pods[0]
+	result, err := sim.Step([]algorithm.Action{
+		{Type: algorithm.ActionTransition, Inference: "resnet", Namespace: "default",
+			TargetPod: algorithm.ObjectReference{Name: pod.Name}, TargetNode: "edge-2"},
+		{Type: algorithm.ActionTransition, Inference: "missing", Namespace: "default"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Applied != 1 || len(result.Errors) != 1 {
+		t.Errorf("expected 1 applied and 1 rejected action, got %+v", result)
+	}
+	if result.Reward <= 0 {
+		t.Errorf("expected a positive reward for balancing the nodes, got %v", result.Reward)
+	}
+	if result.Done {
+		t.Error("expected the episode is not done")
+	}
+	// 200 rps at the next step
+	if cpu := result.State.Nodes[1].CPU; cpu != 0.5 {
+		t.Errorf("expected cpu 0.5 of edge-2, got %v", cpu)
+	}
+	if latency := result.State.Inferences[0].LatencyMilliseconds; latency >= before {
+		t.Errorf("expected the latency to drop from %v, got %v", before, latency)
+	}
+
+	result, err = sim.Step([]algorithm.Action{{Type: algorithm.ActionScaling, Inference: "resnet", Namespace: "default", ScalingReplica: 5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replicas := result.State.Inferences[0].Replicas; replicas != 3 {
+		t.Errorf("expected replicas bounded to 3, got %d", replicas)
+	}
+	if !result.Done {
+		t.Error("expected the episode is done")
+	}
+	if _, err := sim.Step(nil); err != ErrDone {
+		t.Errorf("expected ErrDone, got %v", err)
+	}
+
+	state = sim.Reset()
+	if sim.Done() || state.Inferences[0].Replicas != 2 || state.Inferences[0].Pods[1].Node != "edge-1" {
+		t.Errorf("expected the initial state after reset, got %+v", state)
+	}
+}
+
+func TestRewardWeights(t *testing.T) {
+	step := func(weights *algorithm.ObjectiveWeights) float64 {
+		config := testConfig()
+		config.Weights = weights
+		sim, err := New(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pod := sim.State().Inferences[0].Pods[0]
+		result, err := sim.Step([]algorithm.Action{{Type: algorithm.ActionTransition, Inference: "resnet", Namespace: "default",
+			TargetPod: algorithm.ObjectReference{Name: pod.Name}, TargetNode: "edge-2"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.Reward
+	}
+
+	balanced := step(nil)
+	costly := step(&algorithm.ObjectiveWeights{CPUBalance: 1, MemoryBalance: 1, Latency: 1, MigrationCost: 1})
+	if diff := balanced - costly; math.Abs(diff-1) > 1e-9 {
+		t.Errorf("expected the migration to cost 1, got %v", diff)
+	}
+	if latencyOnly := step(&algorithm.ObjectiveWeights{Latency: 1}); latencyOnly <= 0 || latencyOnly >= balanced {
+		t.Errorf("expected a smaller positive reward for the latency only, got %v of %v", latencyOnly, balanced)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	sim, err := New(testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	balanced, err := Evaluate(context.Background(), sim, scheduler.NewLeastLoaded())
+	if err != nil {
+		t.Fatal(err)
+	}
+	idle, err := Evaluate(context.Background(), sim, scheduler.SchedulerFunc(func(ctx context.Context, state algorithm.ClusterState, weights algorithm.ObjectiveWeights) ([]scheduler.Decision, error) {
+		return nil, nil
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balanced.Steps != 2 || balanced.Actions == 0 || balanced.Rejected != 0 {
+		t.Errorf("unexpected result %+v", balanced)
+	}
+	if balanced.TotalReward <= idle.TotalReward || balanced.MeanLatencyMilliseconds >= idle.MeanLatencyMilliseconds {
+		t.Errorf("expected least-loaded to outperform no scheduling, got %+v and %+v", balanced, idle)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	sim, err := New(testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(NewHandler(sim))
+	defer server.Close()
+
+	post := func(path string, body interface{}, out interface{}) int {
+		data, _ := json.Marshal(body)
+		resp, err := http.Post(server.URL+path, "application/json", bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if out != nil {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return resp.StatusCode
+	}
+
+	reset := &ResetResponse{}
+	if status := post(ResetPath, nil, reset); status != http.StatusOK || len(reset.State.Nodes) != 2 {
+		t.Fatalf("unexpected reset %d %+v", status, reset)
+	}
+	step := &StepResponse{}
+	for i := 0; i < 2; i++ {
+		if status := post(StepPath, &StepRequest{APIVersion: algorithm.APIVersion}, step); status != http.StatusOK {
+			t.Fatalf("unexpected step status %d", status)
+		}
+	}
+	if !step.Done {
+		t.Error("expected the episode is done")
+	}
+	if status := post(StepPath, &StepRequest{APIVersion: algorithm.APIVersion}, nil); status != http.StatusConflict {
+		t.Errorf("expected status 409 once done, got %d", status)
+	}
+	if status := post(StepPath, &StepRequest{APIVersion: "v0"}, nil); status != http.StatusBadRequest {
+		t.Errorf("expected status 400 for another api version, got %d", status)
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	config := testConfig()
+	config.Inferences[0].Nodes = []string{"edge-3", "edge-1"}
+	if _, err := New(config); err == nil {
+		t.Error("expected error for an unknown node")
+	}
+	config = testConfig()
+	config.Trace = Trace{}
+	if _, err := New(config); err == nil {
+		t.Error("expected error without trace or maxSteps")
+	}
+}